@@ -12,15 +12,30 @@ var (
 	errJobNotFound = errors.New("job not found")
 )
 
+// JobMigrationFunc is the function signature used to migrate job names of deployments started with a previous
+// version of an action. It receives the version of the action the deployment was started with and the name of a job
+// in that version, and returns the name of the job in the current version.
+type JobMigrationFunc func(fromVersion int, job string) (string, error)
+
 // Action contains a sequence of jobs, and performs a specific function.
 // Actions are registered, launched and managed by action services.
 // Action instances should only be used to define a sequence of states for registration in a service.
+//
+// Actions have a version that is recorded in the deployments that execute them. The version should be increased
+// every time the sequence of jobs changes (e.g. jobs are added, removed or renamed). Deployments are always resumed
+// with the version of the action they were started with if it is still registered in the service. If it is not,
+// the MigrateJob function of the latest version is used to map the jobs of the deployment to the latest version.
 type Action struct {
 	// Name contains the action name.
 	// This field can be left empty as it will be filled in by a service when registering this action.
 	Name string
+	// Version contains the version of the action.
+	Version int
 	// Jobs contains the sequence of jobs processed to perform this action.
 	Jobs Jobs
+	// MigrateJob contains the optional function used to migrate the jobs of deployments started with a previous
+	// version of this action. It must be able to migrate jobs from any previous version that is not registered.
+	MigrateJob JobMigrationFunc
 }
 
 // NewAction creates a new Action containing a sequence of jobs.
@@ -1,8 +1,8 @@
 package actions
 
 import (
+	"context"
 	"fmt"
-	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -16,7 +16,7 @@ func TestNewAction(t *testing.T) {
 	for i := 0; i < len(jobs); i++ {
 		jobs[i] = &Job{
 			Name: fmt.Sprintf("job_%d", i+1),
-			Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 				return nil, nil
 			},
 			InputType:  GetJobDataType(&TestStruct{}),
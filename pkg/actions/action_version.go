@@ -0,0 +1,77 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// ErrActionVersionNotFound is returned when a deployment is resumed, the version of the action it was started with
+	// is not registered, and the latest version of the action cannot migrate it.
+	ErrActionVersionNotFound = errors.New("action version not found")
+	// ErrJobMigrationFailed is returned when the job of a deployment cannot be migrated to the latest version of its
+	// action.
+	ErrJobMigrationFailed = errors.New("job migration failed")
+)
+
+// migrateDeployment migrates a deployment started with a previous version of an action to `action`.
+// The current job of the deployment and the names of the jobs its data is stored for are mapped with the action's
+// MigrateJob function. Data entries are copied to the migrated job names, and the original entries are kept.
+// Deployment errors are not migrated, as they are only used to keep a record of the deployment.
+func migrateDeployment(tx DeploymentStore, action *Action, deployment *Deployment) error {
+	if action.MigrateJob == nil {
+		return fmt.Errorf("%w: action [%s] version [%d] is not registered and version [%d] cannot migrate it",
+			ErrActionVersionNotFound, action.Name, deployment.ActionVersion, action.Version)
+	}
+
+	// Map the current job
+	current, err := migrateJob(action, deployment.ActionVersion, deployment.CurrentJob)
+	if err != nil {
+		return err
+	}
+	if _, err := action.getJobIndex(&current); err != nil {
+		return fmt.Errorf("%w: job [%s] from version [%d] was migrated to [%s], which is not part of version [%d]",
+			ErrJobMigrationFailed, deployment.CurrentJob, deployment.ActionVersion, current, action.Version)
+	}
+
+	// Copy job data to the migrated jobs. This allows jobs to access data they stored with their previous name (e.g.
+	// rollback handlers).
+	dataSet, err := tx.GetDeploymentDataSet(deployment)
+	if err != nil {
+		return err
+	}
+	for _, data := range dataSet {
+		job, err := migrateJob(action, deployment.ActionVersion, data.Job)
+		if err != nil {
+			return err
+		}
+		if job == data.Job {
+			continue
+		}
+
+		migrated := *data
+		migrated.Model = gorm.Model{}
+		migrated.Deployment = deployment
+		migrated.Job = job
+		if err := tx.SetDeploymentData(&migrated); err != nil {
+			return err
+		}
+	}
+
+	// Update the deployment
+	deployment.CurrentJob = current
+	deployment.ActionVersion = action.Version
+
+	return tx.UpdateDeployment(deployment)
+}
+
+// migrateJob maps the name of a job from a previous version of an action to the current version.
+func migrateJob(action *Action, fromVersion int, job string) (string, error) {
+	migrated, err := action.MigrateJob(fromVersion, job)
+	if err != nil {
+		return "", fmt.Errorf("%w: job [%s] from version [%d]: %s", ErrJobMigrationFailed, job, fromVersion, err)
+	}
+
+	return migrated, nil
+}
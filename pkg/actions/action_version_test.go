@@ -0,0 +1,157 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// createVersionTestAction creates an action version whose jobs record their names in `calls`.
+func createVersionTestAction(t *testing.T, version int, calls *[]string, jobNames ...string) *Action {
+	jobs := make(Jobs, len(jobNames))
+	for i, name := range jobNames {
+		name := name
+		jobs[i] = &Job{
+			Name: name,
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value interface{}) (interface{}, error) {
+
+				*calls = append(*calls, name)
+				return value, nil
+			},
+			InputType:  NilJobDataType,
+			OutputType: NilJobDataType,
+		}
+	}
+
+	action, err := NewAction(jobs)
+	require.NoError(t, err)
+	action.Version = version
+
+	return action
+}
+
+func TestServiceRegisterActionVersions(t *testing.T) {
+	service := newTestService(t)
+	var calls []string
+
+	v1 := createVersionTestAction(t, 1, &calls, "job")
+	v2 := createVersionTestAction(t, 2, &calls, "job")
+
+	require.NoError(t, service.RegisterAction(nil, "versioned", v2))
+	require.NoError(t, service.RegisterAction(nil, "versioned", v1))
+	require.True(t, errors.Is(service.RegisterAction(nil, "versioned", v2), ErrActionExists))
+
+	// The latest version is returned
+	action, err := service.GetAction(nil, "versioned")
+	require.NoError(t, err)
+	assert.Equal(t, v2, action)
+}
+
+func TestExecuteVersionedAction(t *testing.T) {
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var calls []string
+	require.NoError(t, service.RegisterAction(nil, "versioned", createVersionTestAction(t, 1, &calls, "a", "b")))
+	require.NoError(t, service.RegisterAction(nil, "versioned", createVersionTestAction(t, 2, &calls, "a", "c", "b")))
+
+	// New deployments use the latest version
+	executeInput := &ExecuteInput{ActionName: "versioned", GroupID: "new"}
+	require.NoError(t, service.Execute(context.Background(), nil, tx, executeInput, nil))
+	assert.Equal(t, []string{"a", "c", "b"}, calls)
+	assert.Equal(t, 2, executeInput.Deployment.ActionVersion)
+
+	// Deployments are resumed with the version they were started with
+	calls = nil
+	deployment := &Deployment{UUID: "old", Action: "versioned", ActionVersion: 1, CurrentJob: "a",
+		Status: deploymentStatusRunning}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	executeInput = &ExecuteInput{ActionName: "versioned", Deployment: deployment}
+	require.NoError(t, service.Execute(context.Background(), nil, tx, executeInput, nil))
+	assert.Equal(t, []string{"a", "b"}, calls)
+}
+
+func TestExecuteMigratedAction(t *testing.T) {
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var calls []string
+	v2 := createVersionTestAction(t, 2, &calls, "a", "renamed", "c")
+	v2.MigrateJob = func(fromVersion int, job string) (string, error) {
+		if job == "b" {
+			return "renamed", nil
+		}
+		return job, nil
+	}
+	require.NoError(t, service.RegisterAction(nil, "versioned", v2))
+
+	// Create a deployment started with version 1, which is no longer registered
+	deployment := &Deployment{UUID: "test", Action: "versioned", ActionVersion: 1, CurrentJob: "b",
+		Status: deploymentStatusRunning}
+	require.NoError(t, tx.CreateDeployment(deployment))
+	require.NoError(t, deployment.SetJobData(tx, nil, DeploymentJobData, "data"))
+
+	executeInput := &ExecuteInput{ActionName: "versioned", Deployment: deployment}
+	require.NoError(t, service.Execute(context.Background(), nil, tx, executeInput, nil))
+
+	// The deployment is resumed from the migrated job
+	assert.Equal(t, []string{"renamed", "c"}, calls)
+
+	stored, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.ActionVersion)
+
+	// Job data is available under the migrated job name
+	var data string
+	job := "renamed"
+	require.NoError(t, stored.GetJobDataOutValue(tx, &job, DeploymentJobData, &data))
+	assert.Equal(t, "data", data)
+}
+
+func TestExecuteMigratedActionErrors(t *testing.T) {
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var calls []string
+	require.NoError(t, service.RegisterAction(nil, "versioned", createVersionTestAction(t, 2, &calls, "a")))
+
+	migrated := createVersionTestAction(t, 2, &calls, "a")
+	migrated.MigrateJob = func(fromVersion int, job string) (string, error) {
+		return job, nil
+	}
+	require.NoError(t, service.RegisterAction(nil, "migrated", migrated))
+
+	deployments := []*Deployment{
+		{UUID: "versioned", Action: "versioned", ActionVersion: 1, CurrentJob: "a", Status: deploymentStatusRunning},
+		{UUID: "migrated", Action: "migrated", ActionVersion: 1, CurrentJob: "b", Status: deploymentStatusRunning},
+	}
+	for _, deployment := range deployments {
+		require.NoError(t, tx.CreateDeployment(deployment))
+	}
+
+	// Actions without a migration function cannot resume deployments of unregistered versions
+	executeInput := &ExecuteInput{ActionName: "versioned", Deployment: deployments[0]}
+	err := service.Execute(context.Background(), nil, tx, executeInput, nil)
+	assert.True(t, errors.Is(err, ErrActionVersionNotFound))
+
+	// Jobs must be migrated to jobs in the latest version
+	executeInput = &ExecuteInput{ActionName: "migrated", Deployment: deployments[1]}
+	err = service.Execute(context.Background(), nil, tx, executeInput, nil)
+	assert.True(t, errors.Is(err, ErrJobMigrationFailed))
+
+	// Deployments are not run from the first job
+	assert.Empty(t, calls)
+}
@@ -10,7 +10,7 @@ func CleanAndMigrateDB(tx *gorm.DB) error {
 	return gormUtils.CleanAndMigrateModels(
 		tx,
 		&Deployment{},
-		&deploymentData{},
+		&DeploymentData{},
 		&DeploymentError{},
 	)
 }
@@ -20,7 +20,7 @@ func MigrateDB(tx *gorm.DB) error {
 	return gormUtils.MigrateModels(
 		tx,
 		&Deployment{},
-		&deploymentData{},
+		&DeploymentData{},
 		&DeploymentError{},
 	)
 }
@@ -30,7 +30,7 @@ func DropDB(tx *gorm.DB) error {
 	return gormUtils.DropModels(
 		tx,
 		&DeploymentError{},
-		&deploymentData{},
+		&DeploymentData{},
 		&Deployment{},
 	)
 }
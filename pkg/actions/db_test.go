@@ -13,6 +13,7 @@ type TestResource struct {
 	store  Store
 	logger *gz.Logger
 	db     *gorm.DB
+	tx     DeploymentStore
 }
 
 type storeTestData struct {
@@ -39,6 +40,7 @@ func setupTest(t *testing.T) *TestResource {
 		store:  NewStore(&storeTestData{}),
 		logger: &logger,
 		db:     db,
+		tx:     NewGormDeploymentStore(db),
 	}
 
 	return &testResources
@@ -3,6 +3,7 @@ package actions
 import (
 	"errors"
 	"github.com/jinzhu/gorm"
+	"time"
 )
 
 // DeploymentStatus is a possible status for a Deployment.
@@ -26,12 +27,28 @@ type Deployment struct {
 	UUID string `gorm:"not null"`
 	// Action contains the action this deployment executes.
 	Action string `gorm:"not null"`
+	// ActionVersion contains the version of the action this deployment executes.
+	ActionVersion int `gorm:"not null;default:0"`
 	// Status contains the status of this deployment.
 	Status DeploymentStatus `gorm:"not null"`
 	// CurrentJob contains the current action job the deployment is executing.
 	CurrentJob string `gorm:"not null"`
 	// RollbackError contains the error that triggered the rollback of this deployment.
 	RollbackError *string
+	// StopRequested is set when a user requests stopping this deployment.
+	// A running deployment with this flag set stops before running its next job and is rolled back.
+	// This field can only be set by calling DeploymentStore.RequestDeploymentStop.
+	StopRequested bool `gorm:"not null;default:false"`
+	// LeaseOwner contains the ID of the service instance that holds the lease of this deployment.
+	// Leases prevent multiple service instances from executing the same deployment at the same time.
+	// This field can only be set by calling DeploymentStore.AcquireDeploymentLease.
+	LeaseOwner *string
+	// LeaseExpiresAt contains the time the lease of this deployment expires if it is not renewed.
+	// Expired leases can be taken over by other service instances.
+	LeaseExpiresAt *time.Time
+	// TraceID contains the ID of the trace of the last execution of this deployment.
+	// It is only set if the deployment was executed with tracing enabled.
+	TraceID *string
 }
 
 // Deployments is a slice of Deployment pointers.
@@ -43,18 +60,26 @@ func (Deployment) TableName() string {
 }
 
 // newDeployment creates a new Deployment entry in persistent storage and returns a pointer to it.
-func newDeployment(tx *gorm.DB, action *Action, groupID string) (*Deployment, error) {
+// If `lease` is not nil, the deployment is created leased to the lease owner. This prevents other service instances
+// from leasing the deployment before it starts being executed.
+func newDeployment(tx DeploymentStore, action *Action, groupID string, lease *LeaseConfig) (*Deployment, error) {
 	// Create the deployment
 	deployment := Deployment{
-		UUID:       groupID,
-		Action:     action.Name,
-		CurrentJob: action.Jobs[0].Name,
-		Status:     deploymentStatusRunning,
+		UUID:          groupID,
+		Action:        action.Name,
+		ActionVersion: action.Version,
+		CurrentJob:    action.Jobs[0].Name,
+		Status:        deploymentStatusRunning,
+	}
+	if lease != nil {
+		owner := lease.Owner
+		expiresAt := time.Now().Add(lease.TTL)
+		deployment.LeaseOwner = &owner
+		deployment.LeaseExpiresAt = &expiresAt
 	}
 
 	// Create the storage record
-	// TODO: This should use an interface to allow swapping to different storages in the future.
-	if err := tx.Model(&Deployment{}).Create(&deployment).Error; err != nil {
+	if err := tx.CreateDeployment(&deployment); err != nil {
 		return nil, err
 	}
 
@@ -62,41 +87,21 @@ func newDeployment(tx *gorm.DB, action *Action, groupID string) (*Deployment, er
 }
 
 // getDeployment gets the deployment for a given a uuid
-func getDeployment(tx *gorm.DB, uuid *string) (*Deployment, error) {
-	deployment := &Deployment{}
-
-	err := tx.
-		Where("UUID = ?", uuid).
-		First(deployment).
-		Error
-	if err != nil {
-		return nil, err
-	}
-
-	return deployment, nil
+func getDeployment(tx DeploymentStore, uuid *string) (*Deployment, error) {
+	return tx.GetDeployment(*uuid)
 }
 
 // GetRunningDeployments returns the set of deployments that are still running.
-func GetRunningDeployments(tx *gorm.DB) (Deployments, error) {
-	var deployments Deployments
-
-	err := tx.
-		Where("status = ?", deploymentStatusRunning).
-		Find(&deployments).
-		Error
-	if err != nil {
-		return nil, err
-	}
-
-	return deployments, nil
+func GetRunningDeployments(tx DeploymentStore) (Deployments, error) {
+	return tx.GetDeploymentsByStatus(deploymentStatusRunning)
 }
 
 // setJob updates the current job of a deployment and creates an input entry if the value is not nil
-func (d *Deployment) setJob(tx *gorm.DB, job string, inputData interface{}) error {
+func (d *Deployment) setJob(tx DeploymentStore, job string, inputData interface{}) error {
 	// Update the current job if it changed
 	if d.CurrentJob != job {
 		d.CurrentJob = job
-		if err := tx.Model(d).Save(d).Error; err != nil {
+		if err := tx.UpdateDeployment(d); err != nil {
 			return err
 		}
 	}
@@ -112,39 +117,39 @@ func (d *Deployment) setJob(tx *gorm.DB, job string, inputData interface{}) erro
 }
 
 // setStatus changes the status of the deployment.
-func (d *Deployment) setStatus(tx *gorm.DB, status DeploymentStatus) error {
+func (d *Deployment) setStatus(tx DeploymentStore, status DeploymentStatus) error {
 	// Update the status
 	d.Status = status
 
-	return tx.Model(d).Save(*d).Error
+	return tx.UpdateDeployment(d)
 }
 
 // SetJobData creates a job data entry of a specific type for a job in this deployment.
-func (d *Deployment) SetJobData(tx *gorm.DB, job *string, dataType deploymentDataType, data interface{}) error {
+func (d *Deployment) SetJobData(tx DeploymentStore, job *string, dataType DeploymentDataType, data interface{}) error {
 	return setDeploymentData(tx, d, job, dataType, data)
 }
 
 // GetJobData gets job data entry of a specific type for a job in this deployment.
-func (d *Deployment) GetJobData(tx *gorm.DB, job *string, dataType deploymentDataType) (interface{}, error) {
+func (d *Deployment) GetJobData(tx DeploymentStore, job *string, dataType DeploymentDataType) (interface{}, error) {
 	return getDeploymentDataFromRegistry(tx, d, job, dataType)
 }
 
 // GetJobDataOutValue gets job data entry for a job in this deployment and stores the result in the passed output value.
 // `out` must be a pointer.
-func (d *Deployment) GetJobDataOutValue(tx *gorm.DB, job *string, dataType deploymentDataType, out interface{}) error {
+func (d *Deployment) GetJobDataOutValue(tx DeploymentStore, job *string, dataType DeploymentDataType, out interface{}) error {
 	return getDeploymentDataOutValue(tx, d, job, dataType, out)
 }
 
 // addJobError adds a new deployment error entry for a deployment job.
 // If `job` is nil, then the current job of the deployment will be used.
-func (d *Deployment) addJobError(tx *gorm.DB, job *string, err error) error {
+func (d *Deployment) addJobError(tx DeploymentStore, job *string, err error) error {
 	_, err = newDeploymentError(tx, d, job, err)
 	return err
 }
 
 // GetErrors returns a slice with all the deployment errors logged for this deployment.
 // If `job` is not nil, this will return the errors for a single job, otherwise this will return errors for all jobs.
-func (d *Deployment) GetErrors(tx *gorm.DB, job *string) (DeploymentErrors, error) {
+func (d *Deployment) GetErrors(tx DeploymentStore, job *string) (DeploymentErrors, error) {
 	return getDeploymentErrors(tx, d, job)
 }
 
@@ -158,7 +163,7 @@ func (d *Deployment) getRollbackError() error {
 }
 
 // setRollbackStatus sets the status of this deployment to Rollback and stores the error that triggered the rollback.
-func (d *Deployment) setRollbackStatus(tx *gorm.DB, rollbackError error) error {
+func (d *Deployment) setRollbackStatus(tx DeploymentStore, rollbackError error) error {
 	// Make sure rollbackError is defined
 	if rollbackError == nil {
 		return errRollbackErrIsNil
@@ -172,7 +177,7 @@ func (d *Deployment) setRollbackStatus(tx *gorm.DB, rollbackError error) error {
 }
 
 // setFinishedStatus sets the status of this deployment to Finished.
-func (d *Deployment) setFinishedStatus(tx *gorm.DB) error {
+func (d *Deployment) setFinishedStatus(tx DeploymentStore) error {
 	return d.setStatus(tx, deploymentStatusFinished)
 }
 
@@ -181,6 +186,16 @@ func (d *Deployment) isRunning() bool {
 	return d.Status == deploymentStatusRunning
 }
 
+// forJob returns a copy of this deployment that uses `job` as its current job.
+// It is used to run jobs that are not part of the main action job sequence (e.g. jobs in a parallel job group), so that
+// job data and errors are stored for the right job. The returned copy must not be used to update the deployment.
+func (d *Deployment) forJob(job string) *Deployment {
+	deployment := *d
+	deployment.CurrentJob = job
+
+	return &deployment
+}
+
 // isRollingBack indicates if the deployment is in the Rollback status.
 func (d *Deployment) isRollingBack() bool {
 	return d.Status == deploymentStatusRollback
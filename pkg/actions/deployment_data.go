@@ -7,15 +7,19 @@ import (
 	"reflect"
 )
 
-// deploymentDataType is the type of data being stored for a job in a deploymentData entry.
-type deploymentDataType string
+// DeploymentDataType is the type of data being stored for a job in a DeploymentData entry.
+type DeploymentDataType string
 
 const (
 	// DeploymentJobInput entries contain the data used as input for the job.
-	DeploymentJobInput = deploymentDataType("input")
+	DeploymentJobInput = DeploymentDataType("input")
 	// DeploymentJobData entries contain data stored by a job for future use.
 	// This data is used by jobs to handle errors and rollback.
-	DeploymentJobData = deploymentDataType("job")
+	DeploymentJobData = DeploymentDataType("job")
+	// DeploymentJobOutput entries contain the data returned by the job.
+	// This data marks the job as finished. The output of jobs run in a parallel job group is used to restore the output
+	// of finished jobs when a deployment is resumed.
+	DeploymentJobOutput = DeploymentDataType("output")
 )
 
 var (
@@ -23,19 +27,19 @@ var (
 	ErrDeploymentDataNoData = errors.New("an entry for the type and job was found, but there is no data")
 )
 
-// deploymentData contains data related to an action deployment's job.
+// DeploymentData contains data related to an action deployment's job.
 // This information is used to give context for debugging, resume an interrupted action (e.g. due to a server restart),
 // or to have context when handling errors (e.g. knowing what machines to terminate).
-type deploymentData struct {
+type DeploymentData struct {
 	gorm.Model
 	// Deployment contains a reference to the deployment this data is for
-	Deployment *Deployment
+	Deployment *Deployment `gorm:"association_autoupdate:false"`
 	// Deployment contains the ID of the deployment this data is for
 	DeploymentID int `gorm:"not null"`
 	// Job contains the job this data is for
 	Job string `gorm:"not null"`
 	// Type contains the type of data stored for the job
-	Type deploymentDataType `gorm:"not null"`
+	Type DeploymentDataType `gorm:"not null"`
 	// DataType contains the data type of the value stored.
 	// This is used in tandem with a dataTypeRegistry to automatically marshal and unmarshal data from storage.
 	DataType string `gorm:"not null"`
@@ -45,8 +49,8 @@ type deploymentData struct {
 	Data *string `gorm:"not null;type:text"`
 }
 
-// Value returns the unmarshalled value stored in this deploymentData instance.
-func (dsd *deploymentData) Value() (interface{}, error) {
+// Value returns the unmarshalled value stored in this DeploymentData instance.
+func (dsd *DeploymentData) Value() (interface{}, error) {
 	// Get the data type
 	dataType, err := jobDataTypeRegistry.getType(dsd.DataType)
 	if err != nil {
@@ -68,36 +72,37 @@ func (dsd *deploymentData) Value() (interface{}, error) {
 	return reflect.ValueOf(out).Elem().Interface(), nil
 }
 
-// OutValue unmarshals the value stored in this deploymentData instance inside on output value.
+// OutValue unmarshals the value stored in this DeploymentData instance inside on output value.
 // `out` must be a pointer.
-func (dsd *deploymentData) OutValue(out interface{}) error {
+func (dsd *DeploymentData) OutValue(out interface{}) error {
 	return json.Unmarshal([]byte(*dsd.Data), out)
 }
 
-// DeploymentDataSet is a slice of deploymentData pointers.
-type DeploymentDataSet []*deploymentData
+// DeploymentDataSet is a slice of DeploymentData pointers.
+type DeploymentDataSet []*DeploymentData
 
-// TableName sets the database table name for deploymentData
-func (dsd deploymentData) TableName() string {
+// TableName sets the database table name for DeploymentData
+func (dsd DeploymentData) TableName() string {
 	return "action_deployments_data"
 }
 
-// newDeploymentData creates a new deploymentData instance and returns a pointer to it.
-func newDeploymentData(deployment *Deployment, job string, deploymentDataType deploymentDataType, dataTypeName string,
-	data *string) *deploymentData {
-	return &deploymentData{
-		Deployment: deployment,
-		Job:        job,
-		Type:       deploymentDataType,
-		DataType:   dataTypeName,
-		Data:       data,
+// newDeploymentData creates a new DeploymentData instance and returns a pointer to it.
+func newDeploymentData(deployment *Deployment, job string, dataType DeploymentDataType, dataTypeName string,
+	data *string) *DeploymentData {
+	return &DeploymentData{
+		Deployment:   deployment,
+		DeploymentID: int(deployment.ID),
+		Job:          job,
+		Type:         dataType,
+		DataType:     dataTypeName,
+		Data:         data,
 	}
 }
 
 // setDeploymentData stores deployment data for a job in persistent storage.
 // If there is no previous data, a new storage entry will be created.
 // If there is previous data, the storage entry will be replaced with the new data.
-func setDeploymentData(tx *gorm.DB, deployment *Deployment, job *string, deploymentDataType deploymentDataType,
+func setDeploymentData(tx DeploymentStore, deployment *Deployment, job *string, dataType DeploymentDataType,
 	data interface{}) error {
 	if job == nil {
 		job = &deployment.CurrentJob
@@ -113,40 +118,24 @@ func setDeploymentData(tx *gorm.DB, deployment *Deployment, job *string, deploym
 
 	// Create or update the storage entry
 	dataTypeName := GetJobDataTypeName(data)
-	deploymentData := newDeploymentData(deployment, *job, deploymentDataType, dataTypeName, &dataStr)
-	err = tx.
-		Where("deployment_id = ?", deployment.ID).
-		Where("job = ?", *job).
-		Where("type = ?", deploymentDataType).
-		Assign(*deploymentData).
-		FirstOrCreate(deploymentData).
-		Error
-	if err != nil {
-		return err
-	}
+	entry := newDeploymentData(deployment, *job, dataType, dataTypeName, &dataStr)
 
-	return nil
+	return tx.SetDeploymentData(entry)
 }
 
-// getDeploymentData gets a deployment job deploymentData entry from storage.
-// `dataType` defines the deploymentDataType of job data returned.
-// Returns an error if there is no data of the selected type for the deployment job.
-func getDeploymentData(tx *gorm.DB, deployment *Deployment, job *string,
-	deploymentDataType deploymentDataType) (*deploymentData, error) {
+// getDeploymentData gets a deployment job DeploymentData entry from storage.
+// `dataType` defines the DeploymentDataType of job data returned.
+// Returns ErrDeploymentDataNotFound if there is no data of the selected type for the deployment job.
+func getDeploymentData(tx DeploymentStore, deployment *Deployment, job *string,
+	dataType DeploymentDataType) (*DeploymentData, error) {
 
 	// If job is nil, use the deployment's current job
 	if job == nil {
 		job = &deployment.CurrentJob
 	}
 
-	// Get the job data database entry
-	data := &deploymentData{}
-	err := tx.
-		Where("deployment_id = ?", deployment.ID).
-		Where("job = ?", *job).
-		Where("type = ?", deploymentDataType).
-		First(data).
-		Error
+	// Get the job data entry
+	data, err := tx.GetDeploymentData(deployment, *job, dataType)
 	if err != nil {
 		return nil, err
 	}
@@ -159,16 +148,16 @@ func getDeploymentData(tx *gorm.DB, deployment *Deployment, job *string,
 	return data, nil
 }
 
-// getDeploymentDataFromRegistry gets a deployment job deploymentData entry from storage and returns it in a value
+// getDeploymentDataFromRegistry gets a deployment job DeploymentData entry from storage and returns it in a value
 // whose type is resolved automatically using a type registry.
-// `dataType` defines the deploymentDataType of job data returned.
+// `dataType` defines the DeploymentDataType of job data returned.
 // Returns an error if there is no data of the selected type for the deployment job.
 // Keep in mind that the type registry is only able to resolve complex types that have been explicitly registered.
 // In order to get elementary data types, consider using getDeploymentDataOutValue instead.
-func getDeploymentDataFromRegistry(tx *gorm.DB, deployment *Deployment, job *string,
-	deploymentDataType deploymentDataType) (interface{}, error) {
+func getDeploymentDataFromRegistry(tx DeploymentStore, deployment *Deployment, job *string,
+	dataType DeploymentDataType) (interface{}, error) {
 
-	data, err := getDeploymentData(tx, deployment, job, deploymentDataType)
+	data, err := getDeploymentData(tx, deployment, job, dataType)
 	if err != nil {
 		return nil, err
 	}
@@ -177,14 +166,14 @@ func getDeploymentDataFromRegistry(tx *gorm.DB, deployment *Deployment, job *str
 	return data.Value()
 }
 
-// getDeploymentDataOutValue gets a deployment job deploymentData entry from storage and stores it inside a passed
+// getDeploymentDataOutValue gets a deployment job DeploymentData entry from storage and stores it inside a passed
 // output value.
-// `dataType` defines the deploymentDataType of job data returned.
+// `dataType` defines the DeploymentDataType of job data returned.
 // Returns an error if there is no data of the selected type for the deployment job.
-func getDeploymentDataOutValue(tx *gorm.DB, deployment *Deployment, job *string,
-	deploymentDataType deploymentDataType, out interface{}) error {
+func getDeploymentDataOutValue(tx DeploymentStore, deployment *Deployment, job *string,
+	dataType DeploymentDataType, out interface{}) error {
 
-	data, err := getDeploymentData(tx, deployment, job, deploymentDataType)
+	data, err := getDeploymentData(tx, deployment, job, dataType)
 	if err != nil {
 		return err
 	}
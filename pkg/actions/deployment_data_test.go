@@ -45,7 +45,7 @@ var deploymentJobDataTestData = struct {
 	getJobDataCount: func(t *testing.T, db *gorm.DB, deployment *Deployment) int {
 		var count int
 		err := db.
-			Model(&deploymentData{}).
+			Model(&DeploymentData{}).
 			Where("deployment_id = ?", deployment.ID).
 			Count(&count).Error
 		require.NoError(t, err)
@@ -77,7 +77,7 @@ func TestValue(t *testing.T) {
 		require.NoError(t, err)
 		dataStr := string(dataBytes)
 
-		jobData := &deploymentData{
+		jobData := &DeploymentData{
 			DataType: GetJobDataTypeName(value),
 			Data:     &dataStr,
 		}
@@ -123,7 +123,7 @@ func TestSetDeploymentJobDataAndGetDeploymentData(t *testing.T) {
 	jobDataTypeRegistry = newDataTypeRegistry()
 	jobDataTypeRegistry.register(GetJobDataType(DeploymentJobDataTestStruct{}))
 
-	deployment, err := newDeployment(tr.db, td.action, uuid.NewV4().String())
+	deployment, err := newDeployment(tr.tx, td.action, uuid.NewV4().String(), nil)
 	require.NoError(t, err)
 
 	// Check total entry count
@@ -134,21 +134,21 @@ func TestSetDeploymentJobDataAndGetDeploymentData(t *testing.T) {
 	testJobData := dsdtd.createTestData(789, "job", true, 987, "jobPtr", false)
 
 	// Create the job data entries
-	require.NoError(t, setDeploymentData(tr.db, deployment, &td.jobName1, DeploymentJobInput, testInputData))
-	require.NoError(t, setDeploymentData(tr.db, deployment, &td.jobName1, DeploymentJobData, testJobData))
-	require.NoError(t, setDeploymentData(tr.db, deployment, &td.jobName2, DeploymentJobData, nil))
+	require.NoError(t, setDeploymentData(tr.tx, deployment, &td.jobName1, DeploymentJobInput, testInputData))
+	require.NoError(t, setDeploymentData(tr.tx, deployment, &td.jobName1, DeploymentJobData, testJobData))
+	require.NoError(t, setDeploymentData(tr.tx, deployment, &td.jobName2, DeploymentJobData, nil))
 	// Check that two entries have been created
 	assert.Equal(t, 3, dsdtd.getJobDataCount(t, tr.db, deployment))
 
 	// Update an existing job data entry
 	testInputData = dsdtd.createTestData(111, "modifiedInput", true, 999, "modifiedPtr", false)
-	require.NoError(t, setDeploymentData(tr.db, deployment, &td.jobName1, DeploymentJobInput, testInputData))
+	require.NoError(t, setDeploymentData(tr.tx, deployment, &td.jobName1, DeploymentJobInput, testInputData))
 	// Check that the number of entries remains the same
 	assert.Equal(t, 3, dsdtd.getJobDataCount(t, tr.db, deployment))
 
 	// Get the job data from the database
-	compareWithDB := func(job string, dataType deploymentDataType, expected interface{}) {
-		out, err := getDeploymentDataFromRegistry(tr.db, deployment, &job, dataType)
+	compareWithDB := func(job string, dataType DeploymentDataType, expected interface{}) {
+		out, err := getDeploymentDataFromRegistry(tr.tx, deployment, &job, dataType)
 		require.NoError(t, err)
 		dbJobData := out.(DeploymentJobDataTestStruct)
 		require.Equal(t, dsdtd.marshallJSON(t, expected), dsdtd.marshallJSON(t, dbJobData))
@@ -156,7 +156,7 @@ func TestSetDeploymentJobDataAndGetDeploymentData(t *testing.T) {
 	compareWithDB(td.jobName1, DeploymentJobInput, testInputData)
 	compareWithDB(td.jobName1, DeploymentJobData, testJobData)
 	// Check that the job with null data returns an error
-	_, err = getDeploymentDataFromRegistry(tr.db, deployment, &td.jobName2, DeploymentJobData)
+	_, err = getDeploymentDataFromRegistry(tr.tx, deployment, &td.jobName2, DeploymentJobData)
 	require.Equal(t, ErrDeploymentDataNoData, err)
 }
 
@@ -167,7 +167,7 @@ func TestSetDeploymentJobDataAndGetDeploymentDataOutValue(t *testing.T) {
 	td := getTestData(t)
 	dsdtd := deploymentJobDataTestData
 
-	deployment, err := newDeployment(tr.db, td.action, uuid.NewV4().String())
+	deployment, err := newDeployment(tr.tx, td.action, uuid.NewV4().String(), nil)
 	require.NoError(t, err)
 
 	// Check total entry count
@@ -178,20 +178,20 @@ func TestSetDeploymentJobDataAndGetDeploymentDataOutValue(t *testing.T) {
 
 	// Create the job data entries
 	const JobDataType = "test-type"
-	require.NoError(t, setDeploymentData(tr.db, deployment, &td.jobName1, JobDataType, testJobData))
+	require.NoError(t, setDeploymentData(tr.tx, deployment, &td.jobName1, JobDataType, testJobData))
 	// Check that two entries have been created
 	assert.Equal(t, 1, dsdtd.getJobDataCount(t, tr.db, deployment))
 
 	// Update an existing job data entry
 	testJobData = dsdtd.createTestData(111, "modifiedInput", true, 999, "modifiedPtr", false)
-	require.NoError(t, setDeploymentData(tr.db, deployment, &td.jobName1, JobDataType, testJobData))
+	require.NoError(t, setDeploymentData(tr.tx, deployment, &td.jobName1, JobDataType, testJobData))
 	// Check that the number of entries remains the same
 	assert.Equal(t, 1, dsdtd.getJobDataCount(t, tr.db, deployment))
 
 	// Get the job data from the database
-	compareWithDB := func(job string, dataType deploymentDataType, expected interface{}) {
+	compareWithDB := func(job string, dataType DeploymentDataType, expected interface{}) {
 		dbJobData := &DeploymentJobDataTestStruct{}
-		require.NoError(t, getDeploymentDataOutValue(tr.db, deployment, &job, dataType, dbJobData))
+		require.NoError(t, getDeploymentDataOutValue(tr.tx, deployment, &job, dataType, dbJobData))
 		require.NotNil(t, dbJobData)
 		require.Equal(t, dsdtd.marshallJSON(t, expected), dsdtd.marshallJSON(t, *dbJobData))
 	}
@@ -8,7 +8,7 @@ import (
 type DeploymentError struct {
 	gorm.Model
 	DeploymentID int
-	Deployment   *Deployment
+	Deployment   *Deployment `gorm:"association_autoupdate:false"`
 	Job          *string
 	Error        *string `gorm:"type:text"`
 }
@@ -23,20 +23,21 @@ type DeploymentErrors []*DeploymentError
 
 // newDeploymentError creates a new DeploymentError entry in persistent storage and returns a pointer to it.
 // If `job` is nil, the current job of the deployment is used
-func newDeploymentError(tx *gorm.DB, deployment *Deployment, job *string, err error) (*DeploymentError, error) {
+func newDeploymentError(tx DeploymentStore, deployment *Deployment, job *string, err error) (*DeploymentError, error) {
 	if job == nil {
 		job = &deployment.CurrentJob
 	}
 
 	errMsg := err.Error()
 	deploymentErr := &DeploymentError{
-		Deployment: deployment,
-		Job:        job,
-		Error:      &errMsg,
+		Deployment:   deployment,
+		DeploymentID: int(deployment.ID),
+		Job:          job,
+		Error:        &errMsg,
 	}
 
 	// Create the persistent storage entry
-	if err := tx.Create(deploymentErr).Error; err != nil {
+	if err := tx.CreateDeploymentError(deploymentErr); err != nil {
 		return nil, err
 	}
 
@@ -46,20 +47,6 @@ func newDeploymentError(tx *gorm.DB, deployment *Deployment, job *string, err er
 // getDeploymentErrors returns a slice of DeploymentError entries for a Deployment found in persistent storage.
 // `job` is not nil, this will return the errors for a single job,
 // otherwise this will return errors for all jobs.
-func getDeploymentErrors(tx *gorm.DB, deployment *Deployment, job *string) (DeploymentErrors, error) {
-	var deploymentErrs DeploymentErrors
-
-	query := tx.Where("deployment_id = ?", deployment.ID)
-
-	// Optionally filter by job
-	if job != nil {
-		query = query.Where("job = ?", *job)
-	}
-
-	err := query.Find(&deploymentErrs).Error
-	if err != nil {
-		return nil, err
-	}
-
-	return deploymentErrs, nil
+func getDeploymentErrors(tx DeploymentStore, deployment *Deployment, job *string) (DeploymentErrors, error) {
+	return tx.GetDeploymentErrors(deployment, job)
 }
@@ -0,0 +1,188 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/gz-go/v7"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrDeploymentLeaseLost is returned when a service loses the lease of a deployment it is executing because it was
+	// taken over by another service instance.
+	ErrDeploymentLeaseLost = errors.New("deployment lease was lost")
+)
+
+// LeaseConfig contains the configuration used by action services to lease the deployments they execute.
+//
+// Services lease a deployment before running its jobs, and renew the lease periodically while the deployment is
+// being executed. Other service instances cannot execute a leased deployment until its lease is released or expires.
+// Expired leases are taken over, which allows deployments of service instances that died to be resumed.
+type LeaseConfig struct {
+	// Owner identifies the service instance. Every service instance sharing a DeploymentStore must have a different
+	// owner (e.g. the pod name).
+	Owner string `validate:"required"`
+	// TTL is the time a lease lasts if it is not renewed.
+	TTL time.Duration `validate:"gt=0"`
+	// HeartbeatInterval is the time between lease renewals. It must be shorter than TTL.
+	// If zero, a third of the TTL is used.
+	HeartbeatInterval time.Duration `validate:"min=0,ltfield=TTL"`
+}
+
+// heartbeatInterval returns the time between lease renewals.
+func (c LeaseConfig) heartbeatInterval() time.Duration {
+	if c.HeartbeatInterval > 0 {
+		return c.HeartbeatInterval
+	}
+	return c.TTL / 3
+}
+
+// deploymentLease is a lease held by a service on a deployment it is executing.
+// A nil *deploymentLease is a valid lease for services that do not lease deployments.
+type deploymentLease struct {
+	// tx is the store the lease is held in.
+	tx DeploymentStore
+	// uuid contains the UUID of the leased deployment.
+	uuid string
+	// config contains the lease configuration.
+	config LeaseConfig
+	// logger is used to log lease renewal errors.
+	logger gz.Logger
+	// lost is closed when the lease is lost.
+	lost chan struct{}
+	// lostOnce is used to close lost only once.
+	lostOnce sync.Once
+	// stop is closed to stop the heartbeat.
+	stop chan struct{}
+	// stopOnce is used to close stop only once.
+	stopOnce sync.Once
+}
+
+// acquireDeploymentLease leases a deployment and returns the lease.
+// Returns ErrDeploymentLeased if the deployment is leased by another owner.
+func acquireDeploymentLease(tx DeploymentStore, uuid string, config LeaseConfig,
+	logger gz.Logger) (*deploymentLease, error) {
+
+	if err := tx.AcquireDeploymentLease(uuid, config.Owner, config.TTL); err != nil {
+		return nil, fmt.Errorf("%w: deployment [%s]", err, uuid)
+	}
+
+	return newDeploymentLease(tx, uuid, config, logger), nil
+}
+
+// newDeploymentLease returns a lease for a deployment that is already leased to `config.Owner` (e.g. a deployment
+// created leased).
+func newDeploymentLease(tx DeploymentStore, uuid string, config LeaseConfig, logger gz.Logger) *deploymentLease {
+	return &deploymentLease{
+		tx:     tx,
+		uuid:   uuid,
+		config: config,
+		logger: logger,
+		lost:   make(chan struct{}),
+		stop:   make(chan struct{}),
+	}
+}
+
+// heartbeat renews the lease periodically in a separate goroutine until the lease is released.
+// `onLost` is called if the lease is lost. After renewing the lease, the deployment is checked for stop requests made
+// through other service instances, and `onStopRequested` is called if the deployment was stopped.
+func (l *deploymentLease) heartbeat(onLost func(), onStopRequested func()) {
+	if l == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(l.config.heartbeatInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				err := l.tx.AcquireDeploymentLease(l.uuid, l.config.Owner, l.config.TTL)
+				if errors.Is(err, ErrDeploymentLeased) {
+					l.logger.Debug(fmt.Sprintf("Lease of deployment [%s] was taken over by another owner", l.uuid))
+					l.markLost()
+					onLost()
+					return
+				}
+				// Other errors are transient. The lease is kept until it expires.
+				if err != nil {
+					l.logger.Debug(fmt.Sprintf("Failed to renew lease of deployment [%s]: %s", l.uuid, err))
+					continue
+				}
+
+				stored, err := l.tx.GetDeployment(l.uuid)
+				if err != nil {
+					l.logger.Debug(fmt.Sprintf("Failed to check stop requests of deployment [%s]: %s", l.uuid, err))
+					continue
+				}
+				if stored.StopRequested {
+					onStopRequested()
+				}
+			}
+		}
+	}()
+}
+
+// verify checks that the lease is still held.
+// Returns ErrDeploymentLeaseLost if the lease was taken over by another owner.
+func (l *deploymentLease) verify() error {
+	if l == nil {
+		return nil
+	}
+	if l.isLost() {
+		return ErrDeploymentLeaseLost
+	}
+
+	stored, err := l.tx.GetDeployment(l.uuid)
+	if err != nil {
+		return err
+	}
+	if stored.LeaseOwner == nil || *stored.LeaseOwner != l.config.Owner {
+		l.markLost()
+		return ErrDeploymentLeaseLost
+	}
+
+	return nil
+}
+
+// markLost marks the lease as lost.
+func (l *deploymentLease) markLost() {
+	l.lostOnce.Do(func() {
+		close(l.lost)
+	})
+}
+
+// isLost returns true if the lease was lost.
+func (l *deploymentLease) isLost() bool {
+	if l == nil {
+		return false
+	}
+
+	select {
+	case <-l.lost:
+		return true
+	default:
+		return false
+	}
+}
+
+// release stops renewing the lease and releases it. Lost leases are not released, as they are held by another owner.
+func (l *deploymentLease) release() error {
+	if l == nil {
+		return nil
+	}
+
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+
+	if l.isLost() {
+		return nil
+	}
+
+	return l.tx.ReleaseDeploymentLease(l.uuid, l.config.Owner)
+}
@@ -0,0 +1,355 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestLeaseService creates a service that leases deployments and registers an action with the given jobs.
+func newTestLeaseService(t *testing.T, owner string, jobs Jobs) Servicer {
+	service, err := NewServiceWithLease(gz.NewLoggerNoRollbar("Actions", gz.VerbosityDebug), LeaseConfig{
+		Owner: owner,
+		TTL:   time.Minute,
+	})
+	require.NoError(t, err)
+
+	action, err := NewAction(jobs)
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, "leased", action))
+
+	return service
+}
+
+// createLeaseTestJob creates a job that runs `fn` and passes through its input.
+func createLeaseTestJob(name string, fn func(tx DeploymentStore, deployment *Deployment) error) *Job {
+	return &Job{
+		Name: name,
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}) (interface{}, error) {
+
+			return value, fn(tx, deployment)
+		},
+		InputType:  NilJobDataType,
+		OutputType: NilJobDataType,
+	}
+}
+
+func TestNewServiceWithLeaseInvalidConfig(t *testing.T) {
+	logger := gz.NewLoggerNoRollbar("Actions", gz.VerbosityDebug)
+
+	_, err := NewServiceWithLease(logger, LeaseConfig{TTL: time.Minute})
+	assert.Error(t, err)
+
+	_, err = NewServiceWithLease(logger, LeaseConfig{Owner: "test"})
+	assert.Error(t, err)
+
+	_, err = NewServiceWithLease(logger, LeaseConfig{Owner: "test", TTL: time.Minute, HeartbeatInterval: time.Hour})
+	assert.Error(t, err)
+}
+
+func TestMemoryDeploymentStoreLease(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	require.NoError(t, tx.CreateDeployment(&Deployment{UUID: "test"}))
+
+	assert.True(t, errors.Is(tx.AcquireDeploymentLease("missing", "a", time.Minute), ErrDeploymentNotFound))
+
+	// Leases are exclusive
+	require.NoError(t, tx.AcquireDeploymentLease("test", "a", time.Minute))
+	assert.True(t, errors.Is(tx.AcquireDeploymentLease("test", "b", time.Minute), ErrDeploymentLeased))
+
+	// Owners can renew their leases
+	require.NoError(t, tx.AcquireDeploymentLease("test", "a", time.Millisecond))
+
+	// Expired leases can be taken over
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, tx.AcquireDeploymentLease("test", "b", time.Minute))
+
+	// Updating a deployment does not modify its lease
+	deployment, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	deployment.LeaseOwner = nil
+	require.NoError(t, tx.UpdateDeployment(deployment))
+
+	// Leases can only be released by their owner
+	require.NoError(t, tx.ReleaseDeploymentLease("test", "a"))
+	deployment, err = tx.GetDeployment("test")
+	require.NoError(t, err)
+	require.NotNil(t, deployment.LeaseOwner)
+	assert.Equal(t, "b", *deployment.LeaseOwner)
+
+	require.NoError(t, tx.ReleaseDeploymentLease("test", "b"))
+	deployment, err = tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.Nil(t, deployment.LeaseOwner)
+	assert.Nil(t, deployment.LeaseExpiresAt)
+}
+
+func TestExecuteLeasedDeploymentConcurrentWorkers(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	// Create an interrupted deployment
+	deployment := &Deployment{UUID: "test", Action: "leased", CurrentJob: "job",
+		Status: deploymentStatusRunning}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var runs int
+	var runsLock sync.Mutex
+	jobs := Jobs{
+		createLeaseTestJob("job", func(tx DeploymentStore, deployment *Deployment) error {
+			runsLock.Lock()
+			runs++
+			runsLock.Unlock()
+			close(started)
+			<-release
+			return nil
+		}),
+	}
+
+	workerA := newTestLeaseService(t, "a", jobs)
+	workerB := newTestLeaseService(t, "b", jobs)
+
+	// Both workers try to resume the same deployment
+	errs := make(chan error, 2)
+	go func() {
+		stored, err := tx.GetDeployment("test")
+		if err != nil {
+			errs <- err
+			return
+		}
+		errs <- workerA.Execute(context.Background(), nil, tx, &ExecuteInput{ActionName: "leased", Deployment: stored}, nil)
+	}()
+	<-started
+
+	stored, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	errB := workerB.Execute(context.Background(), nil, tx, &ExecuteInput{ActionName: "leased", Deployment: stored}, nil)
+	assert.True(t, errors.Is(errB, ErrDeploymentLeased))
+
+	close(release)
+	require.NoError(t, <-errs)
+
+	// The deployment was executed once and its lease was released
+	assert.Equal(t, 1, runs)
+	stored, err = tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.Equal(t, deploymentStatusFinished, stored.Status)
+	assert.Nil(t, stored.LeaseOwner)
+}
+
+// leaseTestCreateStore is a DeploymentStore that records the lease owner of the deployments it creates.
+type leaseTestCreateStore struct {
+	DeploymentStore
+	owners map[string]*string
+}
+
+func (s *leaseTestCreateStore) CreateDeployment(deployment *Deployment) error {
+	s.owners[deployment.UUID] = deployment.LeaseOwner
+	return s.DeploymentStore.CreateDeployment(deployment)
+}
+
+func TestExecuteNewDeploymentIsCreatedLeased(t *testing.T) {
+	tx := &leaseTestCreateStore{DeploymentStore: NewMemoryDeploymentStore(), owners: make(map[string]*string)}
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var owner *string
+	jobs := Jobs{
+		createLeaseTestJob("job", func(tx DeploymentStore, deployment *Deployment) error {
+			stored, err := tx.GetDeployment(deployment.UUID)
+			if err != nil {
+				return err
+			}
+			owner = stored.LeaseOwner
+			return nil
+		}),
+	}
+	service := newTestLeaseService(t, "a", jobs)
+
+	require.NoError(t, service.Execute(context.Background(), nil, tx, &ExecuteInput{ActionName: "leased", GroupID: "test"}, nil))
+
+	// The deployment was leased when it was created, and kept the lease while running
+	require.NotNil(t, tx.owners["test"])
+	assert.Equal(t, "a", *tx.owners["test"])
+	require.NotNil(t, owner)
+	assert.Equal(t, "a", *owner)
+
+	// The lease is released once the deployment finishes
+	stored, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.Nil(t, stored.LeaseOwner)
+	assert.Nil(t, stored.LeaseExpiresAt)
+}
+
+func TestExecuteLeasedDeploymentStaleLeaseTakeover(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var calls []string
+	jobs := Jobs{
+		createLeaseTestJob("a", func(tx DeploymentStore, deployment *Deployment) error {
+			calls = append(calls, "a")
+			return nil
+		}),
+		createLeaseTestJob("b", func(tx DeploymentStore, deployment *Deployment) error {
+			calls = append(calls, "b")
+			return nil
+		}),
+	}
+	service := newTestLeaseService(t, "new", jobs)
+
+	// Create a deployment leased by a service instance that died
+	deployment := &Deployment{UUID: "test", Action: "leased", CurrentJob: "a", Status: deploymentStatusRunning}
+	require.NoError(t, tx.CreateDeployment(deployment))
+	require.NoError(t, tx.AcquireDeploymentLease("test", "dead", time.Millisecond))
+
+	// The previous owner progressed the deployment after it was read
+	stale, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	deployment.CurrentJob = "b"
+	require.NoError(t, tx.UpdateDeployment(deployment))
+
+	time.Sleep(5 * time.Millisecond)
+
+	// The stale lease is taken over and the deployment is resumed from its stored state
+	require.NoError(t, service.Execute(context.Background(), nil, tx, &ExecuteInput{ActionName: "leased", Deployment: stale}, nil))
+	assert.Equal(t, []string{"b"}, calls)
+}
+
+func TestExecuteLeasedDeploymentLeaseLost(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var calls []string
+	jobs := Jobs{
+		createLeaseTestJob("a", func(tx DeploymentStore, deployment *Deployment) error {
+			calls = append(calls, "a")
+
+			// Simulate another service instance taking over the lease
+			if err := tx.ReleaseDeploymentLease(deployment.UUID, "a"); err != nil {
+				return err
+			}
+			return tx.AcquireDeploymentLease(deployment.UUID, "b", time.Minute)
+		}),
+		createLeaseTestJob("b", func(tx DeploymentStore, deployment *Deployment) error {
+			calls = append(calls, "b")
+			return nil
+		}),
+	}
+	service := newTestLeaseService(t, "a", jobs)
+
+	executeInput := &ExecuteInput{ActionName: "leased", GroupID: "test"}
+	err := service.Execute(context.Background(), nil, tx, executeInput, nil)
+	require.True(t, errors.Is(err, ErrDeploymentLeaseLost))
+
+	// The deployment stops running jobs and is left to the new owner
+	assert.Equal(t, []string{"a"}, calls)
+	stored, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.Equal(t, deploymentStatusRunning, stored.Status)
+	require.NotNil(t, stored.LeaseOwner)
+	assert.Equal(t, "b", *stored.LeaseOwner)
+}
+
+func TestExecuteLeasedDeploymentStoppedByAnotherService(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	started := make(chan struct{})
+	action, err := NewAction(Jobs{
+		{
+			Name: "block",
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value interface{}) (interface{}, error) {
+
+				close(started)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			InputType:  NilJobDataType,
+			OutputType: NilJobDataType,
+		},
+	})
+	require.NoError(t, err)
+
+	logger := gz.NewLoggerNoRollbar("Actions", gz.VerbosityDebug)
+	workerA, err := NewServiceWithLease(logger, LeaseConfig{
+		Owner:             "a",
+		TTL:               time.Minute,
+		HeartbeatInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.NoError(t, workerA.RegisterAction(nil, "leased", action))
+	workerB := newTestLeaseService(t, "b", Jobs{createLeaseTestJob("job", nil)})
+
+	done := make(chan error)
+	go func() {
+		done <- workerA.Execute(context.Background(), nil, tx, &ExecuteInput{ActionName: "leased", GroupID: "test"},
+			nil)
+	}()
+
+	// The deployment is stopped through a service instance that is not executing it
+	<-started
+	require.NoError(t, workerB.Stop(tx, "test"))
+
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, ErrExecutionStopped))
+	case <-time.After(5 * time.Second):
+		t.Fatal("The deployment was not stopped")
+	}
+}
+
+func TestResumerSkipsLeasedDeployments(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	jobs := Jobs{
+		createLeaseTestJob("job", func(tx DeploymentStore, deployment *Deployment) error {
+			return nil
+		}),
+	}
+	service := newTestLeaseService(t, "a", jobs)
+
+	require.NoError(t, tx.CreateDeployment(&Deployment{UUID: "free", Action: "leased", CurrentJob: "job",
+		Status: deploymentStatusRunning}))
+	require.NoError(t, tx.CreateDeployment(&Deployment{UUID: "leased", Action: "leased", CurrentJob: "job",
+		Status: deploymentStatusRunning}))
+	require.NoError(t, tx.AcquireDeploymentLease("leased", "b", time.Minute))
+
+	resumer, err := NewResumer(service, func(ctx context.Context, deployment *Deployment) (State, error) {
+		return struct{}{}, nil
+	}, 2, gz.NewLoggerNoRollbar("Actions", gz.VerbosityDebug))
+	require.NoError(t, err)
+
+	report, err := resumer.Resume(context.Background(), tx)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 2)
+	assert.NoError(t, report.Results[0].Err)
+	assert.True(t, errors.Is(report.Results[1].Err, ErrDeploymentLeased))
+	assert.Empty(t, report.Failed())
+
+	// Deployments leased by other owners are not modified
+	stored, err := tx.GetDeployment("leased")
+	require.NoError(t, err)
+	assert.Equal(t, deploymentStatusRunning, stored.Status)
+}
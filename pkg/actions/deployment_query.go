@@ -0,0 +1,241 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrInvalidDeploymentFilter is returned when a deployment filter contains invalid pagination values.
+	ErrInvalidDeploymentFilter = errors.New("deployment filter offset and limit cannot be negative")
+)
+
+// DeploymentFilter is used to select the deployments returned by ListDeployments.
+// Nil fields are not used to filter deployments.
+type DeploymentFilter struct {
+	// Action filters deployments by the name of the action they execute.
+	Action *string
+	// Status filters deployments by their status.
+	Status *DeploymentStatus
+	// CreatedAfter filters deployments created at or after a specific time.
+	CreatedAfter *time.Time
+	// CreatedBefore filters deployments created before a specific time.
+	CreatedBefore *time.Time
+	// Offset contains the number of deployments skipped.
+	Offset int
+	// Limit contains the maximum number of deployments returned.
+	// If zero, all deployments are returned.
+	Limit int
+	// OldestFirst returns deployments from oldest to newest instead of from newest to oldest.
+	OldestFirst bool
+}
+
+// matches checks if a deployment matches the filter. Pagination fields are not considered.
+func (f *DeploymentFilter) matches(deployment *Deployment) bool {
+	if f.Action != nil && deployment.Action != *f.Action {
+		return false
+	}
+	if f.Status != nil && deployment.Status != *f.Status {
+		return false
+	}
+	if f.CreatedAfter != nil && deployment.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !deployment.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+
+	return true
+}
+
+// DeploymentPage contains a page of deployments returned by ListDeployments.
+type DeploymentPage struct {
+	// Deployments contains the deployments in the page, from newest to oldest unless the filter selected the oldest
+	// deployments first.
+	Deployments Deployments
+	// Total contains the total number of deployments that match the filter.
+	Total int
+	// Offset contains the number of deployments skipped.
+	Offset int
+	// Limit contains the maximum number of deployments in the page.
+	Limit int
+}
+
+// ListDeployments returns a page of deployments that match a filter.
+func ListDeployments(tx DeploymentStore, filter DeploymentFilter) (*DeploymentPage, error) {
+	if filter.Offset < 0 || filter.Limit < 0 {
+		return nil, ErrInvalidDeploymentFilter
+	}
+
+	deployments, total, err := tx.ListDeployments(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeploymentPage{
+		Deployments: deployments,
+		Total:       total,
+		Offset:      filter.Offset,
+		Limit:       filter.Limit,
+	}, nil
+}
+
+// DecodedDeploymentData contains a decoded deployment job data entry.
+type DecodedDeploymentData struct {
+	// Type contains the type of data stored for the job.
+	Type DeploymentDataType
+	// DataType contains the name of the data type of the value stored.
+	DataType string
+	// Value contains the decoded value.
+	// Values whose data type is registered in the job data type registry are decoded to that type. Other values are
+	// decoded to generic JSON values (e.g. map[string]interface{}). Encrypted values are not decoded.
+	Value interface{}
+	// Raw contains the encoded value.
+	Raw *string
+	// CreatedAt contains the time the entry was created.
+	CreatedAt time.Time
+	// UpdatedAt contains the time the entry was last updated.
+	UpdatedAt time.Time
+}
+
+// newDecodedDeploymentData decodes a deployment job data entry.
+func newDecodedDeploymentData(data *DeploymentData) (*DecodedDeploymentData, error) {
+	out := &DecodedDeploymentData{
+		Type:      data.Type,
+		DataType:  data.DataType,
+		Raw:       data.Data,
+		CreatedAt: data.CreatedAt,
+		UpdatedAt: data.UpdatedAt,
+	}
+
+	// Encrypted values can only be decoded after being decrypted
+	if data.Data == nil || isEncrypted(*data.Data) {
+		return out, nil
+	}
+
+	// Use the registry to decode the value to its original type
+	if _, err := jobDataTypeRegistry.getType(data.DataType); err == nil {
+		value, err := data.Value()
+		if err != nil {
+			return nil, err
+		}
+		out.Value = value
+
+		return out, nil
+	}
+
+	// Decode values with unregistered types as generic values
+	if err := json.Unmarshal([]byte(*data.Data), &out.Value); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DeploymentJobRecord contains the information recorded by a deployment for a single job.
+type DeploymentJobRecord struct {
+	// Job contains the name of the job.
+	Job string
+	// StartedAt contains the time of the first entry recorded for the job.
+	StartedAt time.Time
+	// Skipped is true if the job was skipped because of its SkipIf predicate.
+	Skipped bool
+	// Input contains the input received by the job, if it was persisted.
+	Input *DecodedDeploymentData
+	// Output contains the output returned by the job, if it was persisted.
+	Output *DecodedDeploymentData
+	// Data contains the rest of the data entries stored for the job (e.g. data stored by calling
+	// Deployment.SetJobData).
+	Data []*DecodedDeploymentData
+	// Errors contains the errors recorded for the job.
+	Errors DeploymentErrors
+}
+
+// DeploymentDetails contains a deployment and everything it recorded.
+type DeploymentDetails struct {
+	// Deployment contains the deployment.
+	Deployment *Deployment
+	// Timeline contains a record for each job the deployment recorded data or errors for, ordered by the time of
+	// their first entry. Jobs that did not persist any data and did not fail are not included.
+	Timeline []*DeploymentJobRecord
+	// Errors contains all the errors recorded by the deployment, in the order they were created.
+	Errors DeploymentErrors
+}
+
+// GetDeploymentDetails returns a deployment with its job timeline, decoded job data and errors.
+// Returns ErrDeploymentNotFound if the deployment does not exist.
+func GetDeploymentDetails(tx DeploymentStore, uuid string) (*DeploymentDetails, error) {
+	deployment, err := tx.GetDeployment(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSet, err := tx.GetDeploymentDataSet(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := deployment.GetErrors(tx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group entries by job
+	var timeline []*DeploymentJobRecord
+	records := make(map[string]*DeploymentJobRecord)
+	getRecord := func(job string, createdAt time.Time) *DeploymentJobRecord {
+		record, ok := records[job]
+		if !ok {
+			record = &DeploymentJobRecord{
+				Job:       job,
+				StartedAt: createdAt,
+			}
+			records[job] = record
+			timeline = append(timeline, record)
+		}
+		if createdAt.Before(record.StartedAt) {
+			record.StartedAt = createdAt
+		}
+
+		return record
+	}
+
+	for _, data := range dataSet {
+		value, err := newDecodedDeploymentData(data)
+		if err != nil {
+			return nil, err
+		}
+
+		record := getRecord(data.Job, data.CreatedAt)
+		switch data.Type {
+		case DeploymentJobInput:
+			record.Input = value
+		case DeploymentJobOutput:
+			record.Output = value
+		case DeploymentJobSkipped:
+			record.Skipped = value.Value == true
+		default:
+			record.Data = append(record.Data, value)
+		}
+	}
+
+	for _, err := range errs {
+		if err.Job == nil {
+			continue
+		}
+		record := getRecord(*err.Job, err.CreatedAt)
+		record.Errors = append(record.Errors, err)
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].StartedAt.Before(timeline[j].StartedAt)
+	})
+
+	return &DeploymentDetails{
+		Deployment: deployment,
+		Timeline:   timeline,
+		Errors:     errs,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// queryTestStruct is used to test decoding job data registered in the job data type registry.
+type queryTestStruct struct {
+	Value string
+}
+
+func TestListDeployments(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+
+	for _, deployment := range []*Deployment{
+		{UUID: "a", Action: "start", Status: deploymentStatusFinished},
+		{UUID: "b", Action: "stop", Status: deploymentStatusFinished},
+		{UUID: "c", Action: "start", Status: deploymentStatusRunning},
+		{UUID: "d", Action: "start", Status: deploymentStatusFinished},
+	} {
+		require.NoError(t, tx.CreateDeployment(deployment))
+	}
+
+	getUUIDs := func(page *DeploymentPage) []string {
+		uuids := make([]string, len(page.Deployments))
+		for i, deployment := range page.Deployments {
+			uuids[i] = deployment.UUID
+		}
+		return uuids
+	}
+
+	// No filter returns every deployment from newest to oldest
+	page, err := ListDeployments(tx, DeploymentFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "c", "b", "a"}, getUUIDs(page))
+	assert.Equal(t, 4, page.Total)
+
+	// Filter by action and status
+	action := "start"
+	status := deploymentStatusFinished
+	page, err = ListDeployments(tx, DeploymentFilter{Action: &action, Status: &status})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "a"}, getUUIDs(page))
+
+	// Paginate
+	page, err = ListDeployments(tx, DeploymentFilter{Action: &action, Offset: 1, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, getUUIDs(page))
+	assert.Equal(t, 3, page.Total)
+	assert.Equal(t, 1, page.Offset)
+	assert.Equal(t, 1, page.Limit)
+
+	// Paginate from oldest to newest
+	page, err = ListDeployments(tx, DeploymentFilter{Action: &action, Offset: 1, Limit: 2, OldestFirst: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, getUUIDs(page))
+
+	page, err = ListDeployments(tx, DeploymentFilter{Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Deployments)
+	assert.Equal(t, 4, page.Total)
+
+	// Filter by time range
+	future := time.Now().Add(time.Hour)
+	page, err = ListDeployments(tx, DeploymentFilter{CreatedAfter: &future})
+	require.NoError(t, err)
+	assert.Empty(t, page.Deployments)
+
+	page, err = ListDeployments(tx, DeploymentFilter{CreatedBefore: &future})
+	require.NoError(t, err)
+	assert.Len(t, page.Deployments, 4)
+
+	// Invalid pagination
+	_, err = ListDeployments(tx, DeploymentFilter{Limit: -1})
+	assert.True(t, errors.Is(err, ErrInvalidDeploymentFilter))
+}
+
+func TestGetDeploymentDetails(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+	jobDataTypeRegistry.register(GetJobDataType(&queryTestStruct{}))
+
+	deployment := &Deployment{UUID: "test", Action: "start", CurrentJob: "job1", Status: deploymentStatusRunning}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	job1 := "job1"
+	job2 := "job2"
+	require.NoError(t, deployment.SetJobData(tx, &job1, DeploymentJobInput, &queryTestStruct{Value: "input"}))
+	require.NoError(t, deployment.SetJobData(tx, &job1, DeploymentJobData, map[string]int{"count": 1}))
+	require.NoError(t, deployment.addJobError(tx, &job2, assert.AnError))
+	require.NoError(t, deployment.SetJobData(tx, &job2, DeploymentJobOutput, &queryTestStruct{Value: "output"}))
+
+	details, err := GetDeploymentDetails(tx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "test", details.Deployment.UUID)
+	require.Len(t, details.Errors, 1)
+
+	require.Len(t, details.Timeline, 2)
+
+	record := details.Timeline[0]
+	assert.Equal(t, "job1", record.Job)
+	require.NotNil(t, record.Input)
+	assert.Equal(t, &queryTestStruct{Value: "input"}, record.Input.Value)
+	assert.Nil(t, record.Output)
+	require.Len(t, record.Data, 1)
+	assert.Equal(t, map[string]interface{}{"count": float64(1)}, record.Data[0].Value)
+	assert.Empty(t, record.Errors)
+
+	record = details.Timeline[1]
+	assert.Equal(t, "job2", record.Job)
+	assert.Nil(t, record.Input)
+	require.NotNil(t, record.Output)
+	assert.Equal(t, &queryTestStruct{Value: "output"}, record.Output.Value)
+	require.Len(t, record.Errors, 1)
+	assert.Equal(t, assert.AnError.Error(), *record.Errors[0].Error)
+
+	// Missing deployments
+	_, err = GetDeploymentDetails(tx, "missing")
+	assert.True(t, errors.Is(err, ErrDeploymentNotFound))
+}
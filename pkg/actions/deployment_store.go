@@ -0,0 +1,75 @@
+package actions
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrDeploymentNotFound is returned when a deployment is not found in a DeploymentStore.
+	ErrDeploymentNotFound = errors.New("deployment not found")
+	// ErrDeploymentDataNotFound is returned when a deployment job data entry is not found in a DeploymentStore.
+	ErrDeploymentDataNotFound = errors.New("deployment data not found")
+	// ErrDeploymentExists is returned when trying to create a deployment with the UUID of an existing deployment.
+	ErrDeploymentExists = errors.New("deployment already exists")
+	// ErrDeploymentLeased is returned when trying to lease a deployment that is leased by another owner.
+	ErrDeploymentLeased = errors.New("deployment is leased by another owner")
+)
+
+// DeploymentStore persists the state of action deployments, including their job data and errors.
+// Action services use a DeploymentStore to keep track of deployments, and to resume them in case of interruption.
+//
+// A DeploymentStore is passed to every job function. Jobs should use it through the Deployment methods (e.g.
+// Deployment.SetJobData) instead of calling it directly.
+//
+// Jobs in a parallel job group access the DeploymentStore concurrently. Implementations must be safe for concurrent
+// use.
+type DeploymentStore interface {
+	// CreateDeployment creates a new deployment entry.
+	// Returns ErrDeploymentExists if a deployment with the same UUID already exists.
+	CreateDeployment(deployment *Deployment) error
+	// UpdateDeployment updates an existing deployment entry with the current values of the deployment.
+	// The StopRequested flag and lease fields are not updated.
+	UpdateDeployment(deployment *Deployment) error
+	// GetDeployment returns the deployment with the given UUID.
+	// Returns ErrDeploymentNotFound if the deployment does not exist.
+	GetDeployment(uuid string) (*Deployment, error)
+	// GetDeploymentsByStatus returns all the deployments with the given status.
+	GetDeploymentsByStatus(status DeploymentStatus) (Deployments, error)
+	// ListDeployments returns the deployments that match a filter, and the total number of deployments that match the
+	// filter without taking pagination into account. Deployments are returned from newest to oldest, unless
+	// filter.OldestFirst is set.
+	ListDeployments(filter DeploymentFilter) (Deployments, int, error)
+	// RequestDeploymentStop sets the StopRequested flag of the deployment with the given UUID.
+	// UpdateDeployment never modifies this flag, so that a stop request is not lost if it is made while the deployment
+	// is being updated.
+	// Returns ErrDeploymentNotFound if the deployment does not exist.
+	RequestDeploymentStop(uuid string) error
+	// DeleteDeployment permanently deletes a deployment, including its job data and errors.
+	// Returns ErrDeploymentNotFound if the deployment does not exist.
+	DeleteDeployment(uuid string) error
+	// AcquireDeploymentLease leases the deployment with the given UUID to `owner` until `ttl` elapses.
+	// A lease can be acquired if the deployment is not leased, if it is already leased by `owner`, or if its lease has
+	// expired. Acquiring a lease already held by `owner` renews it.
+	// Returns ErrDeploymentLeased if the deployment is leased by another owner.
+	// Returns ErrDeploymentNotFound if the deployment does not exist.
+	AcquireDeploymentLease(uuid string, owner string, ttl time.Duration) error
+	// ReleaseDeploymentLease releases the lease of the deployment with the given UUID if it is held by `owner`.
+	// Returns ErrDeploymentNotFound if the deployment does not exist.
+	ReleaseDeploymentLease(uuid string, owner string) error
+
+	// SetDeploymentData creates a deployment job data entry.
+	// Entries are identified by their deployment, job and type. If an entry already exists, it is replaced.
+	SetDeploymentData(data *DeploymentData) error
+	// GetDeploymentData returns the data entry of a specific type for a deployment job.
+	// Returns ErrDeploymentDataNotFound if the entry does not exist.
+	GetDeploymentData(deployment *Deployment, job string, dataType DeploymentDataType) (*DeploymentData, error)
+	// GetDeploymentDataSet returns all the data entries of a deployment in the order they were created.
+	GetDeploymentDataSet(deployment *Deployment) (DeploymentDataSet, error)
+
+	// CreateDeploymentError creates a deployment error entry.
+	CreateDeploymentError(deploymentError *DeploymentError) error
+	// GetDeploymentErrors returns the errors of a deployment in the order they were created.
+	// If `job` is not nil, only the errors for that job are returned.
+	GetDeploymentErrors(deployment *Deployment, job *string) (DeploymentErrors, error)
+}
@@ -0,0 +1,214 @@
+package actions
+
+import (
+	"errors"
+)
+
+var (
+	// ErrDeploymentStoreNotEncrypted is returned when trying to re-encrypt data in a store that does not encrypt data.
+	ErrDeploymentStoreNotEncrypted = errors.New("deployment store does not encrypt data")
+)
+
+// DeploymentDataFilter selects deployment data entries.
+type DeploymentDataFilter func(data *DeploymentData) bool
+
+// EncryptDataTypes returns a DeploymentDataFilter that selects entries of specific deployment data types (e.g. job
+// inputs).
+func EncryptDataTypes(types ...DeploymentDataType) DeploymentDataFilter {
+	return func(data *DeploymentData) bool {
+		for _, dataType := range types {
+			if data.Type == dataType {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// EncryptValueTypes returns a DeploymentDataFilter that selects entries that contain values with the same type as
+// any of the values passed.
+func EncryptValueTypes(values ...interface{}) DeploymentDataFilter {
+	names := make(map[string]bool, len(values))
+	for _, value := range values {
+		names[GetJobDataTypeName(value)] = true
+	}
+
+	return func(data *DeploymentData) bool {
+		return names[data.DataType]
+	}
+}
+
+// encryptedDeploymentStore is a DeploymentStore that encrypts deployment data before storing it in another store.
+type encryptedDeploymentStore struct {
+	DeploymentStore
+	// encryptor is used to encrypt and decrypt data.
+	encryptor Encryptor
+	// filters contains the filters used to select the entries that are encrypted.
+	filters []DeploymentDataFilter
+}
+
+// NewEncryptedDeploymentStore returns a DeploymentStore that encrypts the Data field of deployment data entries
+// before storing them in `store`.
+//
+// Only entries selected by at least one of the `filters` are encrypted, so that non-sensitive data stays readable
+// in the underlying store. Encrypted entries are always decrypted when read, even if they are no longer selected by
+// the filters.
+func NewEncryptedDeploymentStore(store DeploymentStore, encryptor Encryptor,
+	filters ...DeploymentDataFilter) DeploymentStore {
+
+	return &encryptedDeploymentStore{
+		DeploymentStore: store,
+		encryptor:       encryptor,
+		filters:         filters,
+	}
+}
+
+// shouldEncrypt checks if a data entry should be encrypted.
+func (s *encryptedDeploymentStore) shouldEncrypt(data *DeploymentData) bool {
+	for _, filter := range s.filters {
+		if filter(data) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encrypt returns a copy of a data entry with its data encrypted.
+func (s *encryptedDeploymentStore) encrypt(data *DeploymentData) (*DeploymentData, error) {
+	out := *data
+	if data.Data == nil {
+		return &out, nil
+	}
+
+	encrypted, err := s.encryptor.Encrypt([]byte(*data.Data))
+	if err != nil {
+		return nil, err
+	}
+	out.Data = &encrypted
+
+	return &out, nil
+}
+
+// decrypt decrypts the data of a data entry in place, if it is encrypted.
+func (s *encryptedDeploymentStore) decrypt(data *DeploymentData) error {
+	if data.Data == nil || !isEncrypted(*data.Data) {
+		return nil
+	}
+
+	decrypted, err := s.encryptor.Decrypt(*data.Data)
+	if err != nil {
+		return err
+	}
+	value := string(decrypted)
+	data.Data = &value
+
+	return nil
+}
+
+// SetDeploymentData encrypts a deployment job data entry if it is selected by the store filters, and stores it.
+func (s *encryptedDeploymentStore) SetDeploymentData(data *DeploymentData) error {
+	if !s.shouldEncrypt(data) {
+		return s.DeploymentStore.SetDeploymentData(data)
+	}
+
+	encrypted, err := s.encrypt(data)
+	if err != nil {
+		return err
+	}
+	if err := s.DeploymentStore.SetDeploymentData(encrypted); err != nil {
+		return err
+	}
+	data.Model = encrypted.Model
+
+	return nil
+}
+
+// GetDeploymentData returns the decrypted data entry of a specific type for a deployment job.
+func (s *encryptedDeploymentStore) GetDeploymentData(deployment *Deployment, job string,
+	dataType DeploymentDataType) (*DeploymentData, error) {
+
+	data, err := s.DeploymentStore.GetDeploymentData(deployment, job, dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.decrypt(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetDeploymentDataSet returns all the decrypted data entries of a deployment.
+func (s *encryptedDeploymentStore) GetDeploymentDataSet(deployment *Deployment) (DeploymentDataSet, error) {
+	dataSet, err := s.DeploymentStore.GetDeploymentDataSet(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, data := range dataSet {
+		if err := s.decrypt(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return dataSet, nil
+}
+
+// withoutDecryption returns the store wrapped by a store returned by NewEncryptedDeploymentStore, so that encrypted
+// entries are read as they are stored. Other stores are returned unchanged.
+func withoutDecryption(tx DeploymentStore) DeploymentStore {
+	if s, ok := tx.(*encryptedDeploymentStore); ok {
+		return s.DeploymentStore
+	}
+
+	return tx
+}
+
+// ReencryptDeploymentData re-encrypts the data of a deployment stored in a store returned by
+// NewEncryptedDeploymentStore, and returns the number of entries updated.
+//
+// Entries encrypted with a key other than the primary key are re-encrypted with the primary key. Entries that are
+// not encrypted but are selected by the store filters are encrypted. This is used to rotate encryption keys, and to
+// encrypt existing data after opting in to encrypt new data types.
+func ReencryptDeploymentData(tx DeploymentStore, deployment *Deployment) (int, error) {
+	s, ok := tx.(*encryptedDeploymentStore)
+	if !ok {
+		return 0, ErrDeploymentStoreNotEncrypted
+	}
+
+	dataSet, err := s.DeploymentStore.GetDeploymentDataSet(deployment)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, data := range dataSet {
+		if data.Data == nil {
+			continue
+		}
+
+		encrypted := isEncrypted(*data.Data)
+		if encrypted && s.encryptor.IsPrimary(*data.Data) {
+			continue
+		}
+		if !encrypted && !s.shouldEncrypt(data) {
+			continue
+		}
+
+		if err := s.decrypt(data); err != nil {
+			return updated, err
+		}
+		reencrypted, err := s.encrypt(data)
+		if err != nil {
+			return updated, err
+		}
+		if err := s.DeploymentStore.SetDeploymentData(reencrypted); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,324 @@
+package actions
+
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+	"sync"
+	"time"
+)
+
+// gormDeploymentStore is a DeploymentStore implementation that persists deployments in a relational database using
+// gorm. The database tables can be created with MigrateDB.
+type gormDeploymentStore struct {
+	// db contains the database connection or transaction used to persist deployments.
+	db *gorm.DB
+	// lock serializes access to db. A database transaction uses a single connection, which does not support running
+	// statements concurrently (e.g. from jobs in a parallel job group, or from a lease heartbeat).
+	lock sync.Mutex
+}
+
+// NewGormDeploymentStore returns a DeploymentStore that persists deployments using the given gorm database connection
+// or transaction. Calls to the store are serialized, so it can be used concurrently even if `db` is a transaction.
+func NewGormDeploymentStore(db *gorm.DB) DeploymentStore {
+	return &gormDeploymentStore{
+		db: db,
+	}
+}
+
+// CreateDeployment creates a new deployment entry.
+func (s *gormDeploymentStore) CreateDeployment(deployment *Deployment) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, err := s.findDeployment(deployment.UUID)
+	if err == nil {
+		return ErrDeploymentExists
+	}
+	if !errors.Is(err, ErrDeploymentNotFound) {
+		return err
+	}
+
+	return s.db.Model(&Deployment{}).Create(deployment).Error
+}
+
+// UpdateDeployment updates an existing deployment entry with the current values of the deployment.
+func (s *gormDeploymentStore) UpdateDeployment(deployment *Deployment) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Model(deployment).Omit("stop_requested", "lease_owner", "lease_expires_at").Save(deployment).Error
+}
+
+// GetDeployment returns the deployment with the given UUID.
+func (s *gormDeploymentStore) GetDeployment(uuid string) (*Deployment, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.findDeployment(uuid)
+}
+
+// findDeployment returns the deployment with the given UUID.
+// The caller must hold the lock.
+func (s *gormDeploymentStore) findDeployment(uuid string) (*Deployment, error) {
+	deployment := &Deployment{}
+
+	err := s.db.
+		Where("UUID = ?", uuid).
+		First(deployment).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrDeploymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+// GetDeploymentsByStatus returns all the deployments with the given status.
+func (s *gormDeploymentStore) GetDeploymentsByStatus(status DeploymentStatus) (Deployments, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var deployments Deployments
+
+	err := s.db.
+		Where("status = ?", status).
+		Find(&deployments).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
+
+// ListDeployments returns the deployments that match a filter, and the total number of deployments that match the
+// filter.
+func (s *gormDeploymentStore) ListDeployments(filter DeploymentFilter) (Deployments, int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	query := s.db.Model(&Deployment{})
+
+	if filter.Action != nil {
+		query = query.Where("action = ?", *filter.Action)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+
+	// Get the total number of deployments before paginating
+	var total int
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Paginate
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	order := "id desc"
+	if filter.OldestFirst {
+		order = "id asc"
+	}
+
+	var deployments Deployments
+	if err := query.Order(order).Find(&deployments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return deployments, total, nil
+}
+
+// RequestDeploymentStop sets the StopRequested flag of the deployment with the given UUID.
+func (s *gormDeploymentStore) RequestDeploymentStop(uuid string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := s.findDeployment(uuid); err != nil {
+		return err
+	}
+
+	return s.db.
+		Model(&Deployment{}).
+		Where("uuid = ?", uuid).
+		Update("stop_requested", true).
+		Error
+}
+
+// AcquireDeploymentLease leases the deployment with the given UUID to `owner` until `ttl` elapses.
+// The lease is acquired with a single conditional update, which prevents two owners from acquiring the same lease.
+func (s *gormDeploymentStore) AcquireDeploymentLease(uuid string, owner string, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+
+	result := s.db.
+		Model(&Deployment{}).
+		Where("uuid = ?", uuid).
+		Where("lease_owner IS NULL OR lease_owner = ? OR lease_expires_at IS NULL OR lease_expires_at < ?", owner, now).
+		UpdateColumns(map[string]interface{}{
+			"lease_owner":      owner,
+			"lease_expires_at": now.Add(ttl),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// No rows are affected if the deployment does not exist, if it is leased by another owner, or if the lease was
+	// renewed with the same values
+	deployment, err := s.findDeployment(uuid)
+	if err != nil {
+		return err
+	}
+	if deployment.LeaseOwner == nil || *deployment.LeaseOwner != owner {
+		return ErrDeploymentLeased
+	}
+
+	return nil
+}
+
+// ReleaseDeploymentLease releases the lease of the deployment with the given UUID if it is held by `owner`.
+func (s *gormDeploymentStore) ReleaseDeploymentLease(uuid string, owner string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := s.findDeployment(uuid); err != nil {
+		return err
+	}
+
+	return s.db.
+		Model(&Deployment{}).
+		Where("uuid = ? AND lease_owner = ?", uuid, owner).
+		UpdateColumns(map[string]interface{}{
+			"lease_owner":      gorm.Expr("NULL"),
+			"lease_expires_at": gorm.Expr("NULL"),
+		}).
+		Error
+}
+
+// DeleteDeployment permanently deletes a deployment, including its job data and errors.
+func (s *gormDeploymentStore) DeleteDeployment(uuid string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	deployment, err := s.findDeployment(uuid)
+	if err != nil {
+		return err
+	}
+
+	// The deployment entry is deleted last. If deleting its data fails, the deployment can be deleted again to remove
+	// the rest of its entries.
+	if err := s.db.Unscoped().Where("deployment_id = ?", deployment.ID).Delete(&DeploymentData{}).Error; err != nil {
+		return err
+	}
+	if err := s.db.Unscoped().Where("deployment_id = ?", deployment.ID).Delete(&DeploymentError{}).Error; err != nil {
+		return err
+	}
+
+	return s.db.Unscoped().Delete(deployment).Error
+}
+
+// SetDeploymentData creates a deployment job data entry, or replaces it if it already exists.
+func (s *gormDeploymentStore) SetDeploymentData(data *DeploymentData) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.
+		Where("deployment_id = ?", data.DeploymentID).
+		Where("job = ?", data.Job).
+		Where("type = ?", data.Type).
+		Assign(*data).
+		FirstOrCreate(data).
+		Error
+}
+
+// GetDeploymentData returns the data entry of a specific type for a deployment job.
+func (s *gormDeploymentStore) GetDeploymentData(deployment *Deployment, job string,
+	dataType DeploymentDataType) (*DeploymentData, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	data := &DeploymentData{}
+	err := s.db.
+		Where("deployment_id = ?", deployment.ID).
+		Where("job = ?", job).
+		Where("type = ?", dataType).
+		First(data).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrDeploymentDataNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetDeploymentDataSet returns all the data entries of a deployment in the order they were created.
+func (s *gormDeploymentStore) GetDeploymentDataSet(deployment *Deployment) (DeploymentDataSet, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var dataSet DeploymentDataSet
+
+	err := s.db.
+		Where("deployment_id = ?", deployment.ID).
+		Order("id").
+		Find(&dataSet).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return dataSet, nil
+}
+
+// CreateDeploymentError creates a deployment error entry.
+func (s *gormDeploymentStore) CreateDeploymentError(deploymentError *DeploymentError) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Create(deploymentError).Error
+}
+
+// GetDeploymentErrors returns the errors of a deployment.
+// If `job` is not nil, only the errors for that job are returned.
+func (s *gormDeploymentStore) GetDeploymentErrors(deployment *Deployment, job *string) (DeploymentErrors, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var deploymentErrs DeploymentErrors
+
+	query := s.db.Where("deployment_id = ?", deployment.ID)
+
+	// Optionally filter by job
+	if job != nil {
+		query = query.Where("job = ?", *job)
+	}
+
+	err := query.Order("id").Find(&deploymentErrs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return deploymentErrs, nil
+}
@@ -0,0 +1,200 @@
+package actions
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestGormDeploymentStoreCreateDeploymentExists(t *testing.T) {
+	tr := setupTest(t)
+	defer tr.db.Close()
+
+	first := &Deployment{UUID: "test", Action: "action", Status: deploymentStatusFinished}
+	require.NoError(t, tr.tx.CreateDeployment(first))
+
+	// Deployments with the same UUID cannot be created twice
+	duplicate := &Deployment{UUID: "test", Action: "action", Status: deploymentStatusRunning}
+	require.True(t, errors.Is(tr.tx.CreateDeployment(duplicate), ErrDeploymentExists))
+
+	var count int
+	require.NoError(t, tr.db.Model(&Deployment{}).Where("uuid = ?", "test").Count(&count).Error)
+	assert.Equal(t, 1, count)
+}
+
+func TestGormDeploymentStoreLease(t *testing.T) {
+	tr := setupTest(t)
+	defer tr.db.Close()
+
+	tx := tr.tx
+	require.NoError(t, tx.CreateDeployment(&Deployment{UUID: "test", Action: "action"}))
+
+	assert.True(t, errors.Is(tx.AcquireDeploymentLease("missing", "a", time.Minute), ErrDeploymentNotFound))
+	assert.True(t, errors.Is(tx.ReleaseDeploymentLease("missing", "a"), ErrDeploymentNotFound))
+
+	// Leases are exclusive
+	require.NoError(t, tx.AcquireDeploymentLease("test", "a", time.Minute))
+	assert.True(t, errors.Is(tx.AcquireDeploymentLease("test", "b", time.Minute), ErrDeploymentLeased))
+
+	// Owners can renew their leases, even if the renewal does not change the stored values
+	require.NoError(t, tx.AcquireDeploymentLease("test", "a", time.Minute))
+	require.NoError(t, tx.AcquireDeploymentLease("test", "a", time.Minute))
+
+	// Expired leases can be taken over
+	require.NoError(t, tx.AcquireDeploymentLease("test", "a", -time.Hour))
+	require.NoError(t, tx.AcquireDeploymentLease("test", "b", time.Minute))
+
+	// Updating a deployment does not modify its lease
+	deployment, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	deployment.LeaseOwner = nil
+	deployment.LeaseExpiresAt = nil
+	require.NoError(t, tx.UpdateDeployment(deployment))
+
+	// Leases can only be released by their owner
+	require.NoError(t, tx.ReleaseDeploymentLease("test", "a"))
+	deployment, err = tx.GetDeployment("test")
+	require.NoError(t, err)
+	require.NotNil(t, deployment.LeaseOwner)
+	assert.Equal(t, "b", *deployment.LeaseOwner)
+
+	require.NoError(t, tx.ReleaseDeploymentLease("test", "b"))
+	deployment, err = tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.Nil(t, deployment.LeaseOwner)
+	assert.Nil(t, deployment.LeaseExpiresAt)
+
+	// Deployments can be created leased
+	owner := "a"
+	expiresAt := time.Now().Add(time.Minute)
+	leased := &Deployment{UUID: "leased", Action: "action", LeaseOwner: &owner, LeaseExpiresAt: &expiresAt}
+	require.NoError(t, tx.CreateDeployment(leased))
+	assert.True(t, errors.Is(tx.AcquireDeploymentLease("leased", "b", time.Minute), ErrDeploymentLeased))
+	require.NoError(t, tx.AcquireDeploymentLease("leased", "a", time.Minute))
+}
+
+func TestGormDeploymentStoreRequestDeploymentStop(t *testing.T) {
+	tr := setupTest(t)
+	defer tr.db.Close()
+
+	tx := tr.tx
+	assert.True(t, errors.Is(tx.RequestDeploymentStop("missing"), ErrDeploymentNotFound))
+
+	deployment := &Deployment{UUID: "test", Action: "action", Status: deploymentStatusRunning}
+	require.NoError(t, tx.CreateDeployment(deployment))
+	require.NoError(t, tx.RequestDeploymentStop("test"))
+
+	stored, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.True(t, stored.StopRequested)
+
+	// Updating a deployment does not clear its stop request
+	deployment.CurrentJob = "job"
+	require.NoError(t, tx.UpdateDeployment(deployment))
+	stored, err = tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.True(t, stored.StopRequested)
+	assert.Equal(t, "job", stored.CurrentJob)
+}
+
+func TestGormDeploymentStoreListDeployments(t *testing.T) {
+	tr := setupTest(t)
+	defer tr.db.Close()
+
+	tx := tr.tx
+	for _, deployment := range []*Deployment{
+		{UUID: "a", Action: "start", Status: deploymentStatusFinished},
+		{UUID: "b", Action: "stop", Status: deploymentStatusFinished},
+		{UUID: "c", Action: "start", Status: deploymentStatusRunning},
+		{UUID: "d", Action: "start", Status: deploymentStatusFinished},
+	} {
+		require.NoError(t, tx.CreateDeployment(deployment))
+	}
+
+	getUUIDs := func(deployments Deployments) []string {
+		uuids := make([]string, len(deployments))
+		for i, deployment := range deployments {
+			uuids[i] = deployment.UUID
+		}
+		return uuids
+	}
+
+	// No filter returns every deployment from newest to oldest
+	deployments, total, err := tx.ListDeployments(DeploymentFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "c", "b", "a"}, getUUIDs(deployments))
+	assert.Equal(t, 4, total)
+
+	// Filter by action and status
+	action := "start"
+	status := deploymentStatusFinished
+	deployments, total, err = tx.ListDeployments(DeploymentFilter{Action: &action, Status: &status})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "a"}, getUUIDs(deployments))
+	assert.Equal(t, 2, total)
+
+	// Paginate
+	deployments, total, err = tx.ListDeployments(DeploymentFilter{Action: &action, Offset: 1, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, getUUIDs(deployments))
+	assert.Equal(t, 3, total)
+
+	deployments, total, err = tx.ListDeployments(DeploymentFilter{Action: &action, Offset: 1, Limit: 2, OldestFirst: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, getUUIDs(deployments))
+	assert.Equal(t, 3, total)
+
+	// Filter by time range
+	future := time.Now().Add(time.Hour)
+	deployments, total, err = tx.ListDeployments(DeploymentFilter{CreatedAfter: &future})
+	require.NoError(t, err)
+	assert.Empty(t, deployments)
+	assert.Equal(t, 0, total)
+
+	deployments, _, err = tx.ListDeployments(DeploymentFilter{CreatedBefore: &future})
+	require.NoError(t, err)
+	assert.Len(t, deployments, 4)
+}
+
+func TestGormDeploymentStoreDeleteDeployment(t *testing.T) {
+	tr := setupTest(t)
+	defer tr.db.Close()
+
+	tx := tr.tx
+	assert.True(t, errors.Is(tx.DeleteDeployment("missing"), ErrDeploymentNotFound))
+
+	job := "job"
+	var deployments Deployments
+	for _, uuid := range []string{"deleted", "kept"} {
+		deployment := &Deployment{UUID: uuid, Action: "action", CurrentJob: job}
+		require.NoError(t, tx.CreateDeployment(deployment))
+		require.NoError(t, deployment.SetJobData(tx, nil, DeploymentJobData, "data"))
+		require.NoError(t, deployment.addJobError(tx, &job, assert.AnError))
+		deployments = append(deployments, deployment)
+	}
+
+	require.NoError(t, tx.DeleteDeployment("deleted"))
+
+	// The deployment and its entries are permanently deleted
+	_, err := tx.GetDeployment("deleted")
+	assert.True(t, errors.Is(err, ErrDeploymentNotFound))
+
+	countRows := func(model interface{}, deployment *Deployment) int {
+		var count int
+		require.NoError(t, tr.db.Unscoped().Model(model).Where("deployment_id = ?", deployment.ID).Count(&count).Error)
+		return count
+	}
+	var count int
+	require.NoError(t, tr.db.Unscoped().Model(&Deployment{}).Where("uuid = ?", "deleted").Count(&count).Error)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0, countRows(&DeploymentData{}, deployments[0]))
+	assert.Equal(t, 0, countRows(&DeploymentError{}, deployments[0]))
+
+	// Other deployments are not modified
+	_, err = tx.GetDeployment("kept")
+	require.NoError(t, err)
+	assert.Equal(t, 1, countRows(&DeploymentData{}, deployments[1]))
+	assert.Equal(t, 1, countRows(&DeploymentError{}, deployments[1]))
+}
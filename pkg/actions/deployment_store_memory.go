@@ -0,0 +1,358 @@
+package actions
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryDeploymentStore is a DeploymentStore implementation that keeps deployments in memory.
+// It is intended for unit tests and applications that do not need deployments to survive a restart.
+type memoryDeploymentStore struct {
+	// lock is used to allow concurrent access to the store.
+	lock sync.RWMutex
+	// lastID contains the last ID assigned to an entry.
+	lastID uint
+	// deployments contains the stored deployments, indexed by UUID.
+	deployments map[string]*Deployment
+	// data contains the stored deployment job data entries, in creation order.
+	data []*DeploymentData
+	// errors contains the stored deployment errors, in creation order.
+	errors DeploymentErrors
+}
+
+// NewMemoryDeploymentStore returns a DeploymentStore that keeps deployments in memory.
+func NewMemoryDeploymentStore() DeploymentStore {
+	return &memoryDeploymentStore{
+		deployments: make(map[string]*Deployment),
+	}
+}
+
+// nextID returns a new ID for an entry.
+// The caller must hold the write lock.
+func (s *memoryDeploymentStore) nextID() uint {
+	s.lastID++
+	return s.lastID
+}
+
+// CreateDeployment creates a new deployment entry.
+func (s *memoryDeploymentStore) CreateDeployment(deployment *Deployment) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.deployments[deployment.UUID]; ok {
+		return ErrDeploymentExists
+	}
+
+	now := time.Now()
+	deployment.ID = s.nextID()
+	deployment.CreatedAt = now
+	deployment.UpdatedAt = now
+
+	entry := *deployment
+	s.deployments[deployment.UUID] = &entry
+
+	return nil
+}
+
+// UpdateDeployment updates an existing deployment entry with the current values of the deployment.
+func (s *memoryDeploymentStore) UpdateDeployment(deployment *Deployment) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stored, ok := s.deployments[deployment.UUID]
+	if !ok {
+		return ErrDeploymentNotFound
+	}
+
+	deployment.UpdatedAt = time.Now()
+
+	entry := *deployment
+	entry.StopRequested = stored.StopRequested
+	entry.LeaseOwner = stored.LeaseOwner
+	entry.LeaseExpiresAt = stored.LeaseExpiresAt
+	s.deployments[deployment.UUID] = &entry
+
+	return nil
+}
+
+// GetDeployment returns the deployment with the given UUID.
+func (s *memoryDeploymentStore) GetDeployment(uuid string) (*Deployment, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	deployment, ok := s.deployments[uuid]
+	if !ok {
+		return nil, ErrDeploymentNotFound
+	}
+
+	out := *deployment
+	return &out, nil
+}
+
+// GetDeploymentsByStatus returns all the deployments with the given status.
+func (s *memoryDeploymentStore) GetDeploymentsByStatus(status DeploymentStatus) (Deployments, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var deployments Deployments
+	for _, deployment := range s.deployments {
+		if deployment.Status == status {
+			out := *deployment
+			deployments = append(deployments, &out)
+		}
+	}
+
+	// Return deployments in creation order
+	sort.Slice(deployments, func(i, j int) bool {
+		return deployments[i].ID < deployments[j].ID
+	})
+
+	return deployments, nil
+}
+
+// ListDeployments returns the deployments that match a filter, and the total number of deployments that match the
+// filter.
+func (s *memoryDeploymentStore) ListDeployments(filter DeploymentFilter) (Deployments, int, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var deployments Deployments
+	for _, deployment := range s.deployments {
+		if filter.matches(deployment) {
+			out := *deployment
+			deployments = append(deployments, &out)
+		}
+	}
+
+	// Return deployments from newest to oldest, or from oldest to newest if requested
+	sort.Slice(deployments, func(i, j int) bool {
+		if filter.OldestFirst {
+			return deployments[i].ID < deployments[j].ID
+		}
+		return deployments[i].ID > deployments[j].ID
+	})
+
+	total := len(deployments)
+
+	// Paginate
+	if filter.Offset >= len(deployments) {
+		return Deployments{}, total, nil
+	}
+	deployments = deployments[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(deployments) {
+		deployments = deployments[:filter.Limit]
+	}
+
+	return deployments, total, nil
+}
+
+// RequestDeploymentStop sets the StopRequested flag of the deployment with the given UUID.
+func (s *memoryDeploymentStore) RequestDeploymentStop(uuid string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	deployment, ok := s.deployments[uuid]
+	if !ok {
+		return ErrDeploymentNotFound
+	}
+
+	deployment.StopRequested = true
+
+	return nil
+}
+
+// AcquireDeploymentLease leases the deployment with the given UUID to `owner` until `ttl` elapses.
+func (s *memoryDeploymentStore) AcquireDeploymentLease(uuid string, owner string, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	deployment, ok := s.deployments[uuid]
+	if !ok {
+		return ErrDeploymentNotFound
+	}
+
+	now := time.Now()
+	if deployment.LeaseOwner != nil && *deployment.LeaseOwner != owner &&
+		deployment.LeaseExpiresAt != nil && deployment.LeaseExpiresAt.After(now) {
+		return ErrDeploymentLeased
+	}
+
+	expiresAt := now.Add(ttl)
+	deployment.LeaseOwner = &owner
+	deployment.LeaseExpiresAt = &expiresAt
+
+	return nil
+}
+
+// ReleaseDeploymentLease releases the lease of the deployment with the given UUID if it is held by `owner`.
+func (s *memoryDeploymentStore) ReleaseDeploymentLease(uuid string, owner string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	deployment, ok := s.deployments[uuid]
+	if !ok {
+		return ErrDeploymentNotFound
+	}
+
+	if deployment.LeaseOwner != nil && *deployment.LeaseOwner == owner {
+		deployment.LeaseOwner = nil
+		deployment.LeaseExpiresAt = nil
+	}
+
+	return nil
+}
+
+// DeleteDeployment permanently deletes a deployment, including its job data and errors.
+func (s *memoryDeploymentStore) DeleteDeployment(uuid string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	deployment, ok := s.deployments[uuid]
+	if !ok {
+		return ErrDeploymentNotFound
+	}
+	id := int(deployment.ID)
+
+	data := s.data[:0]
+	for _, stored := range s.data {
+		if stored.DeploymentID != id {
+			data = append(data, stored)
+		}
+	}
+	s.data = data
+
+	errs := s.errors[:0]
+	for _, stored := range s.errors {
+		if stored.DeploymentID != id {
+			errs = append(errs, stored)
+		}
+	}
+	s.errors = errs
+
+	delete(s.deployments, uuid)
+
+	return nil
+}
+
+// SetDeploymentData creates a deployment job data entry, or replaces it if it already exists.
+func (s *memoryDeploymentStore) SetDeploymentData(data *DeploymentData) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	entry := *data
+	entry.Deployment = nil
+
+	// Replace the entry if it exists
+	for i, stored := range s.data {
+		if stored.DeploymentID == data.DeploymentID && stored.Job == data.Job && stored.Type == data.Type {
+			entry.ID = stored.ID
+			entry.CreatedAt = stored.CreatedAt
+			entry.UpdatedAt = now
+			s.data[i] = &entry
+			data.Model = entry.Model
+
+			return nil
+		}
+	}
+
+	entry.ID = s.nextID()
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	s.data = append(s.data, &entry)
+	data.Model = entry.Model
+
+	return nil
+}
+
+// GetDeploymentData returns the data entry of a specific type for a deployment job.
+func (s *memoryDeploymentStore) GetDeploymentData(deployment *Deployment, job string,
+	dataType DeploymentDataType) (*DeploymentData, error) {
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for _, stored := range s.data {
+		if stored.DeploymentID == int(deployment.ID) && stored.Job == job && stored.Type == dataType {
+			out := *stored
+			return &out, nil
+		}
+	}
+
+	return nil, ErrDeploymentDataNotFound
+}
+
+// GetDeploymentDataSet returns all the data entries of a deployment in the order they were created.
+func (s *memoryDeploymentStore) GetDeploymentDataSet(deployment *Deployment) (DeploymentDataSet, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var dataSet DeploymentDataSet
+	for _, stored := range s.data {
+		if stored.DeploymentID == int(deployment.ID) {
+			out := *stored
+			dataSet = append(dataSet, &out)
+		}
+	}
+
+	// Replaced entries keep their position in the slice, sort them by ID to return them in creation order
+	sort.Slice(dataSet, func(i, j int) bool {
+		return dataSet[i].ID < dataSet[j].ID
+	})
+
+	return dataSet, nil
+}
+
+// CreateDeploymentError creates a deployment error entry.
+func (s *memoryDeploymentStore) CreateDeploymentError(deploymentError *DeploymentError) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	deploymentError.ID = s.nextID()
+	deploymentError.CreatedAt = now
+	deploymentError.UpdatedAt = now
+
+	entry := *deploymentError
+	entry.Deployment = nil
+
+	// Copy pointer fields, as they may point to values that change after the entry is created (e.g. the current job
+	// of a deployment)
+	if entry.Job != nil {
+		job := *entry.Job
+		entry.Job = &job
+	}
+	if entry.Error != nil {
+		msg := *entry.Error
+		entry.Error = &msg
+	}
+
+	s.errors = append(s.errors, &entry)
+
+	return nil
+}
+
+// GetDeploymentErrors returns the errors of a deployment.
+// If `job` is not nil, only the errors for that job are returned.
+func (s *memoryDeploymentStore) GetDeploymentErrors(deployment *Deployment, job *string) (DeploymentErrors, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var deploymentErrs DeploymentErrors
+	for _, stored := range s.errors {
+		if stored.DeploymentID != int(deployment.ID) {
+			continue
+		}
+
+		// Optionally filter by job
+		if job != nil && (stored.Job == nil || *stored.Job != *job) {
+			continue
+		}
+
+		out := *stored
+		deploymentErrs = append(deploymentErrs, &out)
+	}
+
+	return deploymentErrs, nil
+}
@@ -0,0 +1,188 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMemoryDeploymentStoreDeployments(t *testing.T) {
+	store := NewMemoryDeploymentStore()
+
+	// Get a deployment that does not exist
+	_, err := store.GetDeployment("missing")
+	require.True(t, errors.Is(err, ErrDeploymentNotFound))
+
+	// Updating a deployment that does not exist fails
+	require.True(t, errors.Is(store.UpdateDeployment(&Deployment{UUID: "missing"}), ErrDeploymentNotFound))
+
+	// Create deployments
+	first := &Deployment{UUID: "first", Status: deploymentStatusRunning}
+	second := &Deployment{UUID: "second", Status: deploymentStatusRunning}
+	third := &Deployment{UUID: "third", Status: deploymentStatusFinished}
+	for _, deployment := range (Deployments{first, second, third}) {
+		require.NoError(t, store.CreateDeployment(deployment))
+		require.NotZero(t, deployment.ID)
+	}
+
+	// Deployments with the same UUID cannot be created twice
+	duplicate := &Deployment{UUID: "first", Status: deploymentStatusFinished}
+	require.True(t, errors.Is(store.CreateDeployment(duplicate), ErrDeploymentExists))
+	stored, err := store.GetDeployment("first")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, stored.ID)
+	assert.Equal(t, deploymentStatusRunning, stored.Status)
+
+	// Stored deployments are copies
+	first.CurrentJob = "modified"
+	stored, err = store.GetDeployment("first")
+	require.NoError(t, err)
+	assert.Equal(t, "", stored.CurrentJob)
+
+	// Update a deployment
+	require.NoError(t, store.UpdateDeployment(first))
+	stored, err = store.GetDeployment("first")
+	require.NoError(t, err)
+	assert.Equal(t, "modified", stored.CurrentJob)
+
+	// Get deployments by status
+	running, err := store.GetDeploymentsByStatus(deploymentStatusRunning)
+	require.NoError(t, err)
+	require.Len(t, running, 2)
+	assert.Equal(t, "first", running[0].UUID)
+	assert.Equal(t, "second", running[1].UUID)
+}
+
+func TestMemoryDeploymentStoreData(t *testing.T) {
+	store := NewMemoryDeploymentStore()
+
+	deployment := &Deployment{UUID: "test"}
+	require.NoError(t, store.CreateDeployment(deployment))
+
+	// Get data that does not exist
+	_, err := store.GetDeploymentData(deployment, "job", DeploymentJobInput)
+	require.True(t, errors.Is(err, ErrDeploymentDataNotFound))
+
+	// Create and replace data
+	require.NoError(t, setDeploymentData(store, deployment, nil, DeploymentJobInput, 1))
+	require.NoError(t, setDeploymentData(store, deployment, nil, DeploymentJobData, 2))
+	require.NoError(t, setDeploymentData(store, deployment, nil, DeploymentJobData, 3))
+
+	var out int
+	require.NoError(t, deployment.GetJobDataOutValue(store, nil, DeploymentJobInput, &out))
+	assert.Equal(t, 1, out)
+	require.NoError(t, deployment.GetJobDataOutValue(store, nil, DeploymentJobData, &out))
+	assert.Equal(t, 3, out)
+
+	// Data is stored per deployment
+	other := &Deployment{UUID: "other"}
+	require.NoError(t, store.CreateDeployment(other))
+	_, err = getDeploymentData(store, other, nil, DeploymentJobInput)
+	require.True(t, errors.Is(err, ErrDeploymentDataNotFound))
+}
+
+func TestMemoryDeploymentStoreErrors(t *testing.T) {
+	store := NewMemoryDeploymentStore()
+
+	deployment := &Deployment{UUID: "test", CurrentJob: "job_1"}
+	require.NoError(t, store.CreateDeployment(deployment))
+
+	require.NoError(t, deployment.addJobError(store, nil, errors.New("1A")))
+	deployment.CurrentJob = "job_2"
+	require.NoError(t, deployment.addJobError(store, nil, errors.New("2A")))
+	require.NoError(t, deployment.addJobError(store, nil, errors.New("2B")))
+
+	errs, err := deployment.GetErrors(store, nil)
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+
+	job := "job_1"
+	errs, err = deployment.GetErrors(store, &job)
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "1A", *errs[0].Error)
+
+	job = "job_2"
+	errs, err = deployment.GetErrors(store, &job)
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "2A", *errs[0].Error)
+	assert.Equal(t, "2B", *errs[1].Error)
+}
+
+func TestExecuteWithMemoryDeploymentStore(t *testing.T) {
+	td := getTestData(t)
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var rollbackCalls []string
+	createJob := func(name string, jobErr error) *Job {
+		return &Job{
+			Name: name,
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
+				if err := deployment.SetJobData(tx, nil, DeploymentJobData, name); err != nil {
+					return nil, err
+				}
+				if jobErr != nil {
+					return nil, jobErr
+				}
+				return value, nil
+			},
+			RollbackHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{},
+				err error) (interface{}, error) {
+
+				var data string
+				if err := deployment.GetJobDataOutValue(tx, nil, DeploymentJobData, &data); err != nil {
+					return nil, err
+				}
+				rollbackCalls = append(rollbackCalls, data)
+
+				return nil, nil
+			},
+			InputType:  GetJobDataType(&JobGroupTestStruct{}),
+			OutputType: GetJobDataType(&JobGroupTestStruct{}),
+		}
+	}
+
+	// Successful execution
+	action, err := NewAction(Jobs{createJob("job_1", nil), createJob("job_2", nil)})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+		GroupID:    "success",
+	}
+	require.NoError(t, service.Execute(context.Background(), nil, tx, executeInput, &JobGroupTestStruct{Value: 1}))
+
+	deployment, err := tx.GetDeployment("success")
+	require.NoError(t, err)
+	assert.True(t, deployment.isFinished())
+	assert.Empty(t, rollbackCalls)
+
+	// Failed execution
+	failAction, err := NewAction(Jobs{createJob("job_1", nil), createJob("job_2", assert.AnError)})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, "fail", failAction))
+
+	executeInput = &ExecuteInput{
+		ActionName: "fail",
+		GroupID:    "fail",
+	}
+	require.Error(t, service.Execute(context.Background(), nil, tx, executeInput, &JobGroupTestStruct{Value: 1}))
+
+	deployment, err = tx.GetDeployment("fail")
+	require.NoError(t, err)
+	assert.True(t, deployment.isFinished())
+	assert.Equal(t, assert.AnError.Error(), *deployment.RollbackError)
+	assert.Equal(t, []string{"job_2", "job_1"}, rollbackCalls)
+
+	errs, err := deployment.GetErrors(tx, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, errs)
+}
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"errors"
 	"github.com/jinzhu/gorm"
 	"github.com/satori/go.uuid"
@@ -26,7 +27,7 @@ var deploymentTestData = struct {
 		Jobs: Jobs{
 			{
 				Name: "job_1",
-				Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+				Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 					return value, nil
 				},
 				InputType:  NilJobDataType,
@@ -34,7 +35,7 @@ var deploymentTestData = struct {
 			},
 			{
 				Name: "job_2",
-				Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+				Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 					return value, nil
 				},
 				InputType:  NilJobDataType,
@@ -42,7 +43,7 @@ var deploymentTestData = struct {
 			},
 			{
 				Name: "job_3",
-				Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+				Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 					return value, nil
 				},
 				InputType:  NilJobDataType,
@@ -54,7 +55,7 @@ var deploymentTestData = struct {
 	// Helper functions
 	getDeploymentJobDataCount: func(t *testing.T, db *gorm.DB) int {
 		var jobDataCount int
-		require.NoError(t, db.Model(&deploymentData{}).Count(&jobDataCount).Error)
+		require.NoError(t, db.Model(&DeploymentData{}).Count(&jobDataCount).Error)
 
 		return jobDataCount
 	},
@@ -65,13 +66,13 @@ func TestNewDeploymentAndGetDeployment(t *testing.T) {
 	defer tr.db.Close()
 
 	// New Deployment
-	deployment, err := newDeployment(tr.db, deploymentTestData.action, uuid.NewV4().String())
+	deployment, err := newDeployment(tr.tx, deploymentTestData.action, uuid.NewV4().String(), nil)
 	require.NoError(t, err)
 	require.NotNil(t, deployment)
 	require.NotNil(t, deployment.UUID)
 
 	// Get Deployment
-	dbDeployment, err := getDeployment(tr.db, &deployment.UUID)
+	dbDeployment, err := getDeployment(tr.tx, &deployment.UUID)
 	assert.NoError(t, err)
 	assert.NotNil(t, dbDeployment)
 	// Make CreatedAt and UpdatedAt fields equal to compare other fields
@@ -100,7 +101,7 @@ func TestGetRunningDeployments(t *testing.T) {
 	assert.NoError(t, createTestDeployment(tr.db, deploymentStatusFinished))
 	assert.NoError(t, createTestDeployment(tr.db, deploymentStatusRollback))
 
-	deployments, err := GetRunningDeployments(tr.db)
+	deployments, err := GetRunningDeployments(tr.tx)
 	assert.NoError(t, err)
 	assert.Len(t, deployments, 3)
 	// Check that all the deployments are not finished
@@ -119,7 +120,7 @@ func TestSetJob(t *testing.T) {
 	// Reset the job data type registry
 	jobDataTypeRegistry = newDataTypeRegistry()
 
-	deployment, err := newDeployment(tr.db, td.action, uuid.NewV4().String())
+	deployment, err := newDeployment(tr.tx, td.action, uuid.NewV4().String(), nil)
 	require.NoError(t, err)
 	require.Equal(t, td.jobName1, deployment.CurrentJob)
 
@@ -134,14 +135,14 @@ func TestSetJob(t *testing.T) {
 	jobDataTypeRegistry.register(GetJobDataType(testInputData))
 
 	// Update the deployment's job
-	require.NoError(t, deployment.setJob(tr.db, td.jobName2, testInputData))
+	require.NoError(t, deployment.setJob(tr.tx, td.jobName2, testInputData))
 	require.Equal(t, td.jobName2, deployment.CurrentJob)
 
 	// There should be a deployment job data
 	require.Equal(t, 1, dtd.getDeploymentJobDataCount(t, tr.db))
 
 	// Check that the job data is of input type
-	out, err := deployment.GetJobData(tr.db, &td.jobName2, DeploymentJobInput)
+	out, err := deployment.GetJobData(tr.tx, &td.jobName2, DeploymentJobInput)
 	require.NoError(t, err)
 	require.Equal(t, testInputData.I, out.(*deploymentTestStruct).I)
 }
@@ -155,7 +156,7 @@ func TestSetAndGetJobData(t *testing.T) {
 	// Reset the job data type registry
 	jobDataTypeRegistry = newDataTypeRegistry()
 
-	deployment, err := newDeployment(tr.db, td.action, uuid.NewV4().String())
+	deployment, err := newDeployment(tr.tx, td.action, uuid.NewV4().String(), nil)
 	require.NoError(t, err)
 
 	// Prepare the job data
@@ -165,16 +166,16 @@ func TestSetAndGetJobData(t *testing.T) {
 	jobDataTypeRegistry.register(GetJobDataType(testJobData))
 
 	// Set job data
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName1, DeploymentJobInput, testInputData))
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName1, DeploymentJobData, testJobData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobInput, testInputData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobData, testJobData))
 
 	// Modify job data
 	testInputData = deploymentTestStruct{I: 100}
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName1, DeploymentJobInput, testInputData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobInput, testInputData))
 
 	// Get job data
-	compareTestData := func(job string, dataType deploymentDataType, expected deploymentTestStruct) {
-		out, err := deployment.GetJobData(tr.db, &job, dataType)
+	compareTestData := func(job string, dataType DeploymentDataType, expected deploymentTestStruct) {
+		out, err := deployment.GetJobData(tr.tx, &job, dataType)
 		require.NoError(t, err)
 		require.Equal(t, expected, out.(deploymentTestStruct))
 	}
@@ -188,7 +189,7 @@ func TestAddAndGetErrors(t *testing.T) {
 
 	td := getTestData(t)
 
-	deployment, err := newDeployment(tr.db, td.action, uuid.NewV4().String())
+	deployment, err := newDeployment(tr.tx, td.action, uuid.NewV4().String(), nil)
 	require.NoError(t, err)
 
 	// Add the errors to the deployment
@@ -197,12 +198,12 @@ func TestAddAndGetErrors(t *testing.T) {
 		errors.New("2"),
 		errors.New("3"),
 	}
-	require.NoError(t, deployment.addJobError(tr.db, &td.jobName1, testErrors[0]))
-	require.NoError(t, deployment.addJobError(tr.db, &td.jobName2, testErrors[1]))
-	require.NoError(t, deployment.addJobError(tr.db, &td.jobName3, testErrors[2]))
+	require.NoError(t, deployment.addJobError(tr.tx, &td.jobName1, testErrors[0]))
+	require.NoError(t, deployment.addJobError(tr.tx, &td.jobName2, testErrors[1]))
+	require.NoError(t, deployment.addJobError(tr.tx, &td.jobName3, testErrors[2]))
 
 	compareTestData := func(job *string, expected []error) {
-		dbErrors, err := deployment.GetErrors(tr.db, job)
+		dbErrors, err := deployment.GetErrors(tr.tx, job)
 		require.NoError(t, err)
 		require.Equal(t, len(expected), len(dbErrors))
 		for i := 0; i < len(expected); i++ {
@@ -222,29 +223,29 @@ func TestSetStatusAndIsStatusAndGetRollbackError(t *testing.T) {
 	td := getTestData(t)
 
 	// The default status should be Running
-	deployment, err := newDeployment(tr.db, td.action, uuid.NewV4().String())
+	deployment, err := newDeployment(tr.tx, td.action, uuid.NewV4().String(), nil)
 	require.NoError(t, err)
 	require.Equal(t, deploymentStatusRunning, deployment.Status)
 
-	dbDeployment, err := getDeployment(tr.db, &deployment.UUID)
+	dbDeployment, err := getDeployment(tr.tx, &deployment.UUID)
 	require.NoError(t, err)
 	require.True(t, dbDeployment.isRunning())
 
 	// Update the deployment status to Finished
-	require.NoError(t, deployment.setFinishedStatus(tr.db))
+	require.NoError(t, deployment.setFinishedStatus(tr.tx))
 	require.Equal(t, deploymentStatusFinished, deployment.Status)
 
-	dbDeployment, err = getDeployment(tr.db, &deployment.UUID)
+	dbDeployment, err = getDeployment(tr.tx, &deployment.UUID)
 	require.NoError(t, err)
 	require.True(t, dbDeployment.isFinished())
 
 	// Update the deployment status to Rollback
 	rollbackErr := errors.New("rollback")
-	require.NoError(t, deployment.setRollbackStatus(tr.db, rollbackErr))
+	require.NoError(t, deployment.setRollbackStatus(tr.tx, rollbackErr))
 	require.Equal(t, deploymentStatusRollback, deployment.Status)
 	require.Equal(t, rollbackErr.Error(), *deployment.RollbackError)
 
-	dbDeployment, err = getDeployment(tr.db, &deployment.UUID)
+	dbDeployment, err = getDeployment(tr.tx, &deployment.UUID)
 	require.NoError(t, err)
 	require.True(t, dbDeployment.isRollingBack())
 	require.Equal(t, rollbackErr, dbDeployment.getRollbackError())
@@ -0,0 +1,210 @@
+package actions
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/secrets"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// encryptedDataPrefix is the prefix of encrypted values.
+	// It is used to tell encrypted values apart from plain JSON values, which can never start with this prefix.
+	encryptedDataPrefix = "enc:"
+	// encryptionPrimaryKeySecret is the secret entry that contains the ID of the primary key.
+	encryptionPrimaryKeySecret = "primary"
+)
+
+var (
+	// ErrEncryptionNoKeys is returned when an encryptor is created without keys.
+	ErrEncryptionNoKeys = errors.New("no encryption keys provided")
+	// ErrEncryptionKeyNotFound is returned when a value was encrypted with a key the encryptor does not have.
+	ErrEncryptionKeyNotFound = errors.New("encryption key not found")
+	// ErrEncryptionInvalidKeys is returned when encryption keys cannot be parsed.
+	ErrEncryptionInvalidKeys = errors.New("invalid encryption keys")
+	// ErrEncryptionInvalidValue is returned when an encrypted value cannot be parsed.
+	ErrEncryptionInvalidValue = errors.New("invalid encrypted value")
+)
+
+// Encryptor encrypts and decrypts deployment data.
+type Encryptor interface {
+	// Encrypt encrypts a value with the primary key.
+	Encrypt(plaintext []byte) (string, error)
+	// Decrypt decrypts a value encrypted with any of the keys of the encryptor.
+	Decrypt(ciphertext string) ([]byte, error)
+	// IsPrimary checks if a value was encrypted with the primary key.
+	IsPrimary(ciphertext string) bool
+}
+
+// aesGCMEncryptor is an Encryptor implementation that uses AES-GCM.
+type aesGCMEncryptor struct {
+	// primary contains the ID of the key used to encrypt values.
+	primary string
+	// keys contains the ciphers of all the keys, indexed by key ID.
+	keys map[string]cipher.AEAD
+}
+
+// NewAESGCMEncryptor returns an Encryptor that uses AES-GCM.
+// `keys` contains the encryption keys indexed by key ID. Keys must be 16, 24 or 32 bytes long to select AES-128,
+// AES-192 or AES-256 respectively. Values are encrypted with the `primary` key, and can be decrypted with any key.
+//
+// Encrypted values contain the ID of the key used to encrypt them. Keys can be rotated by adding a new key, making it
+// the primary key, and keeping the old keys until all the values encrypted with them have been re-encrypted.
+func NewAESGCMEncryptor(primary string, keys map[string][]byte) (Encryptor, error) {
+	if len(keys) == 0 {
+		return nil, ErrEncryptionNoKeys
+	}
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key [%s]", ErrEncryptionKeyNotFound, primary)
+	}
+
+	e := &aesGCMEncryptor{
+		primary: primary,
+		keys:    make(map[string]cipher.AEAD, len(keys)),
+	}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("%w: invalid key id [%s]", ErrEncryptionInvalidKeys, id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key [%s]: %s", ErrEncryptionInvalidKeys, id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		e.keys[id] = aead
+	}
+
+	return e, nil
+}
+
+// NewAESGCMEncryptorFromSecret returns an AES-GCM Encryptor using keys stored in a secret.
+// Each entry of the secret contains a key, indexed by key ID. The `primary` entry contains the ID of the primary key.
+func NewAESGCMEncryptorFromSecret(ctx context.Context, secrets secrets.Secrets, name, namespace string) (Encryptor,
+	error) {
+
+	secret, err := secrets.Get(ctx, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	primary, ok := secret.Data[encryptionPrimaryKeySecret]
+	if !ok {
+		return nil, fmt.Errorf("%w: secret does not contain a [%s] entry", ErrEncryptionInvalidKeys,
+			encryptionPrimaryKeySecret)
+	}
+
+	keys := make(map[string][]byte, len(secret.Data))
+	for id, key := range secret.Data {
+		if id != encryptionPrimaryKeySecret {
+			keys[id] = key
+		}
+	}
+
+	return NewAESGCMEncryptor(string(primary), keys)
+}
+
+// NewAESGCMEncryptorFromEnv returns an AES-GCM Encryptor using keys stored in an environment variable.
+// The variable contains a comma-separated list of `<id>:<base64 key>` pairs. The first key is the primary key.
+func NewAESGCMEncryptorFromEnv(name string) (Encryptor, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, ErrEncryptionNoKeys
+	}
+
+	var primary string
+	keys := make(map[string][]byte)
+	for i, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: entry %d is not an <id>:<key> pair", ErrEncryptionInvalidKeys, i)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: key [%s] is not base64 encoded", ErrEncryptionInvalidKeys, parts[0])
+		}
+
+		if i == 0 {
+			primary = parts[0]
+		}
+		keys[parts[0]] = key
+	}
+
+	return NewAESGCMEncryptor(primary, keys)
+}
+
+// Encrypt encrypts a value with the primary key.
+// The returned value has the format `enc:<key id>:<base64 nonce and ciphertext>`.
+func (e *aesGCMEncryptor) Encrypt(plaintext []byte) (string, error) {
+	aead := e.keys[e.primary]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(e.primary))
+
+	return fmt.Sprintf("%s%s:%s", encryptedDataPrefix, e.primary, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// Decrypt decrypts a value encrypted with any of the keys of the encryptor.
+func (e *aesGCMEncryptor) Decrypt(ciphertext string) ([]byte, error) {
+	id, sealed, err := parseEncryptedValue(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, ok := e.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: [%s]", ErrEncryptionKeyNotFound, id)
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrEncryptionInvalidValue
+	}
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, sealed, []byte(id))
+}
+
+// IsPrimary checks if a value was encrypted with the primary key.
+func (e *aesGCMEncryptor) IsPrimary(ciphertext string) bool {
+	id, _, err := parseEncryptedValue(ciphertext)
+	return err == nil && id == e.primary
+}
+
+// isEncrypted checks if a value was encrypted by an Encryptor.
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedDataPrefix)
+}
+
+// parseEncryptedValue returns the key ID and the sealed data of an encrypted value.
+func parseEncryptedValue(value string) (string, []byte, error) {
+	if !isEncrypted(value) {
+		return "", nil, ErrEncryptionInvalidValue
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, encryptedDataPrefix), ":", 2)
+	if len(parts) != 2 {
+		return "", nil, ErrEncryptionInvalidValue
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, ErrEncryptionInvalidValue
+	}
+
+	return parts[0], sealed, nil
+}
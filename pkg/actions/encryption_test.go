@@ -0,0 +1,220 @@
+package actions
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/secrets"
+	"github.com/gazebo-web/cloudsim/v4/pkg/secrets/implementations/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// encryptionTestSecret is a sensitive value stored in encrypted deployments.
+type encryptionTestSecret struct {
+	Token string
+}
+
+var (
+	encryptionTestKey1 = []byte("0123456789abcdef0123456789abcdef")
+	encryptionTestKey2 = []byte("fedcba9876543210")
+)
+
+func TestAESGCMEncryptor(t *testing.T) {
+	encryptor, err := NewAESGCMEncryptor("key1", map[string][]byte{"key1": encryptionTestKey1})
+	require.NoError(t, err)
+
+	ciphertext, err := encryptor.Encrypt([]byte("test"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "enc:key1:"))
+	assert.True(t, encryptor.IsPrimary(ciphertext))
+
+	plaintext, err := encryptor.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "test", string(plaintext))
+
+	// Values are encrypted with random nonces
+	other, err := encryptor.Encrypt([]byte("test"))
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, other)
+
+	// Tampered values cannot be decrypted
+	_, err = encryptor.Decrypt(ciphertext[:len(ciphertext)-4] + "AAA=")
+	assert.Error(t, err)
+	_, err = encryptor.Decrypt("test")
+	assert.True(t, errors.Is(err, ErrEncryptionInvalidValue))
+}
+
+func TestNewAESGCMEncryptorErrors(t *testing.T) {
+	_, err := NewAESGCMEncryptor("key1", nil)
+	assert.True(t, errors.Is(err, ErrEncryptionNoKeys))
+
+	_, err = NewAESGCMEncryptor("key2", map[string][]byte{"key1": encryptionTestKey1})
+	assert.True(t, errors.Is(err, ErrEncryptionKeyNotFound))
+
+	_, err = NewAESGCMEncryptor("key1", map[string][]byte{"key1": []byte("short")})
+	assert.True(t, errors.Is(err, ErrEncryptionInvalidKeys))
+
+	_, err = NewAESGCMEncryptor("key:1", map[string][]byte{"key:1": encryptionTestKey1})
+	assert.True(t, errors.Is(err, ErrEncryptionInvalidKeys))
+}
+
+func TestAESGCMEncryptorKeyRotation(t *testing.T) {
+	old, err := NewAESGCMEncryptor("key1", map[string][]byte{"key1": encryptionTestKey1})
+	require.NoError(t, err)
+	ciphertext, err := old.Encrypt([]byte("test"))
+	require.NoError(t, err)
+
+	rotated, err := NewAESGCMEncryptor("key2", map[string][]byte{
+		"key1": encryptionTestKey1,
+		"key2": encryptionTestKey2,
+	})
+	require.NoError(t, err)
+
+	// Values encrypted with the old key can still be decrypted
+	assert.False(t, rotated.IsPrimary(ciphertext))
+	plaintext, err := rotated.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "test", string(plaintext))
+
+	// Values encrypted with removed keys cannot be decrypted
+	removed, err := NewAESGCMEncryptor("key2", map[string][]byte{"key2": encryptionTestKey2})
+	require.NoError(t, err)
+	_, err = removed.Decrypt(ciphertext)
+	assert.True(t, errors.Is(err, ErrEncryptionKeyNotFound))
+}
+
+func TestNewAESGCMEncryptorFromEnv(t *testing.T) {
+	t.Setenv("CLOUDSIM_TEST_ENCRYPTION_KEYS", "key2:"+base64.StdEncoding.EncodeToString(encryptionTestKey2)+
+		", key1:"+base64.StdEncoding.EncodeToString(encryptionTestKey1))
+
+	encryptor, err := NewAESGCMEncryptorFromEnv("CLOUDSIM_TEST_ENCRYPTION_KEYS")
+	require.NoError(t, err)
+
+	// The first key is the primary key
+	ciphertext, err := encryptor.Encrypt([]byte("test"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "enc:key2:"))
+
+	t.Setenv("CLOUDSIM_TEST_ENCRYPTION_KEYS", "")
+	_, err = NewAESGCMEncryptorFromEnv("CLOUDSIM_TEST_ENCRYPTION_KEYS")
+	assert.True(t, errors.Is(err, ErrEncryptionNoKeys))
+
+	t.Setenv("CLOUDSIM_TEST_ENCRYPTION_KEYS", "key1")
+	_, err = NewAESGCMEncryptorFromEnv("CLOUDSIM_TEST_ENCRYPTION_KEYS")
+	assert.True(t, errors.Is(err, ErrEncryptionInvalidKeys))
+}
+
+func TestNewAESGCMEncryptorFromSecret(t *testing.T) {
+	s := fake.NewFakeSecrets()
+	s.On("Get", mock.Anything, "keys", "default").Return(&secrets.Secret{
+		Data: map[string][]byte{
+			"primary": []byte("key2"),
+			"key1":    encryptionTestKey1,
+			"key2":    encryptionTestKey2,
+		},
+	}, nil)
+
+	encryptor, err := NewAESGCMEncryptorFromSecret(context.Background(), s, "keys", "default")
+	require.NoError(t, err)
+
+	ciphertext, err := encryptor.Encrypt([]byte("test"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "enc:key2:"))
+}
+
+func TestEncryptedDeploymentStore(t *testing.T) {
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+	jobDataTypeRegistry.register(GetJobDataType(encryptionTestSecret{}))
+	jobDataTypeRegistry.register(GetJobDataType(""))
+
+	encryptor, err := NewAESGCMEncryptor("key1", map[string][]byte{"key1": encryptionTestKey1})
+	require.NoError(t, err)
+
+	raw := NewMemoryDeploymentStore()
+	tx := NewEncryptedDeploymentStore(raw, encryptor, EncryptValueTypes(encryptionTestSecret{}))
+
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	secret := encryptionTestSecret{Token: "token"}
+	require.NoError(t, deployment.SetJobData(tx, nil, DeploymentJobData, secret))
+	require.NoError(t, deployment.SetJobData(tx, nil, DeploymentJobInput, "visible"))
+
+	// Only opted-in types are encrypted in the underlying store
+	data, err := raw.GetDeploymentData(deployment, "job", DeploymentJobData)
+	require.NoError(t, err)
+	assert.True(t, isEncrypted(*data.Data))
+	assert.NotContains(t, *data.Data, "token")
+
+	data, err = raw.GetDeploymentData(deployment, "job", DeploymentJobInput)
+	require.NoError(t, err)
+	assert.False(t, isEncrypted(*data.Data))
+
+	// Values are decrypted transparently
+	out, err := deployment.GetJobData(tx, nil, DeploymentJobData)
+	require.NoError(t, err)
+	assert.Equal(t, secret, out)
+
+	dataSet, err := tx.GetDeploymentDataSet(deployment)
+	require.NoError(t, err)
+	for _, data := range dataSet {
+		assert.False(t, isEncrypted(*data.Data))
+	}
+}
+
+func TestReencryptDeploymentData(t *testing.T) {
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+	jobDataTypeRegistry.register(GetJobDataType(encryptionTestSecret{}))
+	jobDataTypeRegistry.register(GetJobDataType(""))
+
+	old, err := NewAESGCMEncryptor("key1", map[string][]byte{"key1": encryptionTestKey1})
+	require.NoError(t, err)
+
+	raw := NewMemoryDeploymentStore()
+	tx := NewEncryptedDeploymentStore(raw, old, EncryptValueTypes(encryptionTestSecret{}))
+
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+	secret := encryptionTestSecret{Token: "token"}
+	require.NoError(t, deployment.SetJobData(tx, nil, DeploymentJobData, secret))
+	require.NoError(t, deployment.SetJobData(tx, nil, DeploymentJobInput, "input"))
+
+	// Rotate the key and opt in to encrypt job inputs
+	rotated, err := NewAESGCMEncryptor("key2", map[string][]byte{
+		"key1": encryptionTestKey1,
+		"key2": encryptionTestKey2,
+	})
+	require.NoError(t, err)
+	tx = NewEncryptedDeploymentStore(raw, rotated, EncryptValueTypes(encryptionTestSecret{}),
+		EncryptDataTypes(DeploymentJobInput))
+
+	updated, err := ReencryptDeploymentData(tx, deployment)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	dataSet, err := raw.GetDeploymentDataSet(deployment)
+	require.NoError(t, err)
+	require.Len(t, dataSet, 2)
+	for _, data := range dataSet {
+		assert.True(t, rotated.IsPrimary(*data.Data))
+	}
+
+	// Data encrypted with the primary key is not updated
+	updated, err = ReencryptDeploymentData(tx, deployment)
+	require.NoError(t, err)
+	assert.Equal(t, 0, updated)
+
+	out, err := deployment.GetJobData(tx, nil, DeploymentJobData)
+	require.NoError(t, err)
+	assert.Equal(t, secret, out)
+
+	// Stores that do not encrypt data cannot re-encrypt data
+	_, err = ReencryptDeploymentData(raw, deployment)
+	assert.True(t, errors.Is(err, ErrDeploymentStoreNotEncrypted))
+}
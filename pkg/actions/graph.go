@@ -0,0 +1,361 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// graphMaxErrorLength is the maximum length of error messages displayed in graph nodes.
+	graphMaxErrorLength = 80
+)
+
+// graphNodeState is the state of a job in an action graph with a deployment overlay.
+type graphNodeState string
+
+const (
+	// graphNodePending is used for jobs that have not been run by the deployment, and for graphs without an overlay.
+	graphNodePending graphNodeState = ""
+	// graphNodeDone is used for jobs that were run by the deployment.
+	graphNodeDone graphNodeState = "done"
+	// graphNodeSkipped is used for jobs that were skipped by the deployment.
+	graphNodeSkipped graphNodeState = "skipped"
+	// graphNodeCurrent is used for the job the deployment is currently running.
+	graphNodeCurrent graphNodeState = "current"
+	// graphNodeRollback is used for the job the deployment is currently rolling back.
+	graphNodeRollback graphNodeState = "rollback"
+	// graphNodeFailed is used for the job that caused a deployment to fail.
+	graphNodeFailed graphNodeState = "failed"
+)
+
+// graphNodeStyles contains the Graphviz and Mermaid style used for each node state.
+var graphNodeStyles = map[graphNodeState]string{
+	graphNodeDone:     "#c8e6c9",
+	graphNodeSkipped:  "#e0e0e0",
+	graphNodeCurrent:  "#fff59d",
+	graphNodeRollback: "#ffcc80",
+	graphNodeFailed:   "#ef9a9a",
+}
+
+// GraphOverlay contains the state of a deployment overlaid on an action graph.
+type GraphOverlay struct {
+	// Deployment contains the deployment whose current job is highlighted.
+	Deployment *Deployment
+	// Errors contains the errors recorded by the deployment. Jobs with errors display their errors.
+	Errors DeploymentErrors
+	// Finished contains the names of the jobs that finished running in the deployment.
+	Finished map[string]bool
+	// Skipped contains the names of the jobs that were skipped by the deployment.
+	Skipped map[string]bool
+}
+
+// NewGraphOverlay creates a GraphOverlay for a deployment, loading the errors it recorded and the jobs it finished
+// and skipped.
+func NewGraphOverlay(tx DeploymentStore, deployment *Deployment) (*GraphOverlay, error) {
+	errs, err := deployment.GetErrors(tx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSet, err := tx.GetDeploymentDataSet(deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay := &GraphOverlay{
+		Deployment: deployment,
+		Errors:     errs,
+		Finished:   make(map[string]bool),
+		Skipped:    make(map[string]bool),
+	}
+	for _, data := range dataSet {
+		switch data.Type {
+		case DeploymentJobOutput:
+			overlay.Finished[data.Job] = true
+		case DeploymentJobSkipped:
+			job := data.Job
+			skipped, err := isJobSkipped(tx, deployment, &job)
+			if err != nil {
+				return nil, err
+			}
+			overlay.Skipped[job] = skipped
+		}
+	}
+
+	return overlay, nil
+}
+
+// getFailedJob returns the name of the job that caused the deployment to fail, or an empty string if the deployment
+// did not fail.
+// The failed job is the job of the last error recorded by the deployment, provided that the job did not finish
+// afterwards (e.g. after being retried) or that the error was returned by its rollback handler.
+func (o *GraphOverlay) getFailedJob() string {
+	deployment := o.Deployment
+	if !deployment.isRollingBack() && !(deployment.isFinished() && deployment.RollbackError != nil) {
+		return ""
+	}
+
+	for i := len(o.Errors) - 1; i >= 0; i-- {
+		err := o.Errors[i]
+		if err.Job == nil {
+			continue
+		}
+		if o.Finished[*err.Job] && (err.Error == nil || !strings.HasPrefix(*err.Error, "rollback: ")) {
+			return ""
+		}
+
+		return *err.Job
+	}
+
+	return ""
+}
+
+// getJobState returns the state of a job that is not being run or rolled back by the deployment.
+func (o *GraphOverlay) getJobState(job string) graphNodeState {
+	switch {
+	case o.Skipped[job]:
+		return graphNodeSkipped
+	case o.Finished[job]:
+		return graphNodeDone
+	default:
+		return graphNodePending
+	}
+}
+
+// graphNode is a job in an action graph.
+type graphNode struct {
+	// id contains the identifier of the node in the graph.
+	id string
+	// lines contains the lines of text displayed in the node.
+	lines []string
+	// state contains the state of the job in the overlaid deployment.
+	state graphNodeState
+	// children contains the nodes of the jobs in a parallel job group.
+	children []*graphNode
+}
+
+// graphTypeName returns the name of a job data type displayed in graphs.
+func graphTypeName(dataType JobDataType) string {
+	if dataType == nil || dataType == NilJobDataType {
+		return "nil"
+	}
+
+	return dataType.String()
+}
+
+// newGraphNode creates the graph node for a job.
+func newGraphNode(id string, job *Job, errs map[string][]string) *graphNode {
+	lines := []string{
+		job.Name,
+		fmt.Sprintf("in: %s", graphTypeName(job.InputType)),
+		fmt.Sprintf("out: %s", graphTypeName(job.OutputType)),
+	}
+
+	var flags []string
+	if job.group != nil {
+		flags = append(flags, "parallel")
+	}
+	if job.SkipIf != nil {
+		flags = append(flags, "conditional")
+	}
+	if job.RollbackHandler != nil {
+		flags = append(flags, "rollback")
+	}
+	if job.RetryPolicy != nil {
+		flags = append(flags, fmt.Sprintf("retry x%d", job.RetryPolicy.MaxAttempts))
+	}
+	if len(flags) > 0 {
+		lines = append(lines, fmt.Sprintf("[%s]", strings.Join(flags, ", ")))
+	}
+
+	node := &graphNode{
+		id:    id,
+		lines: lines,
+	}
+
+	if jobErrs, ok := errs[job.Name]; ok {
+		for _, err := range jobErrs {
+			if len(err) > graphMaxErrorLength {
+				err = err[:graphMaxErrorLength] + "..."
+			}
+			node.lines = append(node.lines, fmt.Sprintf("error: %s", err))
+		}
+	}
+
+	if job.group != nil {
+		for i, child := range job.group.jobs {
+			node.children = append(node.children, newGraphNode(fmt.Sprintf("%s_%d", id, i), child, errs))
+		}
+	}
+
+	return node
+}
+
+// getGraphNodes returns the graph nodes for the jobs in an action, with the state of the overlaid deployment.
+func (a *Action) getGraphNodes(overlay *GraphOverlay) []*graphNode {
+	// Group errors by job
+	errs := make(map[string][]string)
+	if overlay != nil {
+		for _, err := range overlay.Errors {
+			if err.Job == nil || err.Error == nil {
+				continue
+			}
+			errs[*err.Job] = append(errs[*err.Job], *err.Error)
+		}
+	}
+
+	nodes := make([]*graphNode, len(a.Jobs))
+	for i, job := range a.Jobs {
+		nodes[i] = newGraphNode(fmt.Sprintf("job%d", i), job, errs)
+	}
+
+	if overlay == nil || overlay.Deployment == nil {
+		return nodes
+	}
+
+	// Overlay the deployment progress. The job that caused the deployment to fail takes precedence over progress.
+	deployment := overlay.Deployment
+	failed := overlay.getFailedJob()
+	current, err := a.getJobIndex(&deployment.CurrentJob)
+	if err != nil {
+		current = -1
+	}
+	for i, node := range nodes {
+		job := a.Jobs[i]
+
+		switch {
+		case job.Name == failed:
+			node.state = graphNodeFailed
+		case deployment.isRunning() && i == current:
+			node.state = graphNodeCurrent
+		case deployment.isRollingBack() && i == current:
+			node.state = graphNodeRollback
+		default:
+			node.state = overlay.getJobState(job.Name)
+		}
+
+		// Jobs in a failed group that returned an error and did not finish are also marked as failed
+		for j, child := range node.children {
+			childJob := job.group.jobs[j]
+			if job.Name == failed && len(errs[childJob.Name]) > 0 && !overlay.Finished[childJob.Name] {
+				child.state = graphNodeFailed
+				continue
+			}
+			child.state = overlay.getJobState(childJob.Name)
+		}
+	}
+
+	return nodes
+}
+
+// escapeDOT escapes a string to be used as a Graphviz label.
+func escapeDOT(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeMermaid escapes a string to be used as a Mermaid node label.
+func escapeMermaid(value string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(value)
+}
+
+// RenderDOT renders the sequence of jobs of an action as a Graphviz DOT graph.
+// Each job displays its input and output types, and whether it is conditional, has a rollback handler, has a retry
+// policy or runs a group of jobs in parallel. If `overlay` is not nil, the graph highlights the progress of the
+// deployment and the job that caused it to fail, and displays the errors recorded for each job.
+func (a *Action) RenderDOT(overlay *GraphOverlay) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "digraph \"%s\" {\n", escapeDOT(a.Name))
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\"];\n")
+
+	var writeNode func(node *graphNode, indent string)
+	writeNode = func(node *graphNode, indent string) {
+		fmt.Fprintf(&b, "%s%s [label=\"%s\"", indent, node.id, escapeDOT(strings.Join(node.lines, "\n")))
+		if color, ok := graphNodeStyles[node.state]; ok {
+			fmt.Fprintf(&b, ", fillcolor=\"%s\"", color)
+		}
+		b.WriteString("];\n")
+	}
+
+	nodes := a.getGraphNodes(overlay)
+	for _, node := range nodes {
+		writeNode(node, "\t")
+
+		// Parallel job groups are rendered as a cluster attached to the group job
+		if len(node.children) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\tsubgraph cluster_%s {\n", node.id)
+		b.WriteString("\t\tstyle=dashed;\n")
+		for _, child := range node.children {
+			writeNode(child, "\t\t")
+		}
+		b.WriteString("\t}\n")
+		for _, child := range node.children {
+			fmt.Fprintf(&b, "\t%s -> %s [style=dashed, arrowhead=none];\n", node.id, child.id)
+		}
+	}
+
+	for i := 1; i < len(nodes); i++ {
+		fmt.Fprintf(&b, "\t%s -> %s;\n", nodes[i-1].id, nodes[i].id)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// RenderMermaid renders the sequence of jobs of an action as a Mermaid flowchart.
+// It displays the same information as RenderDOT.
+func (a *Action) RenderMermaid(overlay *GraphOverlay) string {
+	var b strings.Builder
+
+	b.WriteString("flowchart LR\n")
+
+	states := make(map[graphNodeState][]string)
+	writeNode := func(node *graphNode, indent string) {
+		lines := make([]string, len(node.lines))
+		for i, line := range node.lines {
+			lines[i] = escapeMermaid(line)
+		}
+		fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, node.id, strings.Join(lines, "<br/>"))
+		if node.state != graphNodePending {
+			states[node.state] = append(states[node.state], node.id)
+		}
+	}
+
+	nodes := a.getGraphNodes(overlay)
+	for _, node := range nodes {
+		writeNode(node, "\t")
+
+		// Parallel job groups are rendered as a subgraph attached to the group job
+		if len(node.children) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\tsubgraph %s_group [\"%s\"]\n", node.id, escapeMermaid(node.lines[0]))
+		for _, child := range node.children {
+			writeNode(child, "\t\t")
+		}
+		b.WriteString("\tend\n")
+		for _, child := range node.children {
+			fmt.Fprintf(&b, "\t%s -.- %s\n", node.id, child.id)
+		}
+	}
+
+	for i := 1; i < len(nodes); i++ {
+		fmt.Fprintf(&b, "\t%s --> %s\n", nodes[i-1].id, nodes[i].id)
+	}
+
+	// Style nodes based on the deployment overlay
+	for _, state := range []graphNodeState{graphNodeDone, graphNodeSkipped, graphNodeCurrent, graphNodeRollback, graphNodeFailed} {
+		ids, ok := states[state]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "\tclassDef %s fill:%s\n", state, graphNodeStyles[state])
+		fmt.Fprintf(&b, "\tclass %s %s\n", strings.Join(ids, ","), state)
+	}
+
+	return b.String()
+}
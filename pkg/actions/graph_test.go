@@ -0,0 +1,192 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// createGraphTestAction creates an action used to test action graphs.
+func createGraphTestAction(t *testing.T) *Action {
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	noop := func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+		value interface{}) (interface{}, error) {
+
+		return value, nil
+	}
+	rollback := func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+		value interface{}, err error) (interface{}, error) {
+
+		return nil, nil
+	}
+
+	action, err := NewAction(Jobs{
+		{
+			Name:            "launch",
+			Execute:         noop,
+			RollbackHandler: rollback,
+			RetryPolicy:     &RetryPolicy{MaxAttempts: 3},
+			InputType:       GetJobDataType(""),
+			OutputType:      GetJobDataType(&actionTestStruct{}),
+		},
+		NewParallelJob("setup", Jobs{
+			{
+				Name:       "setup_a",
+				Execute:    noop,
+				InputType:  NilJobDataType,
+				OutputType: NilJobDataType,
+			},
+			{
+				Name:       "setup_\"b\"",
+				Execute:    noop,
+				InputType:  NilJobDataType,
+				OutputType: NilJobDataType,
+			},
+		}, nil),
+		{
+			Name:       "wait",
+			Execute:    noop,
+			InputType:  NilJobDataType,
+			OutputType: NilJobDataType,
+		},
+	})
+	require.NoError(t, err)
+	action.Name = "test"
+
+	return action
+}
+
+// actionTestStruct is used to test job data type names in action graphs.
+type actionTestStruct struct{}
+
+func TestActionRenderDOT(t *testing.T) {
+	action := createGraphTestAction(t)
+
+	dot := action.RenderDOT(nil)
+	assert.Contains(t, dot, "digraph \"test\" {")
+	assert.Contains(t, dot, `job0 [label="launch\nin: string\nout: *actions.actionTestStruct\n[rollback, retry x3]"];`)
+	assert.Contains(t, dot, `job1 [label="setup\nin: nil\nout: nil\n[parallel, rollback]"];`)
+	assert.Contains(t, dot, "subgraph cluster_job1 {")
+	assert.Contains(t, dot, `job1_1 [label="setup_\"b\"\nin: nil\nout: nil"];`)
+	assert.Contains(t, dot, "job1 -> job1_0 [style=dashed, arrowhead=none];")
+	assert.Contains(t, dot, "job0 -> job1;")
+	assert.Contains(t, dot, "job1 -> job2;")
+	assert.NotContains(t, dot, "job0 -> job2;")
+	assert.NotContains(t, dot, graphNodeStyles[graphNodeCurrent])
+}
+
+func TestActionRenderMermaid(t *testing.T) {
+	action := createGraphTestAction(t)
+
+	mermaid := action.RenderMermaid(nil)
+	assert.Contains(t, mermaid, "flowchart LR\n")
+	assert.Contains(t, mermaid, `job0["launch<br/>in: string<br/>out: *actions.actionTestStruct<br/>[rollback, retry x3]"]`)
+	assert.Contains(t, mermaid, `subgraph job1_group ["setup"]`)
+	assert.Contains(t, mermaid, `job1_1["setup_#quot;b#quot;<br/>in: nil<br/>out: nil"]`)
+	assert.Contains(t, mermaid, "job1 -.- job1_0")
+	assert.Contains(t, mermaid, "job0 --> job1")
+	assert.Contains(t, mermaid, "job1 --> job2")
+	assert.NotContains(t, mermaid, "classDef")
+}
+
+func TestActionRenderDeploymentOverlay(t *testing.T) {
+	action := createGraphTestAction(t)
+	tx := NewMemoryDeploymentStore()
+
+	deployment := &Deployment{UUID: "test", CurrentJob: "setup", Status: deploymentStatusRunning}
+	require.NoError(t, tx.CreateDeployment(deployment))
+	launch := "launch"
+	require.NoError(t, deployment.addJobError(tx, &launch, assert.AnError))
+	require.NoError(t, deployment.SetJobData(tx, &launch, DeploymentJobOutput, &actionTestStruct{}))
+	child := "setup_a"
+	require.NoError(t, deployment.addJobError(tx, &child, assert.AnError))
+
+	overlay, err := NewGraphOverlay(tx, deployment)
+	require.NoError(t, err)
+	require.Len(t, overlay.Errors, 2)
+	assert.True(t, overlay.Finished["launch"])
+
+	// Jobs that failed before being retried successfully are done, and running jobs display their errors
+	dot := action.RenderDOT(overlay)
+	assert.Contains(t, dot, `job0 [label="launch\nin: string\nout: *actions.actionTestStruct\n[rollback, retry x3]\nerror: `+assert.AnError.Error()+`", fillcolor="#c8e6c9"];`)
+	assert.Contains(t, dot, `fillcolor="#fff59d"`)
+	assert.Contains(t, dot, `job1_0 [label="setup_a\nin: nil\nout: nil\nerror: `+assert.AnError.Error()+`"];`)
+	assert.Contains(t, dot, `job2 [label="wait\nin: nil\nout: nil"];`)
+
+	mermaid := action.RenderMermaid(overlay)
+	assert.Contains(t, mermaid, "class job0 done")
+	assert.Contains(t, mermaid, "class job1 current")
+	assert.NotContains(t, mermaid, "failed")
+	assert.NotContains(t, mermaid, "job2 ")
+}
+
+func TestActionRenderDeploymentOverlayFailed(t *testing.T) {
+	action := createGraphTestAction(t)
+	tx := NewMemoryDeploymentStore()
+
+	deployment := &Deployment{UUID: "test", CurrentJob: "setup", Status: deploymentStatusRunning}
+	require.NoError(t, tx.CreateDeployment(deployment))
+	launch := "launch"
+	require.NoError(t, deployment.SetJobData(tx, &launch, DeploymentJobOutput, &actionTestStruct{}))
+	childA := "setup_a"
+	childB := "setup_\"b\""
+	require.NoError(t, deployment.SetJobData(tx, &childB, DeploymentJobOutput, nil))
+	require.NoError(t, deployment.addJobError(tx, &childA, assert.AnError))
+	require.NoError(t, deployment.addJobError(tx, nil, assert.AnError))
+
+	// Rolling back deployments highlight the failed job and the job being rolled back
+	deployment.Status = deploymentStatusRollback
+	overlay, err := NewGraphOverlay(tx, deployment)
+	require.NoError(t, err)
+	mermaid := action.RenderMermaid(overlay)
+	assert.Contains(t, mermaid, "class job0,job1_1 done")
+	assert.Contains(t, mermaid, "class job1,job1_0 failed")
+
+	deployment.CurrentJob = "launch"
+	mermaid = action.RenderMermaid(overlay)
+	assert.Contains(t, mermaid, "class job0 rollback")
+	assert.Contains(t, mermaid, "class job1,job1_0 failed")
+
+	// Rolled back deployments keep the jobs that finished before the deployment failed as done
+	rollbackErr := assert.AnError.Error()
+	deployment.RollbackError = &rollbackErr
+	deployment.Status = deploymentStatusFinished
+	mermaid = action.RenderMermaid(overlay)
+	assert.Contains(t, mermaid, "class job0,job1_1 done")
+	assert.Contains(t, mermaid, "class job1,job1_0 failed")
+
+	// Jobs whose rollback handler failed are marked as failed
+	require.NoError(t, deployment.addJobError(tx, &launch, errors.New("rollback: "+assert.AnError.Error())))
+	overlay, err = NewGraphOverlay(tx, deployment)
+	require.NoError(t, err)
+	mermaid = action.RenderMermaid(overlay)
+	assert.Contains(t, mermaid, "class job1_1 done")
+	assert.Contains(t, mermaid, "class job0 failed")
+}
+
+func TestActionRenderDeploymentOverlaySkipped(t *testing.T) {
+	action := createGraphTestAction(t)
+	tx := NewMemoryDeploymentStore()
+
+	deployment := &Deployment{UUID: "test", CurrentJob: "wait", Status: deploymentStatusFinished}
+	require.NoError(t, tx.CreateDeployment(deployment))
+	for _, job := range []string{"launch", "setup", "setup_a", "setup_\"b\"", "wait"} {
+		job := job
+		require.NoError(t, deployment.SetJobData(tx, &job, DeploymentJobOutput, nil))
+	}
+	setup := "setup"
+	require.NoError(t, deployment.SetJobData(tx, &setup, DeploymentJobSkipped, true))
+
+	overlay, err := NewGraphOverlay(tx, deployment)
+	require.NoError(t, err)
+	assert.True(t, overlay.Skipped["setup"])
+
+	mermaid := action.RenderMermaid(overlay)
+	assert.Contains(t, mermaid, "class job0,job1_0,job1_1,job2 done")
+	assert.Contains(t, mermaid, "class job1 skipped")
+	assert.Contains(t, action.RenderDOT(overlay), `fillcolor="#e0e0e0"`)
+}
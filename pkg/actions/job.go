@@ -1,9 +1,9 @@
 package actions
 
 import (
+	"context"
 	"fmt"
 	"github.com/imdario/mergo"
-	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 	"gopkg.in/go-playground/validator.v9"
 	"reflect"
@@ -20,11 +20,14 @@ var (
 )
 
 // JobFunc is the function signature used by job hooks and Execute function.
-type JobFunc func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error)
+// `ctx` is cancelled if the deployment is stopped while the job is running. Job functions should pass it to any
+// long-running operations they perform.
+type JobFunc func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}) (interface{}, error)
 
 // JobErrorHandler is the job function type called when an error occurs in a job.
-type JobErrorHandler func(store Store, tx *gorm.DB, deployment *Deployment, value interface{},
-	err error) (interface{}, error)
+type JobErrorHandler func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}, err error) (interface{}, error)
 
 // JobDataType is used to store Job input and output data types.
 type JobDataType reflect.Type
@@ -55,6 +58,15 @@ type JobDataType reflect.Type
 // them. If a function or error-handled function returns an error, this will trigger a rollback of the entire job
 // sequence.
 //
+// Jobs that do not depend on each other can be run concurrently by grouping them in a single job with
+// NewParallelJob.
+//
+// Jobs that only apply to some executions of an action can define a SkipIf predicate. Skipped jobs pass through the
+// input value they receive to the next job, and are not rolled back.
+//
+// Jobs that can fail due to temporary issues (e.g. a cloud provider running out of capacity) can define a RetryPolicy
+// to be run again in place instead of triggering a rollback of the entire action.
+//
 // A Job may contain an optional RollbackHandler function. The RollbackHandler function is in charge of releasing any
 // shared resources claimed (e.g. cloud instances, orchestration resources, etc.) and undoing any changes that may
 // impact other operations. Rollback logic should always double check to understand the state of things, as the job
@@ -64,7 +76,7 @@ type JobDataType reflect.Type
 //
 // The RollbackHandler function may require context from the Execute function to perform its operations (e.g. instance
 // ids, pod ids, etc.). This shared context should be stored by the Execute function by calling deployment.SetJobData
-// and creating a `deploymentData` type entry. The shared context can then be retrieved by the RollbackHandler by
+// and creating a `DeploymentData` type entry. The shared context can then be retrieved by the RollbackHandler by
 // calling deployment.GetJobData.
 //
 // Jobs contain InputType and OutputType fields. These required fields contain the data types expected of the values
@@ -76,6 +88,10 @@ type JobDataType reflect.Type
 // `NilJobDataType` can be used to indicate such. Note that this does not mean that the job will not receive or return
 // data; this information lets an actions.Service instance know how to recover data from the persistent records.
 //
+// Jobs created with NewJob receive and return values of specific types. Their InputType and OutputType are derived
+// from those types, and their functions return an error instead of panicking if they receive a value of a different
+// type.
+//
 // As an illustration, consider the following scenario:
 //
 //          ┌────┐                 ┌────┐                 ┌────┐
@@ -104,12 +120,22 @@ type Job struct {
 	PostHooks []JobFunc
 	// RollbackHandler contains the rollback function for this job.
 	RollbackHandler JobErrorHandler
+	// SkipIf contains the optional predicate used to skip this job.
+	// It is evaluated before running the job's pre-hooks. If it returns true, the job is not run and it returns the
+	// input value it received. The result is recorded in the deployment.
+	SkipIf JobSkipFunc
+	// RetryPolicy contains the optional retry policy for this job.
+	// If nil, the job is not retried and any error returned by it triggers a rollback.
+	RetryPolicy *RetryPolicy
 	// InputType contains the input type this job receives.
 	// This should contain the return value of calling `GetJobDataType` with a value of the expected type.
 	InputType JobDataType `validate:"required"`
 	// OutputType contains the output type this job returns.
 	// This should contain the return value of calling `GetJobDataType` with a value of the expected type.
 	OutputType JobDataType `validate:"required"`
+	// group contains the group of jobs run concurrently by this job.
+	// It is only set for jobs created with NewParallelJob.
+	group *jobGroup
 }
 
 // GetJobDataType returns the data type for a job input or output.
@@ -157,6 +183,7 @@ func (j *Job) Extend(extension Job) *Job {
 	if err := mergo.Merge(&extension, *j); err != nil {
 		panic(fmt.Sprintf("extend for %s failed to merge definitions: %s", j.Name, err.Error()))
 	}
+	extension.group = j.group
 
 	return &extension
 }
@@ -173,15 +200,70 @@ func (j Job) Rename(name string) *Job {
 func (j *Job) registerTypes(registry dataTypeRegistry) {
 	registry.register(GetJobDataType(j.InputType))
 	registry.register(GetJobDataType(j.OutputType))
+
+	// Register the types of the jobs in the group
+	if j.group != nil {
+		j.group.jobs.registerTypes(registry)
+	}
 }
 
 // validate checks that all the required fields are in place
 func (j *Job) validate() error {
-	return validator.New().Struct(j)
+	if err := validator.New().Struct(j); err != nil {
+		return err
+	}
+
+	// Validate the jobs in the group
+	if j.group != nil {
+		if err := j.group.jobs.notEmpty(); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("parallel job %s", j.Name))
+		}
+		if err := j.group.jobs.jobsAreValid(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// names returns the name of this job, followed by the names of the jobs in its group, if any.
+func (j *Job) names() []string {
+	names := []string{j.Name}
+
+	if j.group != nil {
+		for _, job := range j.group.jobs {
+			names = append(names, job.names()...)
+		}
+	}
+
+	return names
 }
 
 // Run runs the job. It calls the job's pre-hooks, followed by its Execute method, and finally its post-hooks.
-func (j *Job) Run(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+// If the job has a SkipIf predicate that returns true, the job is not run and the input value is returned.
+// If the job has a RetryPolicy, the job is run again when it fails with a retryable error.
+func (j *Job) Run(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}) (interface{}, error) {
+
+	skip, err := j.shouldSkip(store, tx, deployment, value)
+	if err != nil {
+		return nil, err
+	}
+	if skip {
+		return value, nil
+	}
+
+	if j.RetryPolicy != nil {
+		return j.runWithRetryPolicy(ctx, store, tx, deployment, value)
+	}
+
+	return j.run(ctx, store, tx, deployment, value)
+}
+
+// run runs a single attempt of the job.
+func (j *Job) run(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}) (interface{}, error) {
+
 	var err error
 	// Ensure there is an Execute function
 	if j.Execute == nil {
@@ -193,17 +275,17 @@ func (j *Job) Run(store Store, tx *gorm.DB, deployment *Deployment, value interf
 	inputValueIsNil := value == nil
 
 	// Process pre-hooks
-	if value, err = j.processHooks(store, tx, deployment, value, &j.PreHooks); err != nil {
+	if value, err = j.processHooks(ctx, store, tx, deployment, value, &j.PreHooks); err != nil {
 		return nil, err
 	}
 
 	// Execute job
-	if value, err = callJobFunc(j.Execute, store, tx, deployment, value); err != nil {
+	if value, err = callJobFunc(ctx, j.Execute, store, tx, deployment, value); err != nil {
 		return nil, err
 	}
 
 	// Process post-hooks
-	if value, err = j.processHooks(store, tx, deployment, value, &j.PostHooks); err != nil {
+	if value, err = j.processHooks(ctx, store, tx, deployment, value, &j.PostHooks); err != nil {
 		return nil, err
 	}
 
@@ -216,13 +298,13 @@ func (j *Job) Run(store Store, tx *gorm.DB, deployment *Deployment, value interf
 }
 
 // processHooks receives an input value and processes it using a sequence of hook functions.
-func (j *Job) processHooks(store Store, tx *gorm.DB, deployment *Deployment, value interface{},
-	hooks *[]JobFunc) (interface{}, error) {
+func (j *Job) processHooks(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}, hooks *[]JobFunc) (interface{}, error) {
 
 	var err error
 	for _, hook := range *hooks {
 		// Process the values
-		if value, err = callJobFunc(hook, store, tx, deployment, value); err != nil {
+		if value, err = callJobFunc(ctx, hook, store, tx, deployment, value); err != nil {
 			return nil, err
 		}
 	}
@@ -231,12 +313,12 @@ func (j *Job) processHooks(store Store, tx *gorm.DB, deployment *Deployment, val
 }
 
 // callJobFunc calls a function of type JobFunc and checks that the output is valid.
-func callJobFunc(jobFunc JobFunc, store Store, tx *gorm.DB, deployment *Deployment,
+func callJobFunc(ctx context.Context, jobFunc JobFunc, store Store, tx DeploymentStore, deployment *Deployment,
 	value interface{}) (interface{}, error) {
 
 	// Process the values
 	var err error
-	if value, err = jobFunc(store, tx, deployment, value); err != nil {
+	if value, err = jobFunc(ctx, store, tx, deployment, value); err != nil {
 		return nil, err
 	}
 
@@ -274,14 +356,17 @@ func (s *Jobs) jobsAreValid() error {
 }
 
 // jobNamesAreUnique checks that all job names in a job sequence are unique.
+// The names of jobs inside parallel job groups are also checked.
 // This is necessary to recover start from the database.
 func (s *Jobs) jobNamesAreUnique() error {
 	jobNames := make(map[string]interface{}, len(*s))
 	for _, job := range *s {
-		if _, ok := jobNames[job.Name]; ok {
-			return errors.Wrap(ErrJobsNamesNotUnique, fmt.Sprintf("job name %s is not unique", job.Name))
+		for _, name := range job.names() {
+			if _, ok := jobNames[name]; ok {
+				return errors.Wrap(ErrJobsNamesNotUnique, fmt.Sprintf("job name %s is not unique", name))
+			}
+			jobNames[name] = nil
 		}
-		jobNames[job.Name] = nil
 	}
 
 	return nil
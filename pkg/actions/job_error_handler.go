@@ -1,7 +1,7 @@
 package actions
 
 import (
-	"github.com/jinzhu/gorm"
+	"context"
 )
 
 // WrapErrorHandler wraps a job function with an ErrorHandler.
@@ -9,10 +9,11 @@ import (
 // If `fn` returns an error, the error is handled by the `errorHandler` function.
 // If the handler returns an error, the error is considered critical and triggers an action execution rollback.
 func WrapErrorHandler(fn JobFunc, errorHandler JobErrorHandler) JobFunc {
-	return func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+	return func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+		value interface{}) (interface{}, error) {
 
 		var err error
-		value, err = fn(store, tx, deployment, value)
+		value, err = fn(ctx, store, tx, deployment, value)
 		if err != nil {
 			// Add the error
 			if err := deployment.addJobError(tx, nil, err); err != nil {
@@ -21,7 +22,7 @@ func WrapErrorHandler(fn JobFunc, errorHandler JobErrorHandler) JobFunc {
 
 			// Try to handle the error
 			var handlerErr error
-			value, handlerErr = errorHandler(store, tx, deployment, value, err)
+			value, handlerErr = errorHandler(ctx, store, tx, deployment, value, err)
 
 			// If the handler returned an error, only add it if it differs from the fn error or the same error will be
 			// added twice.
@@ -41,8 +42,8 @@ func WrapErrorHandler(fn JobFunc, errorHandler JobErrorHandler) JobFunc {
 }
 
 // ErrorHandlerIgnoreError ignores errors returned by a function and continues execution.
-func ErrorHandlerIgnoreError(store Store, tx *gorm.DB, deployment *Deployment, value interface{},
-	err error) (interface{}, error) {
+func ErrorHandlerIgnoreError(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}, err error) (interface{}, error) {
 
 	return value, nil
 }
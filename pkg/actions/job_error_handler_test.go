@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"errors"
 	"github.com/jinzhu/gorm"
 	"github.com/satori/go.uuid"
@@ -33,21 +34,21 @@ var jobErrorTestData = struct {
 	handlerErr: errors.New("handler"),
 
 	// Job functions
-	fn: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+	fn: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 		return value, nil
 	},
-	failingFn: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+	failingFn: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 		return value, errors.New("fn")
 	},
 
 	// Job error handlers
-	errHandler: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}, err error) (interface{}, error) {
+	errHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}, err error) (interface{}, error) {
 		return value, nil
 	},
-	passthroughErrHandler: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}, err error) (interface{}, error) {
+	passthroughErrHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}, err error) (interface{}, error) {
 		return value, err
 	},
-	failingErrHandler: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}, err error) (interface{}, error) {
+	failingErrHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}, err error) (interface{}, error) {
 		return value, errors.New("handler")
 	},
 
@@ -71,7 +72,7 @@ func TestJobFuncWrapErrorHandler(t *testing.T) {
 
 	totalErrCount := 0
 	test := func(fn JobFunc, expectedErr error, expectedErrCount int) {
-		_, err := fn(tr.store, tr.db, deployment, nil)
+		_, err := fn(context.Background(), tr.store, tr.tx, deployment, nil)
 		if expectedErr != nil {
 			require.NotNil(t, err)
 			require.Equal(t, expectedErr.Error(), err.Error())
@@ -109,7 +110,7 @@ func TestErrorHandlerIgnoreError(t *testing.T) {
 
 	test := func(fn JobFunc) {
 		wrappedFn := WrapErrorHandler(fn, ErrorHandlerIgnoreError)
-		_, err := wrappedFn(tr.store, tr.db, deployment, nil)
+		_, err := wrappedFn(context.Background(), tr.store, tr.tx, deployment, nil)
 		require.NoError(t, err)
 	}
 	test(setd.fn)
@@ -123,11 +124,11 @@ func TestJobRunErrorHandler(t *testing.T) {
 	td := getTestData(t)
 	setd := jobErrorTestData
 
-	deployment, err := newDeployment(tr.db, td.action, uuid.NewV4().String())
+	deployment, err := newDeployment(tr.tx, td.action, uuid.NewV4().String(), nil)
 	require.NoError(t, err)
 
 	test := func(job *Job, expectedErr error) {
-		_, err := job.Run(tr.store, tr.db, deployment, nil)
+		_, err := job.Run(context.Background(), tr.store, tr.tx, deployment, nil)
 
 		// Check error
 		if expectedErr != nil {
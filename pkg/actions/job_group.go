@@ -0,0 +1,255 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrJobGroupFailed is returned when one or more jobs in a parallel job group fail.
+	ErrJobGroupFailed = errors.New("parallel job group failed")
+)
+
+// JobGroupMergeFunc is the function signature used to merge the outputs of the jobs in a parallel job group.
+// `value` contains the input value received by the group, and `outputs` maps the name of each job in the group to the
+// value it returned.
+type JobGroupMergeFunc func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}, outputs map[string]interface{}) (interface{}, error)
+
+// jobGroup contains a set of jobs that are run concurrently as a single job in an action.
+type jobGroup struct {
+	// jobs contains the jobs run concurrently by the group.
+	jobs Jobs
+	// merge is used to merge the outputs of the group jobs into a single output value.
+	merge JobGroupMergeFunc
+}
+
+// NewParallelJob creates a job that runs a group of jobs concurrently.
+//
+// Every job in the group receives the input value of the parallel job (fan-out). Jobs that require a specific input
+// should use PreHooks to get it from the received value or the store. Once all the jobs in the group have finished,
+// their outputs are passed to `merge` to create the value returned to the next job in the action (fan-in). If `merge`
+// is nil, the parallel job passes through the input value it received.
+//
+// Jobs in the group are regular jobs. Their data is persisted under their own name, so job functions that call
+// deployment.SetJobData and deployment.GetJobData without a job name will read and write data for the group job
+// currently running. The input and output of each job in the group are also persisted. This allows a restarted
+// deployment to only run the jobs in the group that had not finished, and restore the outputs of the ones that did.
+//
+// If one or more jobs in the group fail, the parallel job waits for the rest of the jobs to finish and then returns an
+// error wrapping ErrJobGroupFailed with the errors of all the failed jobs. When the action is rolled back, the
+// RollbackHandler of every job in the group that was started is called with the input value the job received,
+// including the ones that failed. Jobs that were skipped are not rolled back.
+//
+// Jobs in the group share the same store and deployment store, and are run in separate goroutines. Job functions
+// must be safe to run concurrently with the rest of the jobs in the group.
+//
+// Job names must be unique across the entire action, including the names of the jobs in a group.
+// InputType and OutputType are set to NilJobDataType by default, and can be customized by calling Job.Extend.
+func NewParallelJob(name string, jobs Jobs, merge JobGroupMergeFunc) *Job {
+	group := &jobGroup{
+		jobs:  jobs,
+		merge: merge,
+	}
+
+	return &Job{
+		Name:            name,
+		Execute:         group.execute,
+		RollbackHandler: group.rollback,
+		InputType:       NilJobDataType,
+		OutputType:      NilJobDataType,
+		group:           group,
+	}
+}
+
+// execute runs the jobs in the group concurrently and merges their outputs.
+// Jobs that finished in a previous execution of the deployment are not run again.
+func (g *jobGroup) execute(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}) (interface{}, error) {
+
+	outputs := make(map[string]interface{}, len(g.jobs))
+	var mutex sync.Mutex
+
+	// Restore the outputs of jobs that have already finished
+	pending := make(Jobs, 0, len(g.jobs))
+	for _, job := range g.jobs {
+		out, finished, err := getJobGroupOutput(tx, deployment.forJob(job.Name))
+		if err != nil {
+			return nil, err
+		}
+		if !finished {
+			pending = append(pending, job)
+			continue
+		}
+		outputs[job.Name] = out
+	}
+
+	// Run the rest of the jobs
+	err := runJobGroup(pending, func(job *Job) error {
+		out, err := runJobGroupJob(ctx, store, tx, deployment.forJob(job.Name), job, value)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		outputs[job.Name] = out
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge the outputs
+	if g.merge == nil {
+		return value, nil
+	}
+
+	return g.merge(ctx, store, tx, deployment, value, outputs)
+}
+
+// rollback calls the rollback handler of every job in the group that was started.
+// Rollback handlers receive the input value persisted for their job.
+// All rollback handlers are called even if some of them fail.
+func (g *jobGroup) rollback(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}, err error) (interface{}, error) {
+
+	// Only rollback jobs that were started
+	started := make(Jobs, 0, len(g.jobs))
+	inputs := make(map[string]interface{}, len(g.jobs))
+	for _, job := range g.jobs {
+		if job.RollbackHandler == nil {
+			continue
+		}
+
+		in, ok, dataErr := getJobGroupInput(tx, deployment.forJob(job.Name))
+		if dataErr != nil {
+			return nil, dataErr
+		}
+		skipped, dataErr := isJobSkipped(tx, deployment.forJob(job.Name), nil)
+		if dataErr != nil {
+			return nil, dataErr
+		}
+		if ok && !skipped {
+			started = append(started, job)
+			inputs[job.Name] = in
+		}
+	}
+
+	rollbackErr := runJobGroup(started, func(job *Job) error {
+		jobDeployment := deployment.forJob(job.Name)
+
+		_, handlerErr := job.RollbackHandler(ctx, store, tx, jobDeployment, inputs[job.Name], err)
+		if handlerErr != nil {
+			if err := jobDeployment.addJobError(tx, nil, fmt.Errorf("rollback: %s", handlerErr.Error())); err != nil {
+				return err
+			}
+			return handlerErr
+		}
+
+		return nil
+	})
+
+	return nil, rollbackErr
+}
+
+// runJobGroupJob runs a single job in a group.
+// `deployment` must be the deployment returned by calling Deployment.forJob with the job name.
+func runJobGroupJob(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, job *Job,
+	value interface{}) (interface{}, error) {
+
+	// Store the job input. This entry marks the job as started.
+	if err := deployment.SetJobData(tx, nil, DeploymentJobInput, value); err != nil {
+		return nil, err
+	}
+
+	out, err := job.Run(ctx, store, tx, deployment, value)
+	if err != nil {
+		if err := deployment.addJobError(tx, nil, err); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+
+	// Store the job output. This entry marks the job as finished.
+	if err := deployment.SetJobData(tx, nil, DeploymentJobOutput, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// runJobGroup calls fn for each job in a slice of jobs concurrently, and waits for all calls to finish.
+// Panics triggered by fn are recovered and returned as errors.
+// If one or more calls return an error, an error wrapping ErrJobGroupFailed is returned.
+func runJobGroup(jobs Jobs, fn func(job *Job) error) error {
+	errs := make([]error, len(jobs))
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job *Job) {
+			defer wg.Done()
+
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("running job panic: %s", r)
+				}
+			}()
+
+			errs[i] = fn(job)
+		}(i, job)
+	}
+	wg.Wait()
+
+	// Aggregate errors
+	msgs := make([]string, 0, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("[%s] %s", jobs[i].Name, err.Error()))
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.Wrap(ErrJobGroupFailed, strings.Join(msgs, "; "))
+	}
+
+	return nil
+}
+
+// getJobGroupOutput returns the persisted output of a job in a group.
+// `deployment` must be the deployment returned by calling Deployment.forJob with the job name.
+// The returned boolean is true if the job has finished.
+func getJobGroupOutput(tx DeploymentStore, deployment *Deployment) (interface{}, bool, error) {
+	out, err := deployment.GetJobData(tx, nil, DeploymentJobOutput)
+	switch {
+	case err == nil:
+		return out, true, nil
+	case errors.Is(err, ErrDeploymentDataNoData):
+		return nil, true, nil
+	case errors.Is(err, ErrDeploymentDataNotFound):
+		return nil, false, nil
+	default:
+		return nil, false, err
+	}
+}
+
+// getJobGroupInput returns the persisted input of a job in a group.
+// `deployment` must be the deployment returned by calling Deployment.forJob with the job name.
+// The returned boolean is true if the job was started.
+func getJobGroupInput(tx DeploymentStore, deployment *Deployment) (interface{}, bool, error) {
+	in, err := deployment.GetJobData(tx, nil, DeploymentJobInput)
+	switch {
+	case err == nil:
+		return in, true, nil
+	case errors.Is(err, ErrDeploymentDataNoData):
+		return nil, true, nil
+	case errors.Is(err, ErrDeploymentDataNotFound):
+		return nil, false, nil
+	default:
+		return nil, false, err
+	}
+}
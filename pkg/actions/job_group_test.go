@@ -0,0 +1,332 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type JobGroupTestStruct struct {
+	Value int
+}
+
+// createJobGroupTestJob creates a job for parallel job group tests.
+// The job adds `increment` to the input value and returns it in a new JobGroupTestStruct.
+func createJobGroupTestJob(name string, increment int) *Job {
+	return &Job{
+		Name: name,
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
+			input := value.(*JobGroupTestStruct)
+			return &JobGroupTestStruct{Value: input.Value + increment}, nil
+		},
+		InputType:  GetJobDataType(&JobGroupTestStruct{}),
+		OutputType: GetJobDataType(&JobGroupTestStruct{}),
+	}
+}
+
+// sumJobGroupTestOutputs is a JobGroupMergeFunc that adds up the outputs of a group of test jobs.
+func sumJobGroupTestOutputs(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{},
+	outputs map[string]interface{}) (interface{}, error) {
+
+	sum := 0
+	for _, out := range outputs {
+		sum += out.(*JobGroupTestStruct).Value
+	}
+
+	return &JobGroupTestStruct{Value: sum}, nil
+}
+
+func TestNewParallelJob(t *testing.T) {
+	type TestStruct struct{}
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	branch := createJobGroupTestJob("branch", 1)
+	branch.InputType = GetJobDataType(TestStruct{})
+
+	_, err := NewAction(Jobs{
+		createJobGroupTestJob("job", 1),
+		NewParallelJob("group", Jobs{branch}, nil),
+	})
+	require.NoError(t, err)
+
+	// Types of jobs in the group should be registered
+	_, ok := jobDataTypeRegistry["actions.TestStruct"]
+	assert.True(t, ok)
+}
+
+func TestNewParallelJobValidate(t *testing.T) {
+	// Empty groups are not valid
+	_, err := NewAction(Jobs{NewParallelJob("group", Jobs{}, nil)})
+	require.True(t, errors.Is(err, ErrJobsEmptySequence))
+
+	// Jobs in the group must be valid
+	_, err = NewAction(Jobs{NewParallelJob("group", Jobs{{Name: "invalid"}}, nil)})
+	require.Error(t, err)
+
+	// Job names must be unique across the action, including jobs in groups
+	_, err = NewAction(Jobs{
+		createJobGroupTestJob("job", 1),
+		NewParallelJob("group", Jobs{createJobGroupTestJob("job", 1)}, nil),
+	})
+	require.True(t, errors.Is(err, ErrJobsNamesNotUnique))
+
+	_, err = NewAction(Jobs{
+		NewParallelJob("group", Jobs{createJobGroupTestJob("group", 1)}, nil),
+	})
+	require.True(t, errors.Is(err, ErrJobsNamesNotUnique))
+}
+
+func TestExtendParallelJob(t *testing.T) {
+	group := NewParallelJob("group", Jobs{createJobGroupTestJob("branch", 1)}, nil)
+
+	extended := group.Extend(Job{
+		InputType:  GetJobDataType(&JobGroupTestStruct{}),
+		OutputType: GetJobDataType(&JobGroupTestStruct{}),
+	})
+
+	require.Equal(t, []string{"group", "branch"}, extended.names())
+	require.Equal(t, GetJobDataType(&JobGroupTestStruct{}), extended.InputType)
+}
+
+func TestRunJobGroupAggregatesErrors(t *testing.T) {
+	jobs := Jobs{
+		{Name: "ok"},
+		{Name: "fail"},
+		{Name: "panic"},
+	}
+
+	err := runJobGroup(jobs, func(job *Job) error {
+		switch job.Name {
+		case "fail":
+			return assert.AnError
+		case "panic":
+			panic("test")
+		}
+		return nil
+	})
+
+	require.True(t, errors.Is(err, ErrJobGroupFailed))
+	assert.Contains(t, err.Error(), "[fail] "+assert.AnError.Error())
+	assert.Contains(t, err.Error(), "[panic] running job panic: test")
+	assert.NotContains(t, err.Error(), "[ok]")
+}
+
+func TestExecuteParallelJob(t *testing.T) {
+	testExecuteParallelJob(t, NewMemoryDeploymentStore())
+}
+
+func TestExecuteParallelJobGormStore(t *testing.T) {
+	tr := setupTest(t)
+	defer tr.db.Close()
+
+	// Jobs in the group share a single database transaction
+	dbTx := tr.db.Begin()
+	defer dbTx.Rollback()
+
+	testExecuteParallelJob(t, NewGormDeploymentStore(dbTx))
+}
+
+// testExecuteParallelJob runs an action with a parallel job group using the given deployment store.
+func testExecuteParallelJob(t *testing.T, tx DeploymentStore) {
+	store := NewStore(&storeTestData{})
+
+	td := getTestData(t)
+	service := newTestService(t)
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	// Jobs in the group wait for each other to start to check that they run concurrently
+	var started sync.WaitGroup
+	started.Add(2)
+	waitForGroup := func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
+		started.Done()
+
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return value, nil
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("jobs in group did not run concurrently")
+		}
+	}
+
+	branch1 := createJobGroupTestJob("branch_1", 1)
+	branch1.PreHooks = []JobFunc{waitForGroup}
+	branch2 := createJobGroupTestJob("branch_2", 10)
+	branch2.PreHooks = []JobFunc{waitForGroup}
+
+	group := NewParallelJob("group", Jobs{branch1, branch2}, sumJobGroupTestOutputs).Extend(Job{
+		InputType:  GetJobDataType(&JobGroupTestStruct{}),
+		OutputType: GetJobDataType(&JobGroupTestStruct{}),
+	})
+
+	var result *JobGroupTestStruct
+	action, err := NewAction(Jobs{
+		group,
+		{
+			Name: "result",
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
+				result = value.(*JobGroupTestStruct)
+				return value, nil
+			},
+			InputType:  GetJobDataType(&JobGroupTestStruct{}),
+			OutputType: GetJobDataType(&JobGroupTestStruct{}),
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+	}
+	require.NoError(t, service.Execute(context.Background(), store, tx, executeInput, &JobGroupTestStruct{Value: 1}))
+
+	// Outputs should have been merged
+	require.NotNil(t, result)
+	require.Equal(t, 13, result.Value)
+
+	// Inputs and outputs for each job in the group should have been persisted
+	deployment := executeInput.getDeployment()
+	for name, expected := range map[string]int{"branch_1": 2, "branch_2": 11} {
+		out, err := deployment.GetJobData(tx, &name, DeploymentJobOutput)
+		require.NoError(t, err)
+		require.Equal(t, expected, out.(*JobGroupTestStruct).Value)
+
+		in, err := deployment.GetJobData(tx, &name, DeploymentJobInput)
+		require.NoError(t, err)
+		require.Equal(t, 1, in.(*JobGroupTestStruct).Value)
+	}
+
+	// The deployment current job should not have been modified by the jobs in the group
+	stored, err := getDeployment(tx, &deployment.UUID)
+	require.NoError(t, err)
+	require.Equal(t, "result", stored.CurrentJob)
+}
+
+func TestExecuteParallelJobResume(t *testing.T) {
+	store := NewStore(&storeTestData{})
+	tx := NewMemoryDeploymentStore()
+
+	td := getTestData(t)
+	service := newTestService(t)
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	// The first job in the group finished in a previous execution and should not run again
+	branch1 := createJobGroupTestJob("branch_1", 1)
+	branch1.Execute = func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
+		t.Log("Finished job in group was run again.")
+		t.Fail()
+		return value, nil
+	}
+	branch2 := createJobGroupTestJob("branch_2", 10)
+
+	group := NewParallelJob("group", Jobs{branch1, branch2}, sumJobGroupTestOutputs).Extend(Job{
+		InputType:  GetJobDataType(&JobGroupTestStruct{}),
+		OutputType: GetJobDataType(&JobGroupTestStruct{}),
+	})
+	action, err := NewAction(Jobs{createJobGroupTestJob("first", 0), group})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	// Create a deployment that was interrupted while running the group
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+	}
+	require.NoError(t, executeInput.initialize(tx, action, nil))
+	deployment := executeInput.getDeployment()
+	require.NoError(t, deployment.setJob(tx, "group", &JobGroupTestStruct{Value: 1}))
+	branch1Name := "branch_1"
+	require.NoError(t, deployment.SetJobData(tx, &branch1Name, DeploymentJobInput, &JobGroupTestStruct{Value: 1}))
+	require.NoError(t, deployment.SetJobData(tx, &branch1Name, DeploymentJobOutput, &JobGroupTestStruct{Value: 2}))
+
+	// Resume the deployment
+	restored, err := getDeployment(tx, &deployment.UUID)
+	require.NoError(t, err)
+	resumeInput := &ExecuteInput{
+		ActionName: td.actionName,
+		Deployment: restored,
+	}
+	require.NoError(t, service.Execute(context.Background(), store, tx, resumeInput, nil))
+
+	// The unfinished job should have run
+	branch2Name := "branch_2"
+	out, err := restored.GetJobData(tx, &branch2Name, DeploymentJobOutput)
+	require.NoError(t, err)
+	require.Equal(t, 11, out.(*JobGroupTestStruct).Value)
+}
+
+func TestExecuteParallelJobRollback(t *testing.T) {
+	store := NewStore(&storeTestData{})
+	tx := NewMemoryDeploymentStore()
+
+	td := getTestData(t)
+	service := newTestService(t)
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var mutex sync.Mutex
+	rollbackCalls := make(map[string]int)
+	rollbackValues := make(map[string]interface{})
+	rollbackHandler := func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{},
+		err error) (interface{}, error) {
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		rollbackCalls[deployment.CurrentJob]++
+		rollbackValues[deployment.CurrentJob] = value
+
+		return nil, nil
+	}
+
+	branch1 := createJobGroupTestJob("branch_1", 1)
+	branch1.RollbackHandler = rollbackHandler
+	branch2 := createJobGroupTestJob("branch_2", 10)
+	branch2.Execute = func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
+		return nil, assert.AnError
+	}
+	branch2.RollbackHandler = rollbackHandler
+
+	group := NewParallelJob("group", Jobs{branch1, branch2}, sumJobGroupTestOutputs).Extend(Job{
+		InputType:  GetJobDataType(&JobGroupTestStruct{}),
+		OutputType: GetJobDataType(&JobGroupTestStruct{}),
+	})
+	action, err := NewAction(Jobs{group})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+	}
+	err = service.Execute(context.Background(), store, tx, executeInput, &JobGroupTestStruct{Value: 1})
+	require.True(t, errors.Is(err, ErrJobGroupFailed))
+
+	// Rollback handlers for all started jobs should have been called
+	require.Equal(t, map[string]int{"branch_1": 1, "branch_2": 1}, rollbackCalls)
+
+	// Rollback handlers should have received the input of their job
+	for _, job := range []string{"branch_1", "branch_2"} {
+		require.Equal(t, &JobGroupTestStruct{Value: 1}, rollbackValues[job], job)
+	}
+
+	// The failed job error should have been stored for the job in the group
+	deployment := executeInput.getDeployment()
+	branch2Name := "branch_2"
+	errs, err := deployment.GetErrors(tx, &branch2Name)
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+}
@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/pkg/errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	// DeploymentJobAttempts entries contain the number of failed attempts of a job with a RetryPolicy.
+	// This data is used to keep track of retries across deployment restarts.
+	DeploymentJobAttempts = DeploymentDataType("attempts")
+)
+
+// RetryableFunc is the function signature used to determine if an error returned by a job can be retried.
+type RetryableFunc func(err error) bool
+
+// RetryPolicy defines how a job is retried when it fails.
+//
+// When a job with a RetryPolicy returns an error, the error is checked with Retryable. If the error is retryable and
+// the job has not reached MaxAttempts, the error is recorded as a deployment error and the job is run again in place
+// after waiting a backoff period. The number of failed attempts is persisted in the deployment, so an interrupted
+// deployment resumes with the attempts it had left. The number of failed attempts is reset once the job succeeds. Once the job runs out of attempts or returns an error that is not
+// retryable, the error is returned and the action is rolled back as usual.
+//
+// The backoff period grows exponentially with each failed attempt, starting at InitialBackoff and being multiplied by
+// Multiplier after every attempt, up to MaxBackoff. Jitter randomizes each backoff period to avoid multiple
+// deployments retrying in lockstep.
+//
+// Retries stop if the context passed to the job is cancelled while waiting for the next attempt.
+//
+// Jobs are run again in full, including their pre-hooks and post-hooks. Job functions used with a RetryPolicy must be
+// able to run more than once for the same deployment (e.g. by releasing resources claimed by a previous attempt).
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the job is run, including the first attempt.
+	MaxAttempts int `validate:"min=1"`
+	// InitialBackoff is the time waited before the first retry.
+	InitialBackoff time.Duration `validate:"min=0"`
+	// MaxBackoff is the maximum time waited between attempts.
+	// If zero, backoff periods are not capped.
+	MaxBackoff time.Duration `validate:"min=0"`
+	// Multiplier is the factor the backoff period is multiplied by after every failed attempt.
+	// If zero, a multiplier of 2 is used.
+	Multiplier float64 `validate:"omitempty,min=1"`
+	// Jitter is the maximum fraction of the backoff period that is randomly added or subtracted from it.
+	// It must be a value between 0 and 1.
+	Jitter float64 `validate:"min=0,max=1"`
+	// Retryable determines if an error can be retried.
+	// If nil, only errors wrapping machines.ErrRetryable are retried.
+	Retryable RetryableFunc
+}
+
+// isRetryable checks if an error can be retried.
+func (p *RetryPolicy) isRetryable(err error) bool {
+	if p.Retryable == nil {
+		return machines.ErrorIsRetryable(err)
+	}
+
+	return p.Retryable(err)
+}
+
+// shouldRetry checks if a job should be run again after failing `attempts` times with `err`.
+func (p *RetryPolicy) shouldRetry(err error, attempts int) bool {
+	return attempts < p.MaxAttempts && p.isRetryable(err)
+}
+
+// backoff returns the time to wait before running a job again after failing `attempts` times.
+func (p *RetryPolicy) backoff(attempts int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempts-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	// Add or subtract a random fraction of the backoff period
+	backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+
+	return time.Duration(backoff)
+}
+
+// getJobAttempts returns the number of failed attempts persisted for the current job of a deployment.
+func getJobAttempts(tx DeploymentStore, deployment *Deployment) (int, error) {
+	var attempts int
+	err := deployment.GetJobDataOutValue(tx, nil, DeploymentJobAttempts, &attempts)
+	if errors.Is(err, ErrDeploymentDataNotFound) || errors.Is(err, ErrDeploymentDataNoData) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return attempts, nil
+}
+
+// runWithRetryPolicy runs a job and retries it according to its RetryPolicy.
+func (j *Job) runWithRetryPolicy(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value interface{}) (interface{}, error) {
+
+	// Restore the number of failed attempts from a previous execution
+	attempts, err := getJobAttempts(tx, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		out, err := j.run(ctx, store, tx, deployment, value)
+		if err == nil {
+			// Reset the failed attempts so later runs of the job start with all of its attempts
+			if attempts > 0 {
+				if err := deployment.SetJobData(tx, nil, DeploymentJobAttempts, 0); err != nil {
+					return nil, err
+				}
+			}
+			return out, nil
+		}
+
+		attempts++
+		if !j.RetryPolicy.shouldRetry(err, attempts) {
+			return nil, err
+		}
+
+		// Record the failed attempt
+		if err := deployment.SetJobData(tx, nil, DeploymentJobAttempts, attempts); err != nil {
+			return nil, err
+		}
+		attemptErr := fmt.Errorf("attempt %d/%d failed: %s", attempts, j.RetryPolicy.MaxAttempts, err.Error())
+		if err := deployment.addJobError(tx, nil, attemptErr); err != nil {
+			return nil, err
+		}
+
+		// Wait before retrying. Stop retrying if the context is cancelled (e.g. the deployment is stopped).
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(j.RetryPolicy.backoff(attempts)):
+		}
+	}
+}
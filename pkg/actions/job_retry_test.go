@@ -0,0 +1,213 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// createRetryTestJob creates a job that fails with `err` the first `failures` times it is run.
+// The number of times the job was run is stored in `calls`.
+func createRetryTestJob(name string, failures int, err error, calls *int, policy *RetryPolicy) *Job {
+	return &Job{
+		Name: name,
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
+			*calls++
+			if *calls <= failures {
+				return nil, err
+			}
+			return value, nil
+		},
+		RetryPolicy: policy,
+		InputType:   NilJobDataType,
+		OutputType:  NilJobDataType,
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+	}
+
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 2*time.Second, policy.backoff(2))
+	assert.Equal(t, 4*time.Second, policy.backoff(3))
+	assert.Equal(t, 5*time.Second, policy.backoff(4))
+
+	policy.Multiplier = 3
+	assert.Equal(t, 3*time.Second, policy.backoff(2))
+
+	// Jitter keeps the backoff within the configured range
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.backoff(1)
+		assert.GreaterOrEqual(t, int64(backoff), int64(500*time.Millisecond))
+		assert.LessOrEqual(t, int64(backoff), int64(1500*time.Millisecond))
+	}
+}
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	// By default only errors marked as retryable by machines are retried
+	policy := &RetryPolicy{MaxAttempts: 2}
+	assert.True(t, policy.isRetryable(machines.WrapRetryableError(machines.ErrInsufficientMachines)))
+	assert.False(t, policy.isRetryable(machines.ErrInsufficientMachines))
+
+	// Custom retryable functions
+	policy.Retryable = func(err error) bool {
+		return errors.Is(err, assert.AnError)
+	}
+	assert.True(t, policy.isRetryable(assert.AnError))
+	assert.False(t, policy.isRetryable(machines.WrapRetryableError(machines.ErrInsufficientMachines)))
+
+	// Errors are not retried once the job is out of attempts
+	assert.True(t, policy.shouldRetry(assert.AnError, 1))
+	assert.False(t, policy.shouldRetry(assert.AnError, 2))
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	calls := 0
+
+	_, err := NewAction(Jobs{createRetryTestJob("job", 0, nil, &calls, &RetryPolicy{MaxAttempts: 0})})
+	assert.Error(t, err)
+
+	_, err = NewAction(Jobs{createRetryTestJob("job", 0, nil, &calls, &RetryPolicy{MaxAttempts: 1, Jitter: 2})})
+	assert.Error(t, err)
+
+	_, err = NewAction(Jobs{createRetryTestJob("job", 0, nil, &calls, &RetryPolicy{MaxAttempts: 3, Jitter: 0.5})})
+	assert.NoError(t, err)
+}
+
+func TestJobRunRetries(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	calls := 0
+	retryableErr := machines.WrapRetryableError(machines.ErrRequestsLimitExceeded)
+	job := createRetryTestJob("job", 2, retryableErr, &calls, &RetryPolicy{MaxAttempts: 3})
+
+	// The job succeeds on the third attempt
+	_, err := job.Run(context.Background(), nil, tx, deployment, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// Failed attempts are recorded
+	errs, err := deployment.GetErrors(tx, nil)
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	assert.Contains(t, *errs[0].Error, "attempt 1/3 failed")
+	assert.Contains(t, *errs[1].Error, "attempt 2/3 failed")
+
+	// The failed attempts are reset once the job succeeds
+	attempts, err := getJobAttempts(tx, deployment)
+	require.NoError(t, err)
+	assert.Equal(t, 0, attempts)
+}
+
+func TestJobRunRetriesExhausted(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	calls := 0
+	retryableErr := machines.WrapRetryableError(machines.ErrInsufficientMachines)
+	job := createRetryTestJob("job", 5, retryableErr, &calls, &RetryPolicy{MaxAttempts: 3})
+
+	_, err := job.Run(context.Background(), nil, tx, deployment, nil)
+	require.True(t, errors.Is(err, machines.ErrRetryable))
+	assert.Equal(t, 3, calls)
+}
+
+func TestJobRunRetriesNonRetryableError(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	calls := 0
+	job := createRetryTestJob("job", 5, assert.AnError, &calls, &RetryPolicy{MaxAttempts: 3})
+
+	_, err := job.Run(context.Background(), nil, tx, deployment, nil)
+	require.True(t, errors.Is(err, assert.AnError))
+	assert.Equal(t, 1, calls)
+
+	// No attempts are recorded for non retryable errors
+	errs, err := deployment.GetErrors(tx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+}
+
+func TestJobRunRetriesResume(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	// The job failed twice in a previous execution
+	require.NoError(t, deployment.SetJobData(tx, nil, DeploymentJobAttempts, 2))
+
+	calls := 0
+	retryableErr := machines.WrapRetryableError(machines.ErrInsufficientMachines)
+	job := createRetryTestJob("job", 5, retryableErr, &calls, &RetryPolicy{MaxAttempts: 3})
+
+	// The job only has a single attempt left
+	_, err := job.Run(context.Background(), nil, tx, deployment, nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestExecuteJobRetries(t *testing.T) {
+	td := getTestData(t)
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	calls := 0
+	retryableErr := machines.WrapRetryableError(machines.ErrInsufficientMachines)
+	job := createRetryTestJob("job", 1, retryableErr, &calls, &RetryPolicy{MaxAttempts: 2})
+	rollback := false
+	job.RollbackHandler = func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{},
+		err error) (interface{}, error) {
+
+		rollback = true
+		return nil, nil
+	}
+
+	action, err := NewAction(Jobs{job})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+		GroupID:    "test",
+	}
+	require.NoError(t, service.Execute(context.Background(), nil, tx, executeInput, nil))
+
+	// The job was retried in place instead of rolling back the action
+	assert.Equal(t, 2, calls)
+	assert.False(t, rollback)
+}
+
+func TestJobRunRetriesCancelled(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	calls := 0
+	retryableErr := machines.WrapRetryableError(machines.ErrInsufficientMachines)
+	job := createRetryTestJob("job", 5, retryableErr, &calls, &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour})
+
+	// Retries stop when the context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := job.Run(ctx, nil, tx, deployment, nil)
+	require.True(t, errors.Is(err, machines.ErrRetryable))
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,50 @@
+package actions
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	// DeploymentJobSkipped entries contain whether a job with a SkipIf predicate was skipped.
+	// This data is used to avoid rolling back jobs that were not run.
+	DeploymentJobSkipped = DeploymentDataType("skipped")
+)
+
+// JobSkipFunc is the function signature used to determine if a job should be skipped.
+// It receives the store and the input value of the job.
+type JobSkipFunc func(store Store, value interface{}) (bool, error)
+
+// shouldSkip evaluates the SkipIf predicate of a job and records the result in the deployment.
+// Jobs without a SkipIf predicate are never skipped.
+func (j *Job) shouldSkip(store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (bool, error) {
+	if j.SkipIf == nil {
+		return false, nil
+	}
+
+	skip, err := j.SkipIf(store, value)
+	if err != nil {
+		return false, err
+	}
+
+	// The result is always recorded, as a resumed deployment may reevaluate the predicate with a different result
+	if err := deployment.SetJobData(tx, nil, DeploymentJobSkipped, skip); err != nil {
+		return false, err
+	}
+
+	return skip, nil
+}
+
+// isJobSkipped checks if a job was skipped by a deployment.
+// If `job` is nil, the current job of the deployment is checked.
+func isJobSkipped(tx DeploymentStore, deployment *Deployment, job *string) (bool, error) {
+	var skipped bool
+	err := deployment.GetJobDataOutValue(tx, job, DeploymentJobSkipped, &skipped)
+	if errors.Is(err, ErrDeploymentDataNotFound) || errors.Is(err, ErrDeploymentDataNoData) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return skipped, nil
+}
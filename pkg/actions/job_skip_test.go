@@ -0,0 +1,148 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+// skipTestCalls keeps track of the job functions called in skip tests.
+type skipTestCalls struct {
+	sync.Mutex
+	calls []string
+}
+
+func (c *skipTestCalls) add(call string) {
+	c.Lock()
+	defer c.Unlock()
+	c.calls = append(c.calls, call)
+}
+
+// createSkipTestJob creates a job that records its execution and rollback in `calls`.
+// The job is skipped if `skip` is true, and fails with `err` if it is run.
+func createSkipTestJob(name string, skip bool, err error, calls *skipTestCalls) *Job {
+	return &Job{
+		Name: name,
+		SkipIf: func(store Store, value interface{}) (bool, error) {
+			return skip, nil
+		},
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}) (interface{}, error) {
+
+			calls.add(name)
+			if err != nil {
+				return nil, err
+			}
+			return "out_" + name, nil
+		},
+		RollbackHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}, err error) (interface{}, error) {
+
+			calls.add("rollback " + name)
+			return nil, nil
+		},
+		InputType:  GetJobDataType(""),
+		OutputType: GetJobDataType(""),
+	}
+}
+
+func TestJobRunSkip(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	calls := &skipTestCalls{}
+
+	// Skipped jobs pass through their input
+	out, err := createSkipTestJob("job", true, nil, calls).Run(context.Background(), nil, tx, deployment, "in")
+	require.NoError(t, err)
+	assert.Equal(t, "in", out)
+	assert.Empty(t, calls.calls)
+
+	skipped, err := isJobSkipped(tx, deployment, nil)
+	require.NoError(t, err)
+	assert.True(t, skipped)
+
+	// The predicate result is updated if the job is run again
+	out, err = createSkipTestJob("job", false, nil, calls).Run(context.Background(), nil, tx, deployment, "in")
+	require.NoError(t, err)
+	assert.Equal(t, "out_job", out)
+	assert.Equal(t, []string{"job"}, calls.calls)
+
+	skipped, err = isJobSkipped(tx, deployment, nil)
+	require.NoError(t, err)
+	assert.False(t, skipped)
+}
+
+func TestJobRunSkipError(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	calls := &skipTestCalls{}
+	job := createSkipTestJob("job", false, nil, calls)
+	job.SkipIf = func(store Store, value interface{}) (bool, error) {
+		return false, assert.AnError
+	}
+
+	_, err := job.Run(context.Background(), nil, tx, deployment, "in")
+	require.True(t, errors.Is(err, assert.AnError))
+	assert.Empty(t, calls.calls)
+}
+
+func TestExecuteSkippedJobsAreNotRolledBack(t *testing.T) {
+	td := getTestData(t)
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	calls := &skipTestCalls{}
+	action, err := NewAction(Jobs{
+		createSkipTestJob("job1", false, nil, calls),
+		createSkipTestJob("job2", true, nil, calls),
+		NewParallelJob("group", Jobs{
+			createSkipTestJob("group1", true, nil, calls),
+			createSkipTestJob("group2", false, nil, calls),
+		}, nil),
+		createSkipTestJob("job3", false, assert.AnError, calls),
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+		GroupID:    "test",
+	}
+	err = service.Execute(context.Background(), nil, tx, executeInput, "in")
+	require.True(t, errors.Is(err, assert.AnError))
+
+	assert.Equal(t, []string{
+		"job1",
+		"group2",
+		"job3",
+		"rollback job3",
+		"rollback group2",
+		"rollback job1",
+	}, calls.calls)
+
+	// Skipped jobs are recorded in the deployment timeline
+	details, err := GetDeploymentDetails(tx, "test")
+	require.NoError(t, err)
+	skipped := make(map[string]bool)
+	for _, record := range details.Timeline {
+		skipped[record.Job] = record.Skipped
+	}
+	assert.Equal(t, map[string]bool{
+		"job1":   false,
+		"job2":   true,
+		"group":  false,
+		"group1": true,
+		"group2": false,
+		"job3":   false,
+	}, skipped)
+}
@@ -1,8 +1,8 @@
 package actions
 
 import (
+	"context"
 	"fmt"
-	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,14 +14,14 @@ func TestDefaultExecutePanics(t *testing.T) {
 	j := &Job{}
 
 	// The default job should panic
-	assert.Panics(t, func() { _, _ = j.Execute(nil, nil, nil, struct{}{}) })
+	assert.Panics(t, func() { _, _ = j.Execute(context.Background(), nil, nil, nil, struct{}{}) })
 }
 
 func TestDefaultRunPanics(t *testing.T) {
 	j := &Job{}
 
 	// The default job should panic
-	assert.Panics(t, func() { _, _ = j.Run(nil, nil, nil, struct{}{}) })
+	assert.Panics(t, func() { _, _ = j.Run(context.Background(), nil, nil, nil, struct{}{}) })
 }
 
 func TestRegisterTypes(t *testing.T) {
@@ -60,18 +60,18 @@ func TestProcessHooksPass(t *testing.T) {
 
 	// TestResource hooks
 	j.PreHooks = []JobFunc{
-		func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return value.(int) + 1, nil
 		},
-		func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return value.(int) + 2, nil
 		},
-		func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return value.(int) + 3, nil
 		},
 	}
 
-	value, err := j.processHooks(nil, nil, nil, 0, &j.PreHooks)
+	value, err := j.processHooks(context.Background(), nil, nil, nil, 0, &j.PreHooks)
 	assert.NoError(t, err)
 	assert.Equal(t, value, 6)
 }
@@ -81,32 +81,32 @@ func TestProcessHooksFail(t *testing.T) {
 
 	// Test hooks
 	j.PreHooks = []JobFunc{
-		func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return value.(int) + 1, nil
 		},
-		func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return value.(int) + 2, assert.AnError
 		},
-		func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return value.(int) + 3, nil
 		},
 	}
 
-	value, err := j.processHooks(nil, nil, nil, 0, &j.PreHooks)
+	value, err := j.processHooks(context.Background(), nil, nil, nil, 0, &j.PreHooks)
 	assert.Nil(t, value)
 	assert.EqualError(t, err, assert.AnError.Error())
 }
 
 func TestCallJobFunc(t *testing.T) {
-	valueFunc := func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+	valueFunc := func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 		return value, nil
 	}
-	nilFunc := func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+	nilFunc := func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 		return nil, nil
 	}
 
 	test := func(jobFunc JobFunc, value interface{}, error bool) {
-		_, err := callJobFunc(jobFunc, nil, nil, nil, value)
+		_, err := callJobFunc(context.Background(), jobFunc, nil, nil, nil, value)
 		if error {
 			require.Error(t, err)
 		} else {
@@ -121,10 +121,10 @@ func TestCallJobFunc(t *testing.T) {
 }
 
 func TestTestJobExecute(t *testing.T) {
-	valueFunc := func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+	valueFunc := func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 		return value, nil
 	}
-	nilFunc := func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+	nilFunc := func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 		return nil, nil
 	}
 
@@ -139,7 +139,7 @@ func TestTestJobExecute(t *testing.T) {
 			j.PostHooks = []JobFunc{postHook}
 		}
 
-		value, err := j.Execute(nil, nil, nil, value)
+		value, err := j.Execute(context.Background(), nil, nil, nil, value)
 
 		if error {
 			assert.NoError(t, err)
@@ -161,11 +161,11 @@ func TestTestJobRun(t *testing.T) {
 	j := &Job{
 		PreHooks: []JobFunc{
 			// Multiply the input value by two
-			func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 				return value.(int) * 2, nil
 			},
 			// Check that the input value is now two times val
-			func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 				var err error
 				if value.(int) != val*2 {
 					err = assert.AnError
@@ -174,7 +174,7 @@ func TestTestJobRun(t *testing.T) {
 			},
 		},
 		// Check that the input value is two times val
-		Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			var err error
 			if value.(int) != val*2 {
 				err = assert.AnError
@@ -184,7 +184,7 @@ func TestTestJobRun(t *testing.T) {
 		// Divide output value by two
 		PostHooks: []JobFunc{
 			// Check that the output value is two times val
-			func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 				var err error
 				if value.(int) != val*2 {
 					err = assert.AnError
@@ -192,11 +192,11 @@ func TestTestJobRun(t *testing.T) {
 				return value, err
 			},
 			// Divide the output value by two
-			func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 				return value.(int) / 2, nil
 			},
 			// Check that the output value is now val
-			func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 				var err error
 				if value.(int) != val {
 					err = assert.AnError
@@ -206,7 +206,7 @@ func TestTestJobRun(t *testing.T) {
 		},
 	}
 
-	value, err := j.Run(nil, nil, nil, val)
+	value, err := j.Run(context.Background(), nil, nil, nil, val)
 
 	// The test job should not return an error and should return the same
 	// value it receives
@@ -305,7 +305,7 @@ func TestJobsValidate(t *testing.T) {
 	data := "test"
 	job := &Job{
 		Name: "test",
-		Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return nil, nil
 		},
 		// Nil type input
@@ -341,7 +341,7 @@ func TestJobsNoRepeatedJobNames(t *testing.T) {
 	// Prepare a valid job
 	job := &Job{
 		Name: "test",
-		Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return nil, nil
 		},
 		// Nil type input
@@ -363,7 +363,7 @@ func TestJobValidate(t *testing.T) {
 	data := "test"
 	job := &Job{
 		Name: "test",
-		Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return nil, nil
 		},
 		// Nil type input
@@ -377,10 +377,10 @@ func TestExtendJob(t *testing.T) {
 	jobName := "test_job"
 	jobVar := &Job{
 		Name: jobName,
-		Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return jobName, nil
 		},
-		RollbackHandler: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}, err error) (interface{}, error) {
+		RollbackHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}, err error) (interface{}, error) {
 			return jobName, nil
 		},
 	}
@@ -393,7 +393,7 @@ func TestExtendJob(t *testing.T) {
 
 	require.Panics(t, func() {
 		extension := Job{
-			Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 				return true, nil
 			},
 		}
@@ -401,10 +401,10 @@ func TestExtendJob(t *testing.T) {
 	})
 
 	// Create the extension
-	hook := func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+	hook := func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 		return nil, nil
 	}
-	rollback := JobErrorHandler(func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}, err error) (interface{}, error) {
+	rollback := JobErrorHandler(func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}, err error) (interface{}, error) {
 		return fmt.Sprintf("%s-test", jobName), nil
 	})
 	extendedJob := jobVar.Extend(Job{
@@ -0,0 +1,178 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+var (
+	// ErrJobInvalidValue is returned when a typed job receives a value of an unexpected type.
+	ErrJobInvalidValue = errors.New("job received a value of an unexpected type")
+)
+
+// TypedJobFunc is the function signature used by the Execute function of typed jobs.
+type TypedJobFunc[I any, O any] func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	input I) (O, error)
+
+// TypedHookFunc is the function signature used by typed job hooks.
+// Hooks receive a value and return a value of the same type.
+type TypedHookFunc[T any] func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	value T) (T, error)
+
+// TypedRollbackFunc is the function signature used by typed job rollback handlers.
+// `input` contains the input received by the job, restored from the deployment. It contains the zero value if the
+// job did not persist its input (e.g. it received a nil value).
+type TypedRollbackFunc[I any] func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+	input I, err error) error
+
+// TypedSkipFunc is the function signature used by typed job skip predicates.
+type TypedSkipFunc[I any] func(store Store, input I) (bool, error)
+
+// TypedJob defines a job whose input and output values have a specific type.
+// Typed jobs are converted to regular jobs by calling NewJob.
+type TypedJob[I any, O any] struct {
+	// Name contains the name of the Job.
+	Name string
+	// PreHooks contains the hooks ran before the Execute function.
+	PreHooks []TypedHookFunc[I]
+	// Execute performs the job's action.
+	Execute TypedJobFunc[I, O]
+	// PostHooks contains the hooks ran after the Execute function.
+	PostHooks []TypedHookFunc[O]
+	// RollbackHandler contains the rollback function for the job.
+	RollbackHandler TypedRollbackFunc[I]
+	// RetryPolicy contains the optional retry policy for the job.
+	RetryPolicy *RetryPolicy
+	// SkipIf contains the optional predicate used to skip the job.
+	// Skipped jobs return the input value they received, so conditional jobs should usually have the same input and
+	// output types.
+	SkipIf TypedSkipFunc[I]
+}
+
+// NewJob creates a job from a typed job definition.
+//
+// The InputType and OutputType of the job are derived from I and O, and registered in the job data type registry.
+// Values received by the job functions are checked before calling them. If the previous job in the action (or a
+// hook added by calling Job.Extend) returns a value that does not match the expected type, the job returns an error
+// wrapping ErrJobInvalidValue instead of panicking. A nil value is converted to the zero value of types that can be
+// nil (e.g. pointers, slices and maps).
+//
+// The returned job is a regular job, and can be customized by calling Job.Extend.
+func NewJob[I any, O any](definition TypedJob[I, O]) *Job {
+	job := &Job{
+		Name:        definition.Name,
+		RetryPolicy: definition.RetryPolicy,
+		InputType:   typeOf[I](),
+		OutputType:  typeOf[O](),
+	}
+
+	for _, hook := range definition.PreHooks {
+		job.PreHooks = append(job.PreHooks, wrapTypedHookFunc(hook))
+	}
+
+	if definition.Execute != nil {
+		execute := definition.Execute
+		job.Execute = func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}) (interface{}, error) {
+
+			input, err := convertJobValue[I](deployment.CurrentJob, value)
+			if err != nil {
+				return nil, err
+			}
+
+			return execute(ctx, store, tx, deployment, input)
+		}
+	}
+
+	for _, hook := range definition.PostHooks {
+		job.PostHooks = append(job.PostHooks, wrapTypedHookFunc(hook))
+	}
+
+	if definition.RollbackHandler != nil {
+		rollback := definition.RollbackHandler
+		job.RollbackHandler = func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}, err error) (interface{}, error) {
+
+			input, inputErr := getTypedJobInput[I](tx, deployment)
+			if inputErr != nil {
+				return nil, inputErr
+			}
+
+			return nil, rollback(ctx, store, tx, deployment, input, err)
+		}
+	}
+
+	if definition.SkipIf != nil {
+		skip := definition.SkipIf
+		job.SkipIf = func(store Store, value interface{}) (bool, error) {
+			input, err := convertJobValue[I](definition.Name, value)
+			if err != nil {
+				return false, err
+			}
+
+			return skip(store, input)
+		}
+	}
+
+	job.registerTypes(jobDataTypeRegistry)
+
+	return job
+}
+
+// typeOf returns the job data type of T.
+func typeOf[T any]() JobDataType {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// wrapTypedHookFunc converts a typed hook into a JobFunc.
+func wrapTypedHookFunc[T any](hook TypedHookFunc[T]) JobFunc {
+	return func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+		value interface{}) (interface{}, error) {
+
+		typed, err := convertJobValue[T](deployment.CurrentJob, value)
+		if err != nil {
+			return nil, err
+		}
+
+		return hook(ctx, store, tx, deployment, typed)
+	}
+}
+
+// convertJobValue converts a value received by a job to T.
+// `job` contains the name of the job, and is used to identify the job in the returned error.
+func convertJobValue[T any](job string, value interface{}) (T, error) {
+	var zero T
+
+	if value == nil {
+		switch typeOf[T]().Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return zero, nil
+		}
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		return zero, errors.Wrap(ErrJobInvalidValue, fmt.Sprintf("job [%s] expected a value of type [%s], got [%T]",
+			job, typeOf[T](), value))
+	}
+
+	return typed, nil
+}
+
+// getTypedJobInput returns the input of the current job of a deployment.
+// The zero value is returned if the job did not persist its input.
+func getTypedJobInput[I any](tx DeploymentStore, deployment *Deployment) (I, error) {
+	var zero I
+
+	value, err := deployment.GetJobData(tx, nil, DeploymentJobInput)
+	if errors.Is(err, ErrDeploymentDataNotFound) || errors.Is(err, ErrDeploymentDataNoData) {
+		return zero, nil
+	}
+	if err != nil {
+		return zero, err
+	}
+
+	return convertJobValue[I](deployment.CurrentJob, value)
+}
@@ -0,0 +1,179 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// typedTestInput is the input of typed test jobs.
+type typedTestInput struct {
+	Value string
+}
+
+// typedTestOutput is the output of typed test jobs.
+type typedTestOutput struct {
+	Length int
+}
+
+// createTypedTestJob creates a typed job that returns the length of its input value.
+func createTypedTestJob(name string) *Job {
+	return NewJob(TypedJob[typedTestInput, *typedTestOutput]{
+		Name: name,
+		PreHooks: []TypedHookFunc[typedTestInput]{
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value typedTestInput) (typedTestInput, error) {
+
+				value.Value = strings.TrimSpace(value.Value)
+				return value, nil
+			},
+		},
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			input typedTestInput) (*typedTestOutput, error) {
+
+			return &typedTestOutput{Length: len(input.Value)}, nil
+		},
+		PostHooks: []TypedHookFunc[*typedTestOutput]{
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value *typedTestOutput) (*typedTestOutput, error) {
+
+				value.Length *= 10
+				return value, nil
+			},
+		},
+	})
+}
+
+func TestNewJobDataTypes(t *testing.T) {
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	job := createTypedTestJob("job")
+	assert.Equal(t, reflect.TypeOf(typedTestInput{}), job.InputType)
+	assert.Equal(t, reflect.TypeOf(&typedTestOutput{}), job.OutputType)
+
+	// Types are registered
+	_, err := jobDataTypeRegistry.getType(GetJobDataTypeName(typedTestInput{}))
+	assert.NoError(t, err)
+	_, err = jobDataTypeRegistry.getType(GetJobDataTypeName(&typedTestOutput{}))
+	assert.NoError(t, err)
+
+	// Jobs are valid
+	_, err = NewAction(Jobs{job})
+	assert.NoError(t, err)
+}
+
+func TestNewJobRun(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	deployment := &Deployment{UUID: "test", CurrentJob: "job"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	job := createTypedTestJob("job")
+
+	out, err := job.Run(context.Background(), nil, tx, deployment, typedTestInput{Value: " test "})
+	require.NoError(t, err)
+	assert.Equal(t, &typedTestOutput{Length: 40}, out)
+
+	// Values of a different type return an error instead of panicking
+	_, err = job.Run(context.Background(), nil, tx, deployment, "test")
+	require.True(t, errors.Is(err, ErrJobInvalidValue))
+	assert.Contains(t, err.Error(), "job [job] expected a value of type [actions.typedTestInput], got [string]")
+
+	// Nil values are only accepted by types that can be nil
+	_, err = job.Run(context.Background(), nil, tx, deployment, nil)
+	require.True(t, errors.Is(err, ErrJobInvalidValue))
+
+	pointerJob := NewJob(TypedJob[*typedTestInput, string]{
+		Name: "pointer",
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			input *typedTestInput) (string, error) {
+
+			if input == nil {
+				return "nil", nil
+			}
+			return input.Value, nil
+		},
+	})
+	out, err = pointerJob.Run(context.Background(), nil, tx, deployment, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "nil", out)
+}
+
+func TestNewJobExtendedHooks(t *testing.T) {
+	tx := NewMemoryDeploymentStore()
+	deployment := &Deployment{UUID: "test", CurrentJob: "extended"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	// Hooks added by extending a typed job are checked by the typed job functions
+	job := createTypedTestJob("job").Extend(Job{
+		Name: "extended",
+		PreHooks: []JobFunc{
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value interface{}) (interface{}, error) {
+
+				return 1, nil
+			},
+		},
+	})
+
+	_, err := job.Run(context.Background(), nil, tx, deployment, typedTestInput{})
+	require.True(t, errors.Is(err, ErrJobInvalidValue))
+	assert.Contains(t, err.Error(), "job [extended]")
+}
+
+func TestNewJobRollbackAndSkip(t *testing.T) {
+	td := getTestData(t)
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var rollbackInput typedTestInput
+	var rollbackErr error
+	action, err := NewAction(Jobs{
+		NewJob(TypedJob[typedTestInput, typedTestInput]{
+			Name: "skipped",
+			SkipIf: func(store Store, input typedTestInput) (bool, error) {
+				return input.Value == "skip", nil
+			},
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				input typedTestInput) (typedTestInput, error) {
+
+				return typedTestInput{}, nil
+			},
+		}),
+		NewJob(TypedJob[typedTestInput, typedTestInput]{
+			Name: "fail",
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				input typedTestInput) (typedTestInput, error) {
+
+				return input, assert.AnError
+			},
+			RollbackHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				input typedTestInput, err error) error {
+
+				rollbackInput = input
+				rollbackErr = err
+				return nil
+			},
+		}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+		GroupID:    "test",
+	}
+	err = service.Execute(context.Background(), nil, tx, executeInput, typedTestInput{Value: "skip"})
+	require.True(t, errors.Is(err, assert.AnError))
+
+	// The rollback handler receives the input of the job restored from the deployment
+	assert.Equal(t, typedTestInput{Value: "skip"}, rollbackInput)
+	assert.True(t, errors.Is(rollbackErr, assert.AnError))
+}
@@ -0,0 +1,137 @@
+package actions
+
+import (
+	"time"
+)
+
+// Event is a lifecycle event emitted by the actions service while executing deployments.
+// Observers can use a type switch to handle specific events:
+//
+//	switch e := event.(type) {
+//	case *JobSucceededEvent:
+//		...
+//	case *JobFailedEvent:
+//		...
+//	}
+type Event interface {
+	// Info returns the information common to all events.
+	Info() EventInfo
+}
+
+// EventInfo contains the information common to all events.
+type EventInfo struct {
+	// DeploymentUUID contains the UUID of the deployment that emitted the event.
+	DeploymentUUID string
+	// Action contains the name of the action executed by the deployment.
+	Action string
+	// Time contains the time the event was emitted.
+	Time time.Time
+}
+
+// Info returns the information common to all events.
+func (e EventInfo) Info() EventInfo {
+	return e
+}
+
+// DeploymentStartedEvent is emitted when the service starts executing a deployment.
+type DeploymentStartedEvent struct {
+	EventInfo
+	// Resumed is true if the deployment was restored from a previous execution.
+	Resumed bool
+	// Status contains the status of the deployment when its execution started.
+	Status DeploymentStatus
+}
+
+// JobStartedEvent is emitted before running a job.
+type JobStartedEvent struct {
+	EventInfo
+	// Job contains the name of the job.
+	Job string
+	// Index contains the position of the job in the action.
+	Index int
+	// Total contains the number of jobs in the action.
+	Total int
+}
+
+// JobSucceededEvent is emitted after a job finishes running without errors.
+type JobSucceededEvent struct {
+	EventInfo
+	// Job contains the name of the job.
+	Job string
+	// Index contains the position of the job in the action.
+	Index int
+	// Total contains the number of jobs in the action.
+	Total int
+	// Duration contains the time it took to run the job.
+	Duration time.Duration
+}
+
+// JobFailedEvent is emitted after a job returns an error.
+type JobFailedEvent struct {
+	EventInfo
+	// Job contains the name of the job.
+	Job string
+	// Index contains the position of the job in the action.
+	Index int
+	// Total contains the number of jobs in the action.
+	Total int
+	// Duration contains the time it took to run the job.
+	Duration time.Duration
+	// Err contains the error returned by the job.
+	Err error
+}
+
+// RollbackStartedEvent is emitted when the service starts rolling back a deployment.
+type RollbackStartedEvent struct {
+	EventInfo
+	// Job contains the name of the job the rollback starts from.
+	Job string
+	// Err contains the error that triggered the rollback.
+	Err error
+}
+
+// RollbackHandlerFailedEvent is emitted when a job rollback handler returns an error.
+type RollbackHandlerFailedEvent struct {
+	EventInfo
+	// Job contains the name of the job whose rollback handler failed.
+	Job string
+	// Err contains the error returned by the rollback handler.
+	Err error
+}
+
+// DeploymentFinishedEvent is emitted when the service finishes executing a deployment.
+type DeploymentFinishedEvent struct {
+	EventInfo
+	// Duration contains the time the service spent executing the deployment.
+	// Resumed deployments only include the time spent since they were resumed.
+	Duration time.Duration
+	// RolledBack is true if the deployment was rolled back.
+	RolledBack bool
+	// Err contains the error returned by the execution, if any.
+	Err error
+}
+
+// Observer receives lifecycle events from the actions service.
+// Events are delivered synchronously in the goroutine executing the deployment, so observers should not block. Events
+// for different deployments can be delivered concurrently.
+type Observer interface {
+	// OnEvent is called every time an event is emitted.
+	OnEvent(event Event)
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as observers.
+type ObserverFunc func(event Event)
+
+// OnEvent calls f(event).
+func (f ObserverFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// newEventInfo creates the common event information for a deployment.
+func newEventInfo(deployment *Deployment) EventInfo {
+	return EventInfo{
+		DeploymentUUID: deployment.UUID,
+		Action:         deployment.Action,
+		Time:           time.Now(),
+	}
+}
@@ -0,0 +1,161 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+// recordingObserver is an observer that stores every event it receives.
+type recordingObserver struct {
+	sync.Mutex
+	events []Event
+}
+
+func (o *recordingObserver) OnEvent(event Event) {
+	o.Lock()
+	defer o.Unlock()
+	o.events = append(o.events, event)
+}
+
+// createObserverTestJob creates a job that returns `err`, and a rollback handler that returns `rollbackErr`.
+func createObserverTestJob(name string, err error, rollbackErr error) *Job {
+	return &Job{
+		Name: name,
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}) (interface{}, error) {
+
+			return nil, err
+		},
+		RollbackHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}, err error) (interface{}, error) {
+
+			return nil, rollbackErr
+		},
+		InputType:  NilJobDataType,
+		OutputType: NilJobDataType,
+	}
+}
+
+// executeObserverTestAction executes an action with the given jobs and returns the events received by an observer.
+func executeObserverTestAction(t *testing.T, jobs Jobs, observers ...Observer) ([]Event, error) {
+	td := getTestData(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	observer := &recordingObserver{}
+	observers = append(observers, observer)
+	service := NewService(gz.NewLoggerNoRollbar("Actions", gz.VerbosityDebug), observers...)
+
+	action, err := NewAction(jobs)
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+		GroupID:    "test",
+	}
+	err = service.Execute(context.Background(), nil, tx, executeInput, nil)
+
+	return observer.events, err
+}
+
+func TestObserverEvents(t *testing.T) {
+	td := getTestData(t)
+
+	events, err := executeObserverTestAction(t, Jobs{
+		createObserverTestJob("job1", nil, nil),
+		createObserverTestJob("job2", nil, nil),
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 6)
+
+	for _, event := range events {
+		assert.Equal(t, "test", event.Info().DeploymentUUID)
+		assert.Equal(t, td.actionName, event.Info().Action)
+		assert.False(t, event.Info().Time.IsZero())
+	}
+
+	started, ok := events[0].(*DeploymentStartedEvent)
+	require.True(t, ok)
+	assert.False(t, started.Resumed)
+
+	jobStarted, ok := events[1].(*JobStartedEvent)
+	require.True(t, ok)
+	assert.Equal(t, "job1", jobStarted.Job)
+	assert.Equal(t, 0, jobStarted.Index)
+	assert.Equal(t, 2, jobStarted.Total)
+
+	jobSucceeded, ok := events[2].(*JobSucceededEvent)
+	require.True(t, ok)
+	assert.Equal(t, "job1", jobSucceeded.Job)
+
+	jobStarted, ok = events[3].(*JobStartedEvent)
+	require.True(t, ok)
+	assert.Equal(t, "job2", jobStarted.Job)
+	assert.Equal(t, 1, jobStarted.Index)
+
+	jobSucceeded, ok = events[4].(*JobSucceededEvent)
+	require.True(t, ok)
+	assert.Equal(t, "job2", jobSucceeded.Job)
+
+	finished, ok := events[5].(*DeploymentFinishedEvent)
+	require.True(t, ok)
+	assert.False(t, finished.RolledBack)
+	assert.NoError(t, finished.Err)
+}
+
+func TestObserverRollbackEvents(t *testing.T) {
+	rollbackErr := errors.New("rollback error")
+
+	events, err := executeObserverTestAction(t, Jobs{
+		createObserverTestJob("job1", nil, rollbackErr),
+		createObserverTestJob("job2", assert.AnError, nil),
+	})
+	require.True(t, errors.Is(err, assert.AnError))
+	require.Len(t, events, 8)
+
+	assert.IsType(t, &DeploymentStartedEvent{}, events[0])
+	assert.IsType(t, &JobStartedEvent{}, events[1])
+	assert.IsType(t, &JobSucceededEvent{}, events[2])
+	assert.IsType(t, &JobStartedEvent{}, events[3])
+
+	jobFailed, ok := events[4].(*JobFailedEvent)
+	require.True(t, ok)
+	assert.Equal(t, "job2", jobFailed.Job)
+	assert.True(t, errors.Is(jobFailed.Err, assert.AnError))
+
+	rollbackStarted, ok := events[5].(*RollbackStartedEvent)
+	require.True(t, ok)
+	assert.Equal(t, "job2", rollbackStarted.Job)
+	assert.True(t, errors.Is(rollbackStarted.Err, assert.AnError))
+
+	handlerFailed, ok := events[6].(*RollbackHandlerFailedEvent)
+	require.True(t, ok)
+	assert.Equal(t, "job1", handlerFailed.Job)
+	assert.True(t, errors.Is(handlerFailed.Err, rollbackErr))
+
+	finished, ok := events[7].(*DeploymentFinishedEvent)
+	require.True(t, ok)
+	assert.True(t, finished.RolledBack)
+	assert.True(t, errors.Is(finished.Err, assert.AnError))
+}
+
+func TestObserverPanic(t *testing.T) {
+	panicObserver := ObserverFunc(func(event Event) {
+		panic("observer panic")
+	})
+
+	// Observer panics do not affect the deployment or other observers
+	events, err := executeObserverTestAction(t, Jobs{
+		createObserverTestJob("job", nil, nil),
+	}, panicObserver)
+	require.NoError(t, err)
+	assert.Len(t, events, 4)
+}
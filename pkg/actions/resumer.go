@@ -0,0 +1,179 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/gz-go/v7"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrResumerInvalidConcurrency is returned when a resumer is created with a concurrency lower than 1.
+	ErrResumerInvalidConcurrency = errors.New("resumer concurrency must be at least 1")
+)
+
+// StateFactory creates the state used to resume a deployment.
+// Deployments keep their job data in persistent storage, but the action Store state contains application-specific
+// dependencies (e.g. platforms, services) that need to be rebuilt before the deployment can be resumed.
+type StateFactory func(ctx context.Context, deployment *Deployment) (State, error)
+
+// ResumeResult contains the result of resuming a single deployment.
+type ResumeResult struct {
+	// UUID contains the UUID of the resumed deployment.
+	UUID string
+	// Action contains the name of the action the deployment executes.
+	Action string
+	// Status contains the status the deployment had when it was resumed.
+	Status DeploymentStatus
+	// Err contains the error returned when resuming the deployment.
+	// Deployments that were rolling back return the error that triggered the rollback, even if the rollback
+	// succeeded. Deployments being executed by another service instance return ErrDeploymentLeased.
+	Err error
+}
+
+// ResumeReport contains the results of resuming a set of interrupted deployments.
+type ResumeReport struct {
+	// Results contains the result for each deployment that was resumed, in the order they were created.
+	Results []ResumeResult
+}
+
+// Failed returns the results of deployments whose execution returned an error.
+// Deployments that were not resumed because they are leased by another service instance are not included.
+func (r *ResumeReport) Failed() []ResumeResult {
+	var failed []ResumeResult
+	for _, result := range r.Results {
+		if result.Err != nil && !errors.Is(result.Err, ErrDeploymentLeased) {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Resumer resumes deployments that were interrupted (e.g. due to a server restart).
+type Resumer interface {
+	// Resume finds every unfinished deployment and resumes it.
+	// Deployments that were running continue from the job they were running, and deployments that were rolling back
+	// continue their rollback. Resume blocks until all deployments have finished, and returns a report with the
+	// result of each one.
+	Resume(ctx context.Context, tx DeploymentStore) (*ResumeReport, error)
+}
+
+// resumer is a Resumer implementation.
+type resumer struct {
+	// service is the action service used to execute deployments.
+	service Servicer
+	// stateFactory is used to create the state for each deployment.
+	stateFactory StateFactory
+	// concurrency is the maximum number of deployments resumed at the same time.
+	concurrency int
+	// logger is used to log resumed deployments.
+	logger gz.Logger
+}
+
+// NewResumer returns a Resumer that resumes deployments using the actions registered in `service`.
+// `stateFactory` is called once per deployment to rebuild its Store. `concurrency` sets the maximum number of
+// deployments resumed at the same time.
+func NewResumer(service Servicer, stateFactory StateFactory, concurrency int, logger gz.Logger) (Resumer, error) {
+	if concurrency < 1 {
+		return nil, ErrResumerInvalidConcurrency
+	}
+
+	return &resumer{
+		service:      service,
+		stateFactory: stateFactory,
+		concurrency:  concurrency,
+		logger:       logger,
+	}, nil
+}
+
+// Resume finds every unfinished deployment and resumes it.
+func (r *resumer) Resume(ctx context.Context, tx DeploymentStore) (*ResumeReport, error) {
+	deployments, err := getUnfinishedDeployments(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Resuming %d interrupted deployments", len(deployments)))
+
+	results := make([]ResumeResult, len(deployments))
+	sem := make(chan struct{}, r.concurrency)
+
+	var wg sync.WaitGroup
+	for i, deployment := range deployments {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, deployment *Deployment) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i] = r.resume(ctx, tx, deployment)
+		}(i, deployment)
+	}
+	wg.Wait()
+
+	return &ResumeReport{
+		Results: results,
+	}, nil
+}
+
+// resume resumes a single deployment.
+func (r *resumer) resume(ctx context.Context, tx DeploymentStore, deployment *Deployment) (result ResumeResult) {
+	result = ResumeResult{
+		UUID:   deployment.UUID,
+		Action: deployment.Action,
+		Status: deployment.Status,
+	}
+
+	// Recover from panics triggered while creating the state
+	defer func() {
+		if p := recover(); p != nil {
+			result.Err = fmt.Errorf("resuming deployment panic: %s", p)
+		}
+	}()
+
+	r.logger.Debug(fmt.Sprintf("Resuming deployment [%s] of action [%s] with status [%s] at job [%s]",
+		deployment.UUID, deployment.Action, deployment.Status, deployment.CurrentJob))
+
+	state, err := r.stateFactory(ctx, deployment)
+	if err != nil {
+		result.Err = err
+		return
+	}
+
+	executeInput := &ExecuteInput{
+		ActionName: deployment.Action,
+		Deployment: deployment,
+	}
+	result.Err = r.service.Execute(ctx, NewStore(state), tx, executeInput, nil)
+
+	if result.Err != nil {
+		r.logger.Debug(fmt.Sprintf("Resuming deployment [%s] returned error: %s", deployment.UUID, result.Err))
+	} else {
+		r.logger.Debug(fmt.Sprintf("Resuming deployment [%s] has successfully finished", deployment.UUID))
+	}
+
+	return
+}
+
+// getUnfinishedDeployments returns the deployments that are running or rolling back, in the order they were created.
+func getUnfinishedDeployments(tx DeploymentStore) (Deployments, error) {
+	running, err := tx.GetDeploymentsByStatus(deploymentStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback, err := tx.GetDeploymentsByStatus(deploymentStatusRollback)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := append(running, rollback...)
+	sort.Slice(deployments, func(i, j int) bool {
+		return deployments[i].ID < deployments[j].ID
+	})
+
+	return deployments, nil
+}
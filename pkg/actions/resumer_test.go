@@ -0,0 +1,131 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+// resumerTestState is the state used to test the resumer.
+type resumerTestState struct {
+	sync.Mutex
+	// calls contains the name of each job and rollback handler called for the deployment.
+	calls []string
+}
+
+func (s *resumerTestState) addCall(call string) {
+	s.Lock()
+	defer s.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+// createResumerTestJob creates a job that records its execution and rollback in the store state.
+func createResumerTestJob(name string) *Job {
+	return &Job{
+		Name: name,
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}) (interface{}, error) {
+
+			store.State().(*resumerTestState).addCall(name)
+			return nil, nil
+		},
+		RollbackHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}, err error) (interface{}, error) {
+
+			store.State().(*resumerTestState).addCall("rollback " + name)
+			return nil, nil
+		},
+		InputType:  NilJobDataType,
+		OutputType: NilJobDataType,
+	}
+}
+
+func TestNewResumerInvalidConcurrency(t *testing.T) {
+	logger := gz.NewLoggerNoRollbar("Resumer", gz.VerbosityDebug)
+	_, err := NewResumer(NewService(logger), nil, 0, logger)
+	assert.True(t, errors.Is(err, ErrResumerInvalidConcurrency))
+}
+
+func TestResumerResume(t *testing.T) {
+	td := getTestData(t)
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	action, err := NewAction(Jobs{
+		createResumerTestJob("job1"),
+		createResumerTestJob("job2"),
+		createResumerTestJob("job3"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	// Create interrupted deployments
+	rollbackErr := "test error"
+	deployments := Deployments{
+		{UUID: "running", Action: td.actionName, CurrentJob: "job2", Status: deploymentStatusRunning},
+		{UUID: "finished", Action: td.actionName, CurrentJob: "job3", Status: deploymentStatusFinished},
+		{UUID: "rollback", Action: td.actionName, CurrentJob: "job2", Status: deploymentStatusRollback,
+			RollbackError: &rollbackErr},
+		{UUID: "missing", Action: "MissingAction", CurrentJob: "job1", Status: deploymentStatusRunning},
+		{UUID: "nostate", Action: td.actionName, CurrentJob: "job1", Status: deploymentStatusRunning},
+	}
+	for _, deployment := range deployments {
+		require.NoError(t, tx.CreateDeployment(deployment))
+	}
+
+	var statesLock sync.Mutex
+	states := make(map[string]*resumerTestState)
+	stateFactory := func(ctx context.Context, deployment *Deployment) (State, error) {
+		if deployment.UUID == "nostate" {
+			return nil, assert.AnError
+		}
+
+		statesLock.Lock()
+		defer statesLock.Unlock()
+		state := &resumerTestState{}
+		states[deployment.UUID] = state
+		return state, nil
+	}
+
+	resumer, err := NewResumer(service, stateFactory, 2, service.logger)
+	require.NoError(t, err)
+
+	report, err := resumer.Resume(context.Background(), tx)
+	require.NoError(t, err)
+
+	// Finished deployments are not resumed
+	require.Len(t, report.Results, 4)
+	assert.Equal(t, "running", report.Results[0].UUID)
+	assert.Equal(t, "rollback", report.Results[1].UUID)
+	assert.Equal(t, "missing", report.Results[2].UUID)
+	assert.Equal(t, "nostate", report.Results[3].UUID)
+
+	// Running deployments continue from their current job
+	assert.NoError(t, report.Results[0].Err)
+	assert.Equal(t, deploymentStatusRunning, report.Results[0].Status)
+	assert.Equal(t, []string{"job2", "job3"}, states["running"].calls)
+
+	// Rolling back deployments continue their rollback and return the original error
+	assert.EqualError(t, report.Results[1].Err, rollbackErr)
+	assert.Equal(t, deploymentStatusRollback, report.Results[1].Status)
+	assert.Equal(t, []string{"rollback job2", "rollback job1"}, states["rollback"].calls)
+
+	// Deployments that cannot be resumed are reported
+	assert.True(t, errors.Is(report.Results[2].Err, ErrActionNotFound))
+	assert.True(t, errors.Is(report.Results[3].Err, assert.AnError))
+	assert.Len(t, report.Failed(), 3)
+
+	// Resumed deployments are finished
+	for _, uuid := range []string{"running", "rollback"} {
+		deployment, err := tx.GetDeployment(uuid)
+		require.NoError(t, err)
+		assert.True(t, deployment.isFinished())
+	}
+}
@@ -0,0 +1,283 @@
+package actions
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/storage"
+	"github.com/gazebo-web/gz-go/v7"
+	"gopkg.in/go-playground/validator.v9"
+	"path"
+	"time"
+)
+
+const (
+	// archiveContentType is the content type of deployment archives.
+	archiveContentType = "application/x-gzip"
+	// retentionPageSize is the number of deployments listed per query when looking for expired deployments, if the
+	// retention policy does not define a batch size.
+	retentionPageSize = 100
+)
+
+var (
+	// ErrInvalidRetentionInterval is returned when a retention collector is run with an interval lower than 1.
+	ErrInvalidRetentionInterval = errors.New("retention interval must be positive")
+)
+
+// RetentionPolicy defines how long finished deployments are kept in a DeploymentStore.
+// Deployments that are still running or rolling back are never collected.
+type RetentionPolicy struct {
+	// MaxAge is the time successful deployments are kept after being created.
+	MaxAge time.Duration `validate:"gt=0"`
+	// FailedMaxAge is the time failed deployments (i.e. deployments that were rolled back) are kept after being
+	// created. Failed deployments are usually kept longer than successful ones to debug them.
+	// If zero, MaxAge is used.
+	FailedMaxAge time.Duration `validate:"min=0"`
+	// BatchSize is the maximum number of deployments collected in a single run.
+	// If zero, all expired deployments are collected.
+	BatchSize int `validate:"min=0"`
+	// DryRun reports the deployments that would be collected without archiving or deleting them.
+	DryRun bool
+}
+
+// maxAge returns the time a deployment is kept.
+func (p *RetentionPolicy) maxAge(deployment *Deployment) time.Duration {
+	if deployment.RollbackError != nil && p.FailedMaxAge > 0 {
+		return p.FailedMaxAge
+	}
+
+	return p.MaxAge
+}
+
+// DeploymentArchiver archives deployments before they are deleted by a RetentionCollector.
+type DeploymentArchiver interface {
+	// Archive archives a deployment with all its data and errors.
+	Archive(details *DeploymentDetails) error
+}
+
+// storageArchiver is a DeploymentArchiver that uploads deployments to a cloud storage.
+type storageArchiver struct {
+	// storage is the storage deployments are uploaded to.
+	storage storage.Storage
+	// bucket is the bucket deployments are uploaded to.
+	bucket string
+	// prefix is the prefix of the keys of the uploaded deployments.
+	prefix string
+}
+
+// NewStorageArchiver returns a DeploymentArchiver that uploads deployments to a cloud storage as gzip compressed JSON
+// files. Files are uploaded to `bucket`, with the key `<prefix>/<action>/<uuid>.json.gz`.
+//
+// Deployments collected from a store returned by NewEncryptedDeploymentStore are archived with their data encrypted.
+func NewStorageArchiver(storage storage.Storage, bucket string, prefix string) DeploymentArchiver {
+	return &storageArchiver{
+		storage: storage,
+		bucket:  bucket,
+		prefix:  prefix,
+	}
+}
+
+// Archive uploads a deployment to the storage.
+func (a *storageArchiver) Archive(details *DeploymentDetails) error {
+	var buf bytes.Buffer
+
+	// Compress the deployment
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(details); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	deployment := details.Deployment
+	return a.storage.Upload(storage.UploadInput{
+		Bucket:        a.bucket,
+		Key:           path.Join(a.prefix, deployment.Action, fmt.Sprintf("%s.json.gz", deployment.UUID)),
+		File:          bytes.NewReader(buf.Bytes()),
+		ContentLength: int64(buf.Len()),
+		ContentType:   archiveContentType,
+	})
+}
+
+// RetentionReport contains the result of a RetentionCollector run.
+type RetentionReport struct {
+	// DryRun is true if the deployments were not archived or deleted.
+	DryRun bool
+	// Expired contains the deployments that exceeded their retention period.
+	Expired Deployments
+	// Archived contains the UUIDs of the deployments that were archived.
+	Archived []string
+	// Deleted contains the UUIDs of the deployments that were deleted.
+	Deleted []string
+	// Errors contains the errors returned while collecting deployments, indexed by deployment UUID.
+	// Deployments that fail to be archived are not deleted.
+	Errors map[string]error
+}
+
+// RetentionCollector deletes finished deployments that exceeded their retention period.
+type RetentionCollector interface {
+	// Collect archives and deletes the deployments that exceeded their retention period, and returns a report.
+	Collect(ctx context.Context, tx DeploymentStore) (*RetentionReport, error)
+	// Run calls Collect every `interval` until the context is cancelled.
+	// Deployments that fail to be archived or deleted are collected again on the next run. Run blocks until the
+	// context is cancelled, and should only be run by one service instance sharing `tx`.
+	Run(ctx context.Context, tx DeploymentStore, interval time.Duration) error
+}
+
+// retentionCollector is a RetentionCollector implementation.
+type retentionCollector struct {
+	// policy contains the retention policy applied to deployments.
+	policy RetentionPolicy
+	// archiver is used to archive deployments before deleting them. If nil, deployments are not archived.
+	archiver DeploymentArchiver
+	// logger is used to log collection results.
+	logger gz.Logger
+	// now returns the current time.
+	now func() time.Time
+}
+
+// NewRetentionCollector returns a RetentionCollector that applies `policy` to deployments.
+// If `archiver` is not nil, deployments are archived before being deleted.
+func NewRetentionCollector(policy RetentionPolicy, archiver DeploymentArchiver, logger gz.Logger) (RetentionCollector, error) {
+	if err := validator.New().Struct(policy); err != nil {
+		return nil, err
+	}
+
+	return &retentionCollector{
+		policy:   policy,
+		archiver: archiver,
+		logger:   logger,
+		now:      time.Now,
+	}, nil
+}
+
+// getExpiredDeployments returns the finished deployments that exceeded their retention period, from oldest to newest.
+func (c *retentionCollector) getExpiredDeployments(tx DeploymentStore) (Deployments, error) {
+	// Only deployments older than the shortest retention period can be expired
+	minAge := c.policy.MaxAge
+	if c.policy.FailedMaxAge > 0 && c.policy.FailedMaxAge < minAge {
+		minAge = c.policy.FailedMaxAge
+	}
+
+	pageSize := c.policy.BatchSize
+	if pageSize == 0 {
+		pageSize = retentionPageSize
+	}
+
+	now := c.now()
+	createdBefore := now.Add(-minAge)
+	status := deploymentStatusFinished
+	filter := DeploymentFilter{
+		Status:        &status,
+		CreatedBefore: &createdBefore,
+		Limit:         pageSize,
+		OldestFirst:   true,
+	}
+
+	// List deployments one page at a time, collecting the oldest first. Deployments that match the filter may still
+	// be within their retention period (e.g. failed deployments), so more than one page may be needed to fill a
+	// batch.
+	var expired Deployments
+	for {
+		deployments, _, err := tx.ListDeployments(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, deployment := range deployments {
+			if now.Sub(deployment.CreatedAt) < c.policy.maxAge(deployment) {
+				continue
+			}
+			expired = append(expired, deployment)
+			if c.policy.BatchSize > 0 && len(expired) == c.policy.BatchSize {
+				return expired, nil
+			}
+		}
+
+		if len(deployments) < filter.Limit {
+			return expired, nil
+		}
+		filter.Offset += len(deployments)
+	}
+}
+
+// Collect archives and deletes the deployments that exceeded their retention period.
+func (c *retentionCollector) Collect(ctx context.Context, tx DeploymentStore) (*RetentionReport, error) {
+	expired, err := c.getExpiredDeployments(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RetentionReport{
+		DryRun:  c.policy.DryRun,
+		Expired: expired,
+		Errors:  make(map[string]error),
+	}
+
+	if c.policy.DryRun {
+		return report, nil
+	}
+
+	for _, deployment := range expired {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+
+		if c.archiver != nil {
+			// Encrypted data is archived as it is stored, so that sensitive data never leaves the store in plaintext
+			details, err := GetDeploymentDetails(withoutDecryption(tx), deployment.UUID)
+			if err != nil {
+				report.Errors[deployment.UUID] = err
+				continue
+			}
+			if err := c.archiver.Archive(details); err != nil {
+				report.Errors[deployment.UUID] = err
+				continue
+			}
+			report.Archived = append(report.Archived, deployment.UUID)
+		}
+
+		if err := tx.DeleteDeployment(deployment.UUID); err != nil {
+			report.Errors[deployment.UUID] = err
+			continue
+		}
+		report.Deleted = append(report.Deleted, deployment.UUID)
+	}
+
+	return report, nil
+}
+
+// Run calls Collect every `interval` until the context is cancelled.
+func (c *retentionCollector) Run(ctx context.Context, tx DeploymentStore, interval time.Duration) error {
+	if interval <= 0 {
+		return ErrInvalidRetentionInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		report, err := c.Collect(ctx, tx)
+		if err != nil {
+			c.logger.Debug(fmt.Sprintf("Collecting expired deployments failed: %s", err))
+			continue
+		}
+
+		if report.DryRun {
+			c.logger.Debug(fmt.Sprintf("Dry run: %d expired deployments would be collected", len(report.Expired)))
+			continue
+		}
+		c.logger.Debug(fmt.Sprintf("Collected expired deployments: %d archived, %d deleted, %d errors",
+			len(report.Archived), len(report.Deleted), len(report.Errors)))
+	}
+}
@@ -0,0 +1,277 @@
+package actions
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/storage"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+	"time"
+)
+
+// retentionTestStorage is a storage.Storage implementation that keeps uploaded files in memory.
+type retentionTestStorage struct {
+	uploads map[string]storage.UploadInput
+	err     error
+}
+
+func (s *retentionTestStorage) Upload(input storage.UploadInput) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.uploads[input.Key] = input
+	return nil
+}
+
+func (s *retentionTestStorage) GetURL(bucket string, key string, expiresIn time.Duration) (string, error) {
+	return s.PrepareAddress(bucket, key), nil
+}
+
+func (s *retentionTestStorage) PrepareAddress(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// setupRetentionTest creates a store with a successful, a failed and a running deployment, and a retention collector
+// that sees them two days after they were created.
+func setupRetentionTest(t *testing.T, policy RetentionPolicy, archiver DeploymentArchiver) (DeploymentStore,
+	RetentionCollector) {
+
+	tx := NewMemoryDeploymentStore()
+	failedErr := "failed"
+	for _, deployment := range []*Deployment{
+		{UUID: "success", Action: "start", Status: deploymentStatusFinished},
+		{UUID: "failed", Action: "start", Status: deploymentStatusFinished, RollbackError: &failedErr},
+		{UUID: "running", Action: "start", Status: deploymentStatusRunning},
+	} {
+		require.NoError(t, tx.CreateDeployment(deployment))
+		require.NoError(t, deployment.SetJobData(tx, nil, DeploymentJobData, "data"))
+	}
+
+	collector, err := NewRetentionCollector(policy, archiver, gz.NewLoggerNoRollbar("Retention", gz.VerbosityDebug))
+	require.NoError(t, err)
+	collector.(*retentionCollector).now = func() time.Time {
+		return time.Now().Add(48 * time.Hour)
+	}
+
+	return tx, collector
+}
+
+func TestNewRetentionCollectorInvalidPolicy(t *testing.T) {
+	_, err := NewRetentionCollector(RetentionPolicy{}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewRetentionCollector(RetentionPolicy{MaxAge: time.Hour, BatchSize: -1}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestRetentionCollectorCollect(t *testing.T) {
+	tx, collector := setupRetentionTest(t, RetentionPolicy{
+		MaxAge:       24 * time.Hour,
+		FailedMaxAge: 7 * 24 * time.Hour,
+	}, nil)
+
+	report, err := collector.Collect(context.Background(), tx)
+	require.NoError(t, err)
+	require.Len(t, report.Expired, 1)
+	assert.Equal(t, "success", report.Expired[0].UUID)
+	assert.Equal(t, []string{"success"}, report.Deleted)
+	assert.Empty(t, report.Archived)
+	assert.Empty(t, report.Errors)
+
+	// The expired deployment and its data are deleted
+	_, err = tx.GetDeployment("success")
+	assert.True(t, errors.Is(err, ErrDeploymentNotFound))
+
+	// Failed deployments are kept longer, and running deployments are never collected
+	for _, uuid := range []string{"failed", "running"} {
+		deployment, err := tx.GetDeployment(uuid)
+		require.NoError(t, err)
+		dataSet, err := tx.GetDeploymentDataSet(deployment)
+		require.NoError(t, err)
+		assert.Len(t, dataSet, 1)
+	}
+}
+
+func TestRetentionCollectorDryRun(t *testing.T) {
+	tx, collector := setupRetentionTest(t, RetentionPolicy{
+		MaxAge: 24 * time.Hour,
+		DryRun: true,
+	}, nil)
+
+	report, err := collector.Collect(context.Background(), tx)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	require.Len(t, report.Expired, 2)
+	assert.Equal(t, "success", report.Expired[0].UUID)
+	assert.Equal(t, "failed", report.Expired[1].UUID)
+	assert.Empty(t, report.Deleted)
+
+	// Deployments are not deleted
+	for _, uuid := range []string{"success", "failed"} {
+		_, err := tx.GetDeployment(uuid)
+		require.NoError(t, err)
+	}
+}
+
+func TestRetentionCollectorBatchSize(t *testing.T) {
+	tx, collector := setupRetentionTest(t, RetentionPolicy{
+		MaxAge:    24 * time.Hour,
+		BatchSize: 1,
+	}, nil)
+
+	report, err := collector.Collect(context.Background(), tx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"success"}, report.Deleted)
+
+	report, err = collector.Collect(context.Background(), tx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"failed"}, report.Deleted)
+}
+
+// retentionTestListStore is a DeploymentStore that records the filters passed to ListDeployments.
+type retentionTestListStore struct {
+	DeploymentStore
+	filters []DeploymentFilter
+}
+
+func (s *retentionTestListStore) ListDeployments(filter DeploymentFilter) (Deployments, int, error) {
+	s.filters = append(s.filters, filter)
+	return s.DeploymentStore.ListDeployments(filter)
+}
+
+func TestRetentionCollectorBatchSizePaginates(t *testing.T) {
+	tx, collector := setupRetentionTest(t, RetentionPolicy{
+		MaxAge:       24 * time.Hour,
+		FailedMaxAge: 7 * 24 * time.Hour,
+		BatchSize:    1,
+	}, nil)
+	require.NoError(t, tx.CreateDeployment(&Deployment{UUID: "newer", Action: "start", Status: deploymentStatusFinished}))
+
+	// Leave the failed deployment, which has not expired, as the oldest finished deployment
+	require.NoError(t, tx.DeleteDeployment("success"))
+
+	list := &retentionTestListStore{DeploymentStore: tx}
+	report, err := collector.Collect(context.Background(), list)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"newer"}, report.Deleted)
+
+	// Deployments should have been listed one page at a time from oldest to newest, stopping once the batch was full
+	require.Len(t, list.filters, 2)
+	for i, filter := range list.filters {
+		assert.Equal(t, 1, filter.Limit)
+		assert.Equal(t, i, filter.Offset)
+		assert.True(t, filter.OldestFirst)
+	}
+}
+
+func TestRetentionCollectorArchive(t *testing.T) {
+	store := &retentionTestStorage{uploads: make(map[string]storage.UploadInput)}
+	archiver := NewStorageArchiver(store, "bucket", "deployments")
+	tx, collector := setupRetentionTest(t, RetentionPolicy{MaxAge: 24 * time.Hour}, archiver)
+
+	report, err := collector.Collect(context.Background(), tx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"success", "failed"}, report.Archived)
+	assert.Equal(t, []string{"success", "failed"}, report.Deleted)
+
+	// Archives contain the compressed deployment details
+	upload, ok := store.uploads["deployments/start/success.json.gz"]
+	require.True(t, ok)
+	assert.Equal(t, "bucket", upload.Bucket)
+	assert.Equal(t, archiveContentType, upload.ContentType)
+	assert.Equal(t, upload.File.Size(), upload.ContentLength)
+
+	reader, err := gzip.NewReader(upload.File)
+	require.NoError(t, err)
+	var details DeploymentDetails
+	require.NoError(t, json.NewDecoder(reader).Decode(&details))
+	assert.Equal(t, "success", details.Deployment.UUID)
+	require.Len(t, details.Timeline, 1)
+	assert.Equal(t, "data", details.Timeline[0].Data[0].Value)
+}
+
+func TestRetentionCollectorArchiveEncrypted(t *testing.T) {
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+	jobDataTypeRegistry.register(GetJobDataType(encryptionTestSecret{}))
+
+	encryptor, err := NewAESGCMEncryptor("key1", map[string][]byte{"key1": encryptionTestKey1})
+	require.NoError(t, err)
+	tx := NewEncryptedDeploymentStore(NewMemoryDeploymentStore(), encryptor, EncryptValueTypes(encryptionTestSecret{}))
+
+	deployment := &Deployment{UUID: "secret", Action: "start", CurrentJob: "job", Status: deploymentStatusFinished}
+	require.NoError(t, tx.CreateDeployment(deployment))
+	require.NoError(t, deployment.SetJobData(tx, nil, DeploymentJobInput, encryptionTestSecret{Token: "token"}))
+
+	store := &retentionTestStorage{uploads: make(map[string]storage.UploadInput)}
+	archiver := NewStorageArchiver(store, "bucket", "deployments")
+	collector, err := NewRetentionCollector(RetentionPolicy{MaxAge: 24 * time.Hour}, archiver,
+		gz.NewLoggerNoRollbar("Retention", gz.VerbosityDebug))
+	require.NoError(t, err)
+	collector.(*retentionCollector).now = func() time.Time {
+		return time.Now().Add(48 * time.Hour)
+	}
+
+	report, err := collector.Collect(context.Background(), tx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"secret"}, report.Archived)
+
+	// Archives contain the encrypted data
+	upload, ok := store.uploads["deployments/start/secret.json.gz"]
+	require.True(t, ok)
+	reader, err := gzip.NewReader(upload.File)
+	require.NoError(t, err)
+	archive, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.NotContains(t, string(archive), "token")
+
+	var details DeploymentDetails
+	require.NoError(t, json.Unmarshal(archive, &details))
+	require.Len(t, details.Timeline, 1)
+	input := details.Timeline[0].Input
+	require.NotNil(t, input)
+	assert.True(t, isEncrypted(*input.Raw))
+	assert.Nil(t, input.Value)
+}
+
+func TestRetentionCollectorArchiveFailure(t *testing.T) {
+	store := &retentionTestStorage{err: assert.AnError}
+	archiver := NewStorageArchiver(store, "bucket", "deployments")
+	tx, collector := setupRetentionTest(t, RetentionPolicy{MaxAge: 24 * time.Hour}, archiver)
+
+	report, err := collector.Collect(context.Background(), tx)
+	require.NoError(t, err)
+	assert.Empty(t, report.Deleted)
+	require.Len(t, report.Errors, 2)
+	assert.True(t, errors.Is(report.Errors["success"], assert.AnError))
+
+	// Deployments that fail to be archived are not deleted
+	_, err = tx.GetDeployment("success")
+	require.NoError(t, err)
+}
+
+func TestRetentionCollectorRun(t *testing.T) {
+	tx, collector := setupRetentionTest(t, RetentionPolicy{MaxAge: 24 * time.Hour}, nil)
+
+	assert.True(t, errors.Is(collector.Run(context.Background(), tx, 0), ErrInvalidRetentionInterval))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- collector.Run(ctx, tx, time.Millisecond)
+	}()
+
+	// Wait for the background task to collect the expired deployments
+	require.Eventually(t, func() bool {
+		_, err := tx.GetDeployment("failed")
+		return errors.Is(err, ErrDeploymentNotFound)
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -1,11 +1,14 @@
 package actions
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/gazebo-web/gz-go/v7"
-	"github.com/jinzhu/gorm"
+	"gopkg.in/go-playground/validator.v9"
 	"runtime/debug"
+	"sync"
+	"time"
 )
 
 var (
@@ -21,32 +24,86 @@ var (
 	ErrJobNilOutput = errors.New("job cannot return nil, pass through the input value instead")
 	// ErrExecutionStopped is raised when the execution is forcibly stopped by a user command.
 	ErrExecutionStopped = errors.New("action execution was stopped by a user command")
+	// ErrDeploymentNotRunning is raised when trying to stop a deployment that is not running.
+	ErrDeploymentNotRunning = errors.New("deployment is not running")
 )
 
 // Servicer is the interface for action services.
 type Servicer interface {
 	// RegisterAction registers an action for a specific application.
+	// Multiple versions of the same action can be registered to resume deployments started with previous versions.
 	RegisterAction(applicationName *string, actionName string, action *Action) error
+	// GetAction returns the latest version of a registered action.
+	GetAction(applicationName *string, actionName string) (*Action, error)
 	// Execute executes an action.
-	Execute(store Store, tx *gorm.DB, executeInput ExecuteInputer, jobInput interface{}) error
+	// The context passed to job functions is cancelled if the deployment is stopped by calling Stop.
+	// If `ctx` contains a recording trace span, a child span is created for the deployment, and for each job and
+	// rollback handler run. The trace ID is recorded in the deployment.
+	// Services that lease deployments return ErrDeploymentLeased if the deployment is being executed by another
+	// service instance, and ErrDeploymentLeaseLost if the lease is taken over while the deployment is executed.
+	Execute(ctx context.Context, store Store, tx DeploymentStore, executeInput ExecuteInputer,
+		jobInput interface{}) error
+	// Stop requests stopping a running deployment.
+	// The deployment stops before running its next job and is rolled back, and the context of the job currently running
+	// is cancelled. Deployments executed by other service instances are stopped when their lease is renewed, and
+	// deployments that are not being executed are stopped when they are resumed. Execute returns ErrExecutionStopped
+	// for stopped deployments.
+	Stop(tx DeploymentStore, uuid string) error
 }
 
 // service provides operations to register and execute actions.
 type service struct {
+	// actions contains the latest version of each registered action, indexed by name.
 	actions map[string]*Action
-	logger  gz.Logger
+	// versions contains every registered version of each action, indexed by name and version.
+	versions map[string]map[int]*Action
+	logger   gz.Logger
+	// running contains the deployments being executed by this service, indexed by UUID.
+	running map[string]*runningDeployment
+	// runningLock is used to synchronize access to running.
+	runningLock sync.Mutex
+	// observers contains the observers notified of lifecycle events.
+	observers []Observer
+	// lease contains the configuration used to lease deployments. If nil, deployments are not leased.
+	lease *LeaseConfig
+}
+
+// runningDeployment contains the state of a deployment being executed by a service.
+type runningDeployment struct {
+	// cancel cancels the context used to run the jobs of the deployment.
+	cancel context.CancelFunc
+	// stopped is set if the deployment was stopped.
+	stopped bool
 }
 
 // NewService returns a pointer to an action Servicer implementation.
-func NewService(logger gz.Logger) Servicer {
+// `observers` are notified of lifecycle events of every deployment executed by the service.
+func NewService(logger gz.Logger, observers ...Observer) Servicer {
 	service := &service{
-		logger: logger,
+		logger:    logger,
+		observers: observers,
 	}
 	service.actions = make(map[string]*Action, 0)
+	service.versions = make(map[string]map[int]*Action)
+	service.running = make(map[string]*runningDeployment)
 
 	return service
 }
 
+// NewServiceWithLease returns a pointer to an action Servicer implementation that leases the deployments it executes.
+// Leasing deployments prevents multiple service instances sharing a DeploymentStore (e.g. during a blue-green
+// deployment) from executing the same deployment at the same time.
+func NewServiceWithLease(logger gz.Logger, config LeaseConfig, observers ...Observer) (Servicer, error) {
+	if err := validator.New().Struct(config); err != nil {
+		return nil, err
+	}
+
+	service := NewService(logger, observers...).(*service)
+	service.lease = &config
+
+	return service, nil
+}
+
 // generateApplicationActionName generates the name for an application-specific action.
 // actionName cannot be an empty string.
 func generateApplicationActionName(applicationName *string, actionName string) (string, error) {
@@ -62,7 +119,7 @@ func generateApplicationActionName(applicationName *string, actionName string) (
 	return fmt.Sprintf("%s%s", appName, actionName), nil
 }
 
-// getAction gets an action based on the application and action names.
+// getAction gets the latest version of an action based on the application and action names.
 // actionName cannot be an empty string.
 func (s *service) getAction(applicationName *string, actionName string) (*Action, error) {
 	// Get the application-specific action name
@@ -81,8 +138,15 @@ func (s *service) getAction(applicationName *string, actionName string) (*Action
 	return action, nil
 }
 
+// GetAction returns the latest version of a registered action.
+// Returns ErrActionNotFound if the action is not registered.
+func (s *service) GetAction(applicationName *string, actionName string) (*Action, error) {
+	return s.getAction(applicationName, actionName)
+}
+
 // RegisterAction registers an action for a specific application.
 // Actions for an application have the application name prefixed to the action.
+// An action can be registered more than once with different versions. New deployments use the latest version.
 // actionName cannot be an empty string.
 // action cannot be `nil`.
 func (s *service) RegisterAction(applicationName *string, actionName string, action *Action) error {
@@ -97,21 +161,55 @@ func (s *service) RegisterAction(applicationName *string, actionName string, act
 		return err
 	}
 
-	// Make sure the action was not registered before
-	if _, exists := s.actions[applicationActionName]; exists {
+	// Make sure the action version was not registered before
+	if _, exists := s.versions[applicationActionName][action.Version]; exists {
 		return ErrActionExists
 	}
 
 	// Register the action
-	s.actions[applicationActionName] = action
+	action.Name = applicationActionName
+	if _, exists := s.versions[applicationActionName]; !exists {
+		s.versions[applicationActionName] = make(map[int]*Action)
+	}
+	s.versions[applicationActionName][action.Version] = action
+
+	// Keep track of the latest version
+	if latest, exists := s.actions[applicationActionName]; !exists || action.Version > latest.Version {
+		s.actions[applicationActionName] = action
+	}
 
 	return nil
 }
 
+// getDeploymentAction returns the version of an action used to resume a deployment.
+// If the version of the action the deployment was started with is registered, it is returned. If not, the deployment
+// is migrated to the latest version of the action.
+// `action` must be the latest version of the action. `deployment` can be nil for new deployments.
+func (s *service) getDeploymentAction(tx DeploymentStore, action *Action, deployment *Deployment) (*Action, error) {
+	if deployment == nil || deployment.ActionVersion == action.Version {
+		return action, nil
+	}
+
+	if versioned, exists := s.versions[action.Name][deployment.ActionVersion]; exists {
+		return versioned, nil
+	}
+
+	s.logger.Debug(fmt.Sprintf("Migrating deployment [%s] from action [%s] version [%d] to version [%d]",
+		deployment.UUID, action.Name, deployment.ActionVersion, action.Version))
+
+	if err := migrateDeployment(tx, action, deployment); err != nil {
+		return nil, err
+	}
+
+	return action, nil
+}
+
 // Execute executes an action by running each job in the action's job sequence.
 // Executing an action includes running an action from scratch, restarting an action (e.g. due to a server restart) and
 // handling errors that may come up while running actions.
-func (s *service) Execute(store Store, tx *gorm.DB, executeInput ExecuteInputer, jobInput interface{}) (err error) {
+func (s *service) Execute(ctx context.Context, store Store, tx DeploymentStore, executeInput ExecuteInputer,
+	jobInput interface{}) (err error) {
+
 	// input contains generic execution information necessary such as the action, the deployment and current job index
 	input := executeInput.getExecuteInput()
 
@@ -121,18 +219,81 @@ func (s *service) Execute(store Store, tx *gorm.DB, executeInput ExecuteInputer,
 		return err
 	}
 
+	// Lease restored deployments before modifying them. New deployments are created leased.
+	var lease *deploymentLease
+	if input.Deployment != nil {
+		if lease, err = s.acquireLease(tx, input.Deployment); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		if errRelease := lease.release(); errRelease != nil {
+			s.logger.Debug(fmt.Sprintf("Failed to release lease of deployment [%s]: %s", input.Deployment.UUID,
+				errRelease))
+		}
+	}()
+
+	// Get the version of the action used by restored deployments
+	action, err = s.getDeploymentAction(tx, action, input.Deployment)
+	if err != nil {
+		return err
+	}
+
 	// Initialize the execution input.
 	// This step ensures that the ExecuteInput contains a valid Deployment:
 	//   * If a previous deployment for the input exists then it is used.
 	//   * If no deployment is found then a new deployment is created and assigned to the input.
-	err = executeInput.initialize(tx, action)
+	// New deployments are created leased to this service, so that other service instances cannot resume them.
+	err = executeInput.initialize(tx, action, s.lease)
 	if err != nil {
 		return err
 	}
 	deployment := executeInput.getDeployment()
+	if lease == nil && s.lease != nil {
+		lease = newDeploymentLease(tx, deployment.UUID, *s.lease, s.logger)
+	}
+	// Stop running jobs if the lease is taken over by another service instance, or if the deployment is stopped
+	// through another service instance
+	lease.heartbeat(func() {
+		s.cancelDeployment(deployment.UUID)
+	}, func() {
+		s.stopDeployment(deployment.UUID)
+	})
+
+	// Trace the execution. The span is ended after the deployment is finished.
+	ctx, span, err := startDeploymentSpan(ctx, tx, deployment, !executeInput.isNew())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	// Notify observers of the execution. The finished event is deferred before updating the deployment status to
+	// have it emitted after the deployment is finished.
+	start := time.Now()
+	s.notify(&DeploymentStartedEvent{
+		EventInfo: newEventInfo(deployment),
+		Resumed:   !executeInput.isNew(),
+		Status:    deployment.Status,
+	})
+	rolledBack := false
+	defer func() {
+		s.notify(&DeploymentFinishedEvent{
+			EventInfo:  newEventInfo(deployment),
+			Duration:   time.Since(start),
+			RolledBack: rolledBack,
+			Err:        err,
+		})
+	}()
 
 	// Change the deployment status to Finished after returning
 	defer func() {
+		// Deployments whose lease was lost are being executed by another service instance
+		if lease.isLost() {
+			err = ErrDeploymentLeaseLost
+			return
+		}
 		// Only override the returned error if the Status field fails to update
 		if errSetStatus := deployment.setFinishedStatus(tx); errSetStatus != nil {
 			err = errSetStatus
@@ -141,12 +302,18 @@ func (s *service) Execute(store Store, tx *gorm.DB, executeInput ExecuteInputer,
 
 	// Process the sequence of jobs
 	if deployment.isRunning() {
-		err = s.processJobs(store, tx, action, executeInput, jobInput)
+		// Allow stopping the deployment while jobs are being processed
+		jobsCtx := s.startDeployment(ctx, deployment.UUID)
+		err = s.processJobs(jobsCtx, store, tx, action, executeInput, jobInput, lease)
+		s.finishDeployment(deployment.UUID)
 	}
 
-	// Rollback if the deployment has been marked for rollback
-	if deployment.isRollingBack() {
-		return s.rollback(store, tx, action, executeInput, err)
+	// Rollback if the deployment has been marked for rollback.
+	// Rollback handlers receive the original context, as the context used to process jobs is cancelled when the
+	// deployment is stopped.
+	if deployment.isRollingBack() && !lease.isLost() {
+		rolledBack = true
+		return s.rollback(ctx, store, tx, action, executeInput, err)
 	}
 
 	return nil
@@ -157,13 +324,14 @@ func (s *service) Execute(store Store, tx *gorm.DB, executeInput ExecuteInputer,
 // If the `executeInput`'s deployment is not new, this method will only process the current job onwards.
 // `jobInput` is also automatically loaded from persistent storage (and overwritten) if the `executeInput`'s
 // deployment is not new.
-func (s *service) processJobs(store Store, tx *gorm.DB, action *Action, executeInput ExecuteInputer,
-	jobInput interface{}) (err error) {
+func (s *service) processJobs(ctx context.Context, store Store, tx DeploymentStore, action *Action,
+	executeInput ExecuteInputer, jobInput interface{}, lease *deploymentLease) (err error) {
 	// input contains generic execution information necessary such as the action, the deployment and current job index
 	input := executeInput.getExecuteInput()
 	deployment := executeInput.getDeployment()
 
 	// Mark the deployment for rollback if an error was returned or a panic was triggered.
+	// Deployments whose lease was lost are not modified, as they are being executed by another service instance.
 	defer func() {
 		if r := recover(); r != nil {
 			s.logger.Debug("Running job panic:", r, "\nStack:", string(debug.Stack()))
@@ -175,24 +343,56 @@ func (s *service) processJobs(store Store, tx *gorm.DB, action *Action, executeI
 				err = errJobError
 			}
 		}
-		if err != nil {
+		if err != nil && !lease.isLost() {
 			if errSetStatus := deployment.setRollbackStatus(tx, err); errSetStatus != nil {
 				err = errSetStatus
 			}
 		}
 	}()
 
-	// If the executeInput is not new, get the jobInput from persistent storage
+	// If the executeInput is not new, get the jobInput from persistent storage.
+	// Jobs that received a nil input do not have a stored input.
+	if !executeInput.isNew() {
+		jobInput, err = deployment.GetJobData(tx, &deployment.CurrentJob, DeploymentJobInput)
+		if errors.Is(err, ErrDeploymentDataNotFound) || errors.Is(err, ErrDeploymentDataNoData) {
+			jobInput, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// The current job of a restored deployment may have started running before the deployment was interrupted
+	resumedIndex := -1
 	if !executeInput.isNew() {
-		if jobInput, err = deployment.GetJobData(tx, &deployment.CurrentJob, DeploymentJobInput); err != nil {
+		resumedIndex = input.index
+	}
+
+	// Restored deployments may have been stopped while they were not being executed
+	if !executeInput.isNew() {
+		stored, err := tx.GetDeployment(deployment.UUID)
+		if err != nil {
 			return err
 		}
+		deployment.StopRequested = stored.StopRequested
 	}
 
 	// Process jobs
 	for ; input.index < len(action.Jobs); input.index++ {
 		job := action.Jobs[input.index]
 
+		// Stop the execution if the lease was lost or a stop was requested
+		if err := lease.verify(); err != nil {
+			return err
+		}
+		if err := s.checkStopRequested(ctx, deployment); err != nil {
+			// Jobs that did not start are not rolled back, rollback starts from the last job that ran
+			if input.index != resumedIndex {
+				input.index--
+			}
+			return err
+		}
+
 		// Update the deployment job
 		if err := deployment.setJob(tx, job.Name, jobInput); err != nil {
 			return err
@@ -200,16 +400,55 @@ func (s *service) processJobs(store Store, tx *gorm.DB, action *Action, executeI
 
 		// Run the job
 		s.logger.Debug(fmt.Sprintf("Running job [%s] for deployment [%s]", job.Name, deployment.UUID))
-		jobInput, err = job.Run(store, tx, deployment, jobInput)
+		s.notify(&JobStartedEvent{
+			EventInfo: newEventInfo(deployment),
+			Job:       job.Name,
+			Index:     input.index,
+			Total:     len(action.Jobs),
+		})
+		jobStart := time.Now()
+		jobCtx, jobSpan := startSpan(ctx, "job "+job.Name,
+			attributeJobName.String(job.Name),
+			attributeJobIndex.Int(input.index),
+		)
+		jobInput, err = job.Run(jobCtx, store, tx, deployment, jobInput)
+		endSpan(jobSpan, err)
 		// If an error was found, add it to the deployment and return
 		if err != nil {
+			// The job may have failed because the lease was lost
+			if errLease := lease.verify(); errLease != nil {
+				return errLease
+			}
 			s.logger.Debug(fmt.Sprintf("Running job [%s] for deployment [%s] has failed with error: %s.", job.Name, deployment.UUID, err))
+			s.notify(&JobFailedEvent{
+				EventInfo: newEventInfo(deployment),
+				Job:       job.Name,
+				Index:     input.index,
+				Total:     len(action.Jobs),
+				Duration:  time.Since(jobStart),
+				Err:       err,
+			})
 			if err := deployment.addJobError(tx, nil, err); err != nil {
 				return err
 			}
+			// The job may have failed because a stop was requested
+			if err := s.checkStopRequested(ctx, deployment); err != nil {
+				return err
+			}
+			return err
+		}
+		// Store the job output. This entry marks the job as finished.
+		if err := deployment.SetJobData(tx, nil, DeploymentJobOutput, jobInput); err != nil {
 			return err
 		}
 		s.logger.Debug(fmt.Sprintf("Running job [%s] for deployment [%s] has successfully finished.", job.Name, deployment.UUID))
+		s.notify(&JobSucceededEvent{
+			EventInfo: newEventInfo(deployment),
+			Job:       job.Name,
+			Index:     input.index,
+			Total:     len(action.Jobs),
+			Duration:  time.Since(jobStart),
+		})
 	}
 
 	return nil
@@ -218,7 +457,9 @@ func (s *service) processJobs(store Store, tx *gorm.DB, action *Action, executeI
 // rollback rolls back an execution, releasing any resources taken (e.g. cloud instances, orchestration resources,
 // etc.) and undoing any changes that may affect other executions.
 // All error handlers for the current and previous jobs will be executed, to allow them to reset resources.
-func (s *service) rollback(store Store, tx *gorm.DB, action *Action, executeInput ExecuteInputer, err error) error {
+func (s *service) rollback(ctx context.Context, store Store, tx DeploymentStore, action *Action,
+	executeInput ExecuteInputer, err error) error {
+
 	// input contains generic execution information necessary such as the action, the deployment and current job index
 	input := executeInput.getExecuteInput()
 	deployment := executeInput.getDeployment()
@@ -242,6 +483,12 @@ func (s *service) rollback(store Store, tx *gorm.DB, action *Action, executeInpu
 		err = deployment.getRollbackError()
 	}
 
+	s.notify(&RollbackStartedEvent{
+		EventInfo: newEventInfo(deployment),
+		Job:       deployment.CurrentJob,
+		Err:       err,
+	})
+
 	for ; input.index >= 0; input.index-- {
 
 		job := action.Jobs[input.index]
@@ -251,10 +498,21 @@ func (s *service) rollback(store Store, tx *gorm.DB, action *Action, executeInpu
 			return err
 		}
 
+		// Skipped jobs are not rolled back
+		skipped, skipErr := isJobSkipped(tx, deployment, &job.Name)
+		if skipErr != nil {
+			return skipErr
+		}
+
 		// Run rollback logic for the current job if defined
-		if job.RollbackHandler != nil {
+		if job.RollbackHandler != nil && !skipped {
 			s.logger.Debug(fmt.Sprintf("Running rollback handler for job [%s] on deployment [%s]", job.Name, deployment.UUID))
-			_, handlerErr := job.RollbackHandler(store, tx, deployment, nil, err)
+			handlerCtx, handlerSpan := startSpan(ctx, "rollback "+job.Name,
+				attributeJobName.String(job.Name),
+				attributeJobIndex.Int(input.index),
+			)
+			_, handlerErr := job.RollbackHandler(handlerCtx, store, tx, deployment, nil, err)
+			endSpan(handlerSpan, handlerErr)
 
 			// If an error was found, add it to the deployment and return
 			if handlerErr != nil {
@@ -265,6 +523,11 @@ func (s *service) rollback(store Store, tx *gorm.DB, action *Action, executeInpu
 				}
 
 				s.logger.Debug(fmt.Sprintf("Running rollback handler for job [%s] on deployment [%s] failed with error: %s.", job.Name, deployment.UUID, handlerErr))
+				s.notify(&RollbackHandlerFailedEvent{
+					EventInfo: newEventInfo(deployment),
+					Job:       job.Name,
+					Err:       handlerErr,
+				})
 				return err
 			}
 			s.logger.Debug(fmt.Sprintf("Running rollback handler for job [%s] on deployment [%s] succeeded.", job.Name, deployment.UUID))
@@ -273,3 +536,133 @@ func (s *service) rollback(store Store, tx *gorm.DB, action *Action, executeInpu
 
 	return err
 }
+
+// Stop requests stopping a running deployment.
+func (s *service) Stop(tx DeploymentStore, uuid string) error {
+	deployment, err := tx.GetDeployment(uuid)
+	if err != nil {
+		return err
+	}
+
+	if !deployment.isRunning() {
+		return ErrDeploymentNotRunning
+	}
+
+	// Persist the request. This allows stopping deployments executed by other services, and deployments that will be
+	// resumed after an interruption.
+	if err := tx.RequestDeploymentStop(uuid); err != nil {
+		return err
+	}
+
+	// Stop the deployment if it is being executed by this service
+	s.stopDeployment(uuid)
+
+	return nil
+}
+
+// cancelDeployment cancels the context of a deployment if it is being executed by this service.
+func (s *service) cancelDeployment(uuid string) {
+	s.runningLock.Lock()
+	defer s.runningLock.Unlock()
+
+	if running, ok := s.running[uuid]; ok {
+		s.logger.Debug(fmt.Sprintf("Cancelling running deployment [%s]", uuid))
+		running.cancel()
+	}
+}
+
+// stopDeployment marks a deployment as stopped and cancels its context if it is being executed by this service.
+func (s *service) stopDeployment(uuid string) {
+	s.runningLock.Lock()
+	defer s.runningLock.Unlock()
+
+	if running, ok := s.running[uuid]; ok {
+		s.logger.Debug(fmt.Sprintf("Stopping running deployment [%s]", uuid))
+		running.stopped = true
+		running.cancel()
+	}
+}
+
+// isStopped returns true if a deployment being executed by this service was stopped.
+func (s *service) isStopped(uuid string) bool {
+	s.runningLock.Lock()
+	defer s.runningLock.Unlock()
+
+	running, ok := s.running[uuid]
+	return ok && running.stopped
+}
+
+// acquireLease leases a deployment if the service leases deployments, and reloads the deployment to get the state
+// stored by the previous owner of the lease. Returns a nil lease if the service does not lease deployments.
+func (s *service) acquireLease(tx DeploymentStore, deployment *Deployment) (*deploymentLease, error) {
+	if s.lease == nil {
+		return nil, nil
+	}
+
+	lease, err := acquireDeploymentLease(tx, deployment.UUID, *s.lease, s.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := tx.GetDeployment(deployment.UUID)
+	if err != nil {
+		_ = lease.release()
+		return nil, err
+	}
+	*deployment = *stored
+
+	return lease, nil
+}
+
+// startDeployment marks a deployment as being executed by this service.
+// It returns a context that is cancelled when the deployment is stopped.
+func (s *service) startDeployment(ctx context.Context, uuid string) context.Context {
+	s.runningLock.Lock()
+	defer s.runningLock.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.running[uuid] = &runningDeployment{cancel: cancel}
+
+	return ctx
+}
+
+// finishDeployment marks a deployment as no longer being executed by this service.
+func (s *service) finishDeployment(uuid string) {
+	s.runningLock.Lock()
+	defer s.runningLock.Unlock()
+
+	if running, ok := s.running[uuid]; ok {
+		running.cancel()
+		delete(s.running, uuid)
+	}
+}
+
+// checkStopRequested returns ErrExecutionStopped if a user requested stopping the deployment.
+// Stopping a deployment cancels the context used to run its jobs, so the stop state is only checked once `ctx` is
+// done.
+func (s *service) checkStopRequested(ctx context.Context, deployment *Deployment) error {
+	if !deployment.StopRequested {
+		if ctx.Err() == nil || !s.isStopped(deployment.UUID) {
+			return nil
+		}
+		deployment.StopRequested = true
+	}
+
+	s.logger.Debug(fmt.Sprintf("Deployment [%s] was stopped", deployment.UUID))
+	return ErrExecutionStopped
+}
+
+// notify sends an event to every observer registered in the service.
+// Observer panics are recovered to prevent them from interrupting deployments.
+func (s *service) notify(event Event) {
+	for _, observer := range s.observers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					s.logger.Debug(fmt.Sprintf("Observer panic while handling event %T: %s", event, r))
+				}
+			}()
+			observer.OnEvent(event)
+		}()
+	}
+}
@@ -2,7 +2,6 @@ package actions
 
 import (
 	"errors"
-	"github.com/jinzhu/gorm"
 )
 
 var (
@@ -21,7 +20,8 @@ type ExecuteInputer interface {
 	// getDeployment returns the execute input's deployment.
 	getDeployment() *Deployment
 	// initialize initializes the input.
-	initialize(tx *gorm.DB, action *Action) error
+	// If `lease` is not nil, new deployments are created leased to the lease owner.
+	initialize(tx DeploymentStore, action *Action, lease *LeaseConfig) error
 	// isNew indicates whether this input is new or was restored from a previous execution
 	isNew() bool
 }
@@ -41,14 +41,16 @@ type ExecuteInput struct {
 	Deployment *Deployment
 	// index contains the current job index.
 	index int
+	// restored is true if this input was restored from an existing deployment.
+	restored bool
 }
 
 // newExecuteInput creates a new ExecuteInput for a specific deployment.
-func newExecuteInput(tx *gorm.DB, action *Action) (*ExecuteInput, error) {
+func newExecuteInput(tx DeploymentStore, action *Action) (*ExecuteInput, error) {
 	var input ExecuteInput
 
 	// Initialize the input
-	if err := input.initialize(tx, action); err != nil {
+	if err := input.initialize(tx, action, nil); err != nil {
 		return nil, err
 	}
 
@@ -68,21 +70,28 @@ func (ei *ExecuteInput) getDeployment() *Deployment {
 
 // isNew indicates whether this input is new or was restored from a previous execution
 func (ei *ExecuteInput) isNew() bool {
-	return ei.index == 0
+	return !ei.restored
 }
 
 // initialize initializes this input.
 // If the input contains a deployment, this method restores the input to the state of the deployment.
-// If not, a new deployment is created for the input.
-func (ei *ExecuteInput) initialize(tx *gorm.DB, action *Action) error {
+// If not, a new deployment is created for the input. If `lease` is not nil, the new deployment is created leased to
+// the lease owner.
+func (ei *ExecuteInput) initialize(tx DeploymentStore, action *Action, lease *LeaseConfig) error {
 	// If the input is for an existing deployment, restore the state and return
-	if err := ei.restore(action); err == nil {
+	err := ei.restore(action)
+	if err == nil {
 		return nil
 	}
 
+	// The deployment cannot be resumed if its current job is not part of the action
+	if ei.Deployment != nil {
+		return err
+	}
+
 	// If the input does not contain a previous deployment, create a new one
 	if ei.Deployment == nil {
-		deployment, err := newDeployment(tx, action, ei.GroupID)
+		deployment, err := newDeployment(tx, action, ei.GroupID, lease)
 		if err != nil {
 			return err
 		}
@@ -108,6 +117,7 @@ func (ei *ExecuteInput) restore(action *Action) error {
 	if err := ei.restoreIndex(action); err != nil {
 		return err
 	}
+	ei.restored = true
 
 	return nil
 }
@@ -40,7 +40,7 @@ func TestNewExecuteInput(t *testing.T) {
 
 	var input *ExecuteInput
 	var err error
-	input, err = newExecuteInput(tr.db, td.action)
+	input, err = newExecuteInput(tr.tx, td.action)
 	require.NoError(t, err)
 	// Check that a deployment was created
 	require.NotNil(t, input.Deployment)
@@ -71,7 +71,7 @@ func TestInitializeNew(t *testing.T) {
 	deploymentCount := eitd.getDeploymentCount(t, tr.db)
 
 	// Initialize the input
-	require.NoError(t, input.initialize(tr.db, td.action))
+	require.NoError(t, input.initialize(tr.tx, td.action, nil))
 
 	// Check that a deployment was created when initializing the input
 	require.NotNil(t, input.Deployment)
@@ -98,7 +98,7 @@ func TestInitializeWithDeployment(t *testing.T) {
 	deploymentCount := eitd.getDeploymentCount(t, tr.db)
 
 	// Initialize the input
-	require.NoError(t, input.initialize(tr.db, td.action))
+	require.NoError(t, input.initialize(tr.tx, td.action, nil))
 
 	// Check that the total number of deployments in the database has not increased
 	require.Equal(t, deploymentCount, eitd.getDeploymentCount(t, tr.db))
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/gazebo-web/gz-go/v7"
@@ -66,7 +67,7 @@ var serviceTestData = struct {
 	getJobDataCount: func(t *testing.T, db *gorm.DB, deployment *Deployment) int {
 		var count int
 		err := db.
-			Model(&deploymentData{}).
+			Model(&DeploymentData{}).
 			Where("deployment_id = ?", deployment.ID).
 			Count(&count).Error
 		require.NoError(t, err)
@@ -99,10 +100,10 @@ var serviceTestData = struct {
 		}
 
 		// Initialize the input
-		require.NoError(t, executeInput.initialize(tr.db, action))
+		require.NoError(t, executeInput.initialize(tr.tx, action, nil))
 
 		// Process jobs
-		err := service.processJobs(tr.store, tr.db, action, executeInput, jobInput)
+		err := service.processJobs(context.Background(), tr.store, tr.tx, action, executeInput, jobInput, nil)
 
 		return executeInput, err
 	},
@@ -116,7 +117,7 @@ var serviceTestData = struct {
 			// Prepare the rollback handler if necessary
 			var rollbackHandler JobErrorHandler
 			if rollbackHandlerCalls != nil {
-				rollbackHandler = func(store Store, tx *gorm.DB, deployment *Deployment, value interface{},
+				rollbackHandler = func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{},
 					err error) (interface{}, error) {
 
 					// The error received should be the test rollback error
@@ -144,7 +145,7 @@ var serviceTestData = struct {
 			return &Job{
 				Name: name,
 				PreHooks: []JobFunc{
-					func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+					func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 						input := value.(*ServiceTestStruct)
 
 						input.PreHook++
@@ -154,7 +155,7 @@ var serviceTestData = struct {
 					},
 				},
 
-				Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+				Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 					input := value.(*ServiceTestStruct)
 
 					input.Execute++
@@ -164,7 +165,7 @@ var serviceTestData = struct {
 				},
 
 				PostHooks: []JobFunc{
-					func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+					func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 						input := value.(*ServiceTestStruct)
 
 						input.PostHook++
@@ -205,12 +206,12 @@ var serviceTestData = struct {
 			executeInput = &ExecuteInput{
 				ActionName: td.actionName,
 			}
-			require.NoError(t, executeInput.initialize(db, action))
+			require.NoError(t, executeInput.initialize(NewGormDeploymentStore(db), action, nil))
 		}
 		deployment := executeInput.getDeployment()
 
 		// Execute the action
-		err = service.Execute(store, db, executeInput, jobInput)
+		err = service.Execute(context.Background(), store, NewGormDeploymentStore(db), executeInput, jobInput)
 		if errorExpected {
 			require.Error(t, err)
 		} else {
@@ -332,9 +333,9 @@ func TestProcessJobs(t *testing.T) {
 	}
 
 	// checkJobData validates the job data stored by each job
-	checkJobData := func(t *testing.T, tr *TestResource, deployment *Deployment, job string, dataType deploymentDataType,
+	checkJobData := func(t *testing.T, tr *TestResource, deployment *Deployment, job string, dataType DeploymentDataType,
 		inputData *ServiceTestStruct) {
-		out, err := deployment.GetJobData(tr.db, &job, dataType)
+		out, err := deployment.GetJobData(tr.tx, &job, dataType)
 		require.NoError(t, err)
 		require.Equal(t, *inputData, *out.(*ServiceTestStruct))
 	}
@@ -348,8 +349,8 @@ func TestProcessJobs(t *testing.T) {
 		}
 
 		// Check that the job data was recorded
-		// The number of job data entries should be twice the number of jobs (input + job)
-		require.Equal(t, std.getJobDataCount(t, tr.db, deployment), jobCount*2)
+		// The number of job data entries should be three times the number of jobs (input + job + output)
+		require.Equal(t, std.getJobDataCount(t, tr.db, deployment), jobCount*3)
 
 		// Job 1
 		checkJobData(t, tr, deployment, td.jobName1, DeploymentJobInput, &std.job1InputData)
@@ -375,9 +376,9 @@ func TestProcessJobs(t *testing.T) {
 
 	// Process jobs resuming from the second job
 	t.Run("Process jobs resuming from second job", func(t *testing.T) {
-		deployment, err := newDeployment(tr.db, &Action{Jobs: jobs}, uuid.NewV4().String())
+		deployment, err := newDeployment(tr.tx, &Action{Jobs: jobs}, uuid.NewV4().String(), nil)
 		require.NoError(t, err)
-		require.NoError(t, deployment.setJob(tr.db, td.jobName2, nil))
+		require.NoError(t, deployment.setJob(tr.tx, td.jobName2, nil))
 
 		executeInput := &ExecuteInput{
 			Deployment: deployment,
@@ -385,9 +386,10 @@ func TestProcessJobs(t *testing.T) {
 		}
 
 		// Set job data
-		require.NoError(t, deployment.SetJobData(tr.db, &td.jobName1, DeploymentJobInput, &std.job1InputData))
-		require.NoError(t, deployment.SetJobData(tr.db, &td.jobName1, DeploymentJobData, &std.job1JobData))
-		require.NoError(t, deployment.SetJobData(tr.db, &td.jobName2, DeploymentJobInput, &std.job2InputData))
+		require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobInput, &std.job1InputData))
+		require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobData, &std.job1JobData))
+		require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobOutput, &std.job2InputData))
+		require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName2, DeploymentJobInput, &std.job2InputData))
 
 		input, err := std.processJobs(t, tr, service, executeInput, nil, jobs)
 		require.NoError(t, err)
@@ -412,11 +414,11 @@ func TestProcessJobsFailedJobErrorHandler(t *testing.T) {
 		PreHooks: []JobFunc{
 			WrapErrorHandler(
 				// Fun
-				func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+				func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 					return value, errors.New("prehooks")
 				},
 				// Handler
-				func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}, err error) (interface{}, error) {
+				func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}, err error) (interface{}, error) {
 					input := value.(*ServiceTestStruct)
 
 					input.PreHook++
@@ -427,11 +429,11 @@ func TestProcessJobsFailedJobErrorHandler(t *testing.T) {
 		},
 		Execute: WrapErrorHandler(
 			// Fn
-			func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 				return value, errors.New("execute")
 			},
 			// Handler
-			func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}, err error) (interface{}, error) {
+			func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}, err error) (interface{}, error) {
 				input := value.(*ServiceTestStruct)
 
 				input.Execute++
@@ -442,11 +444,11 @@ func TestProcessJobsFailedJobErrorHandler(t *testing.T) {
 		PostHooks: []JobFunc{
 			WrapErrorHandler(
 				// Fun
-				func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+				func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 					return value, testErr
 				},
 				// Handler
-				func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}, err error) (interface{}, error) {
+				func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}, err error) (interface{}, error) {
 					input := value.(*ServiceTestStruct)
 
 					input.PostHook++
@@ -474,7 +476,7 @@ func TestProcessJobsFailedJobErrorHandler(t *testing.T) {
 	require.Equal(t, std.getJobDataCount(t, tr.db, deployment), jobCount)
 
 	// There should be 3 errors registered for the job
-	errs, err := deployment.GetErrors(tr.db, nil)
+	errs, err := deployment.GetErrors(tr.tx, nil)
 	require.NoError(t, err)
 	require.Len(t, errs, 4)
 }
@@ -494,14 +496,14 @@ func TestProcessJobsNilOutput(t *testing.T) {
 		deployment := input.getDeployment()
 
 		// There should be only 1 error registered for the job
-		errs, err := deployment.GetErrors(tr.db, nil)
+		errs, err := deployment.GetErrors(tr.tx, nil)
 		require.NoError(t, err)
 		require.Len(t, errs, 1)
 	}
 
 	// Job that returns nil and no error
 	jobNil := &Job{
-		Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return nil, nil
 		},
 	}
@@ -510,7 +512,7 @@ func TestProcessJobsNilOutput(t *testing.T) {
 	// Job that returns nil and an error
 	testErr := errors.New("test")
 	jobTestErr := &Job{
-		Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			return nil, testErr
 		},
 	}
@@ -525,16 +527,16 @@ func testServiceValidateExecute(t *testing.T, db *gorm.DB, deployment *Deploymen
 	std := serviceTestData
 
 	// Check that the job data was recorded
-	// The number of job data entries should be twice the number of jobs (input + job).
-	require.Equal(t, jobCount*2, std.getJobDataCount(t, db, deployment))
+	// The number of job data entries should be three times the number of jobs (input + job + output).
+	require.Equal(t, jobCount*3, std.getJobDataCount(t, db, deployment))
 	// There should be one deployment error for the failed post-hook and one for the rollback handler.
 	require.Equal(t, 0, std.getDeploymentErrorCount(t, db, deployment))
 
 	// Validate job data
 	// checkJobData validates the job data stored by each job
 	checkJobData := func(t *testing.T, db *gorm.DB, deployment *Deployment, job string,
-		dataType deploymentDataType, inputData *ServiceTestStruct) {
-		out, err := deployment.GetJobData(db, &job, dataType)
+		dataType DeploymentDataType, inputData *ServiceTestStruct) {
+		out, err := deployment.GetJobData(NewGormDeploymentStore(db), &job, dataType)
 		require.NoError(t, err)
 		require.Equal(t, *inputData, *out.(*ServiceTestStruct))
 	}
@@ -557,16 +559,17 @@ func testServiceValidateRollbackExecute(t *testing.T, db *gorm.DB, deployment *D
 	std := serviceTestData
 
 	// Check that the job data was recorded
-	// The number of job data entries should be twice the number of jobs (input + job).
-	require.Equal(t, jobCount*2, std.getJobDataCount(t, db, deployment))
+	// The number of job data entries should be three times the number of jobs (input + job + output), except for the
+	// last job, which failed and has no output.
+	require.Equal(t, jobCount*3-1, std.getJobDataCount(t, db, deployment))
 	// There should be one deployment error for the failed post-hook and one for the rollback handler.
 	require.Equal(t, 2, std.getDeploymentErrorCount(t, db, deployment))
 
 	// Validate job data
 	// checkJobData validates the job data stored by each job
 	checkJobData := func(t *testing.T, db *gorm.DB, deployment *Deployment, job string,
-		dataType deploymentDataType, inputData *ServiceTestStruct) {
-		out, err := deployment.GetJobData(db, &job, dataType)
+		dataType DeploymentDataType, inputData *ServiceTestStruct) {
+		out, err := deployment.GetJobData(NewGormDeploymentStore(db), &job, dataType)
 		require.NoError(t, err)
 		require.Equal(t, *inputData, *out.(*ServiceTestStruct))
 	}
@@ -591,9 +594,9 @@ func testServiceUpdateJobsForRollback(t *testing.T, jobs Jobs) {
 	// Make the last posthook fail
 	hookFn := jobs[jobCount-1].PostHooks[0]
 	jobs[jobCount-1].PostHooks = []JobFunc{
-		func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+		func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 			// Execute the posthook logic as usual and return an error
-			_, err := hookFn(store, tx, deployment, value)
+			_, err := hookFn(ctx, store, tx, deployment, value)
 			require.NoError(t, err)
 
 			return nil, std.errRollback
@@ -602,10 +605,10 @@ func testServiceUpdateJobsForRollback(t *testing.T, jobs Jobs) {
 
 	// Make the rollback handler from Job 1 fail
 	rollbackHandler := jobs[0].RollbackHandler
-	jobs[0].RollbackHandler = func(store Store, tx *gorm.DB, deployment *Deployment, value interface{},
+	jobs[0].RollbackHandler = func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{},
 		err error) (interface{}, error) {
 
-		_, err = rollbackHandler(store, tx, deployment, value, err)
+		_, err = rollbackHandler(ctx, store, tx, deployment, value, err)
 		require.NoError(t, err)
 		return nil, std.errRollback
 	}
@@ -623,7 +626,7 @@ func TestExecuteInvalidAction(t *testing.T) {
 	executeInput := &ExecuteInput{
 		ActionName: "invalid_action",
 	}
-	err := service.Execute(tr.store, tr.db, executeInput, nil)
+	err := service.Execute(context.Background(), tr.store, tr.tx, executeInput, nil)
 	require.Error(t, ErrActionNotFound, err)
 }
 
@@ -676,14 +679,15 @@ func TestExecuteResumeAction(t *testing.T) {
 	executeInput := &ExecuteInput{
 		ActionName: td.actionName,
 	}
-	require.NoError(t, executeInput.initialize(tr.db, action))
+	require.NoError(t, executeInput.initialize(tr.tx, action, nil))
 
 	// Create job data and update the deployment to start at the second job
 	deployment := executeInput.getDeployment()
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName1, DeploymentJobInput, &std.job1InputData))
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName1, DeploymentJobData, &std.job1JobData))
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName2, DeploymentJobInput, &std.job2InputData))
-	require.NoError(t, deployment.setJob(tr.db, td.jobName2, nil))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobInput, &std.job1InputData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobData, &std.job1JobData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobOutput, &std.job2InputData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName2, DeploymentJobInput, &std.job2InputData))
+	require.NoError(t, deployment.setJob(tr.tx, td.jobName2, nil))
 
 	deployment = std.execute(t, tr.store, tr.db, service, jobs, executeInput, nil, false)
 
@@ -742,7 +746,7 @@ func TestExecuteResumeRollback(t *testing.T) {
 	testServiceUpdateJobsForRollback(t, jobs)
 
 	// The first job's functions should not run
-	jobs[0].Execute = func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+	jobs[0].Execute = func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 		t.Log("Job 1 Execute function was called instead of rolling back.")
 		t.Fail()
 		return nil, nil
@@ -756,26 +760,221 @@ func TestExecuteResumeRollback(t *testing.T) {
 	executeInput := &ExecuteInput{
 		ActionName: td.actionName,
 	}
-	require.NoError(t, executeInput.initialize(tr.db, action))
+	require.NoError(t, executeInput.initialize(tr.tx, action, nil))
 
 	// Create job and rollback data and update to rollback from the first stage
 	deployment := executeInput.getDeployment()
-	require.NoError(t, deployment.setJob(tr.db, td.jobName1, nil))
-	require.NoError(t, deployment.setRollbackStatus(tr.db, std.errRollback))
+	require.NoError(t, deployment.setJob(tr.tx, td.jobName1, nil))
+	require.NoError(t, deployment.setRollbackStatus(tr.tx, std.errRollback))
 	// Job data
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName1, DeploymentJobInput, &std.job1InputData))
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName1, DeploymentJobData, &std.job1JobData))
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName2, DeploymentJobInput, &std.job2InputData))
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName2, DeploymentJobData, &std.job2JobData))
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName3, DeploymentJobInput, &std.job3InputData))
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName3, DeploymentJobData, &std.job3JobData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobInput, &std.job1InputData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobData, &std.job1JobData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName1, DeploymentJobOutput, &std.job2InputData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName2, DeploymentJobInput, &std.job2InputData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName2, DeploymentJobData, &std.job2JobData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName2, DeploymentJobOutput, &std.job3InputData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName3, DeploymentJobInput, &std.job3InputData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName3, DeploymentJobData, &std.job3JobData))
 	// Job errors
-	require.NoError(t, deployment.addJobError(tr.db, &td.jobName3, std.errExecute))
+	require.NoError(t, deployment.addJobError(tr.tx, &td.jobName3, std.errExecute))
 	// Rollback data
-	require.NoError(t, deployment.SetJobData(tr.db, &td.jobName3, DeploymentJobData, &std.jobRollbackJobData))
+	require.NoError(t, deployment.SetJobData(tr.tx, &td.jobName3, DeploymentJobData, &std.jobRollbackJobData))
 
 	// Execute the action
 	deployment = std.execute(t, tr.store, tr.db, service, jobs, executeInput, nil, true)
 
 	testServiceValidateRollbackExecute(t, tr.db, deployment, jobCount)
 }
+
+func TestServiceStop(t *testing.T) {
+	td := getTestData(t)
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	started := make(chan struct{})
+	var rollbackCtxErr error
+	rollbackCalled := false
+	action, err := NewAction(Jobs{
+		{
+			Name: "block",
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value interface{}) (interface{}, error) {
+
+				close(started)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			RollbackHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value interface{}, err error) (interface{}, error) {
+
+				rollbackCalled = true
+				rollbackCtxErr = ctx.Err()
+				return nil, nil
+			},
+			InputType:  NilJobDataType,
+			OutputType: NilJobDataType,
+		},
+		{
+			Name: "unreachable",
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value interface{}) (interface{}, error) {
+
+				t.Log("Job run after the deployment was stopped.")
+				t.Fail()
+				return value, nil
+			},
+			InputType:  NilJobDataType,
+			OutputType: NilJobDataType,
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+		GroupID:    "test",
+	}
+	done := make(chan error)
+	go func() {
+		done <- service.Execute(context.Background(), nil, tx, executeInput, nil)
+	}()
+
+	// Stop the deployment while the first job is running
+	<-started
+	require.NoError(t, service.Stop(tx, "test"))
+
+	err = <-done
+	require.True(t, errors.Is(err, ErrExecutionStopped))
+
+	// The deployment should have been rolled back with a context that was not cancelled
+	assert.True(t, rollbackCalled)
+	assert.NoError(t, rollbackCtxErr)
+
+	deployment, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.True(t, deployment.StopRequested)
+	assert.True(t, deployment.isFinished())
+	assert.Equal(t, ErrExecutionStopped.Error(), *deployment.RollbackError)
+
+	// Finished deployments cannot be stopped
+	require.True(t, errors.Is(service.Stop(tx, "test"), ErrDeploymentNotRunning))
+
+	// Missing deployments cannot be stopped
+	require.True(t, errors.Is(service.Stop(tx, "missing"), ErrDeploymentNotFound))
+}
+
+func TestServiceStopBetweenJobs(t *testing.T) {
+	td := getTestData(t)
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	var rolledBack []string
+	createJob := func(name string, execute JobFunc) *Job {
+		return &Job{
+			Name:    name,
+			Execute: execute,
+			RollbackHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value interface{}, err error) (interface{}, error) {
+
+				rolledBack = append(rolledBack, name)
+				return nil, nil
+			},
+			InputType:  NilJobDataType,
+			OutputType: NilJobDataType,
+		}
+	}
+
+	action, err := NewAction(Jobs{
+		createJob("first", func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}) (interface{}, error) {
+
+			return value, nil
+		}),
+		// Stop the deployment after the job finishes
+		createJob("stop", func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}) (interface{}, error) {
+
+			return value, service.Stop(tx, deployment.UUID)
+		}),
+		createJob("unreachable", func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+			value interface{}) (interface{}, error) {
+
+			t.Log("Job run after the deployment was stopped.")
+			t.Fail()
+			return value, nil
+		}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+		GroupID:    "test",
+	}
+	err = service.Execute(context.Background(), nil, tx, executeInput, nil)
+	require.True(t, errors.Is(err, ErrExecutionStopped))
+
+	// Only jobs that ran should have been rolled back
+	assert.Equal(t, []string{"stop", "first"}, rolledBack)
+
+	deployment, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.True(t, deployment.isFinished())
+	assert.Equal(t, "first", deployment.CurrentJob)
+}
+
+func TestServiceStopResumedDeployment(t *testing.T) {
+	td := getTestData(t)
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	executed := false
+	rollbackCalled := false
+	action, err := NewAction(Jobs{
+		{
+			Name: "job",
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value interface{}) (interface{}, error) {
+
+				executed = true
+				return value, nil
+			},
+			RollbackHandler: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+				value interface{}, err error) (interface{}, error) {
+
+				rollbackCalled = true
+				return nil, nil
+			},
+			InputType:  NilJobDataType,
+			OutputType: NilJobDataType,
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, td.actionName, action))
+
+	// Create a deployment and stop it before it is executed (e.g. while the server was restarting)
+	executeInput := &ExecuteInput{
+		ActionName: td.actionName,
+		GroupID:    "test",
+	}
+	require.NoError(t, executeInput.initialize(tx, action, nil))
+	require.NoError(t, service.Stop(tx, "test"))
+
+	// Updating the deployment should not clear the stop request
+	deployment := executeInput.getDeployment()
+	require.NoError(t, tx.UpdateDeployment(deployment))
+
+	err = service.Execute(context.Background(), nil, tx, executeInput, nil)
+	require.True(t, errors.Is(err, ErrExecutionStopped))
+	assert.False(t, executed)
+	assert.True(t, rollbackCalled)
+}
@@ -0,0 +1,71 @@
+package actions
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName is the name of the tracer used to create action spans.
+	tracerName = "github.com/gazebo-web/cloudsim/v4/pkg/actions"
+
+	// Span attribute keys
+	attributeDeploymentUUID          = attribute.Key("cloudsim.deployment.uuid")
+	attributeDeploymentAction        = attribute.Key("cloudsim.deployment.action")
+	attributeDeploymentActionVersion = attribute.Key("cloudsim.deployment.action_version")
+	attributeDeploymentResumed       = attribute.Key("cloudsim.deployment.resumed")
+	attributeDeploymentPreviousTrace = attribute.Key("cloudsim.deployment.previous_trace_id")
+	attributeJobName                 = attribute.Key("cloudsim.job.name")
+	attributeJobIndex                = attribute.Key("cloudsim.job.index")
+)
+
+// startSpan starts a new span as a child of the span contained in `ctx`.
+// Tracing is optional. Spans are only recorded if `ctx` contains a span created by a recording tracer provider, and
+// are created with that tracer provider. Otherwise, a no-op span is returned.
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(tracerName)
+	return tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// endSpan ends a span, recording `err` if it is not nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startDeploymentSpan starts the span of a deployment execution and records its trace ID in the deployment.
+func startDeploymentSpan(ctx context.Context, tx DeploymentStore, deployment *Deployment,
+	resumed bool) (context.Context, trace.Span, error) {
+
+	attributes := []attribute.KeyValue{
+		attributeDeploymentUUID.String(deployment.UUID),
+		attributeDeploymentAction.String(deployment.Action),
+		attributeDeploymentActionVersion.Int(deployment.ActionVersion),
+		attributeDeploymentResumed.Bool(resumed),
+	}
+	if deployment.TraceID != nil {
+		attributes = append(attributes, attributeDeploymentPreviousTrace.String(*deployment.TraceID))
+	}
+
+	ctx, span := startSpan(ctx, "deployment "+deployment.Action, attributes...)
+
+	// Only record IDs of traces that are being exported
+	spanContext := span.SpanContext()
+	if !spanContext.IsValid() || !spanContext.IsSampled() {
+		return ctx, span, nil
+	}
+
+	traceID := spanContext.TraceID().String()
+	deployment.TraceID = &traceID
+	if err := tx.UpdateDeployment(deployment); err != nil {
+		endSpan(span, err)
+		return nil, nil, err
+	}
+
+	return ctx, span, nil
+}
@@ -0,0 +1,107 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+// newTestTracerProvider returns a tracer provider that exports spans to an in-memory exporter.
+func newTestTracerProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+// getTestSpan returns the exported span with the given name.
+func getTestSpan(t *testing.T, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+	}
+
+	require.Failf(t, "span not found", "span [%s] was not exported", name)
+	return tracetest.SpanStub{}
+}
+
+func TestExecuteTracing(t *testing.T) {
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	jobs := Jobs{
+		createLeaseTestJob("a", func(tx DeploymentStore, deployment *Deployment) error {
+			return nil
+		}),
+		createLeaseTestJob("b", func(tx DeploymentStore, deployment *Deployment) error {
+			return assert.AnError
+		}),
+	}
+	jobs[0].RollbackHandler = func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment,
+		value interface{}, err error) (interface{}, error) {
+
+		return nil, nil
+	}
+	action, err := NewAction(jobs)
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, "traced", action))
+
+	tracerProvider, exporter := newTestTracerProvider()
+	ctx, root := tracerProvider.Tracer("test").Start(context.Background(), "root")
+
+	executeInput := &ExecuteInput{ActionName: "traced", GroupID: "test"}
+	err = service.Execute(ctx, nil, tx, executeInput, nil)
+	require.True(t, errors.Is(err, assert.AnError))
+	root.End()
+
+	// The deployment span is a child of the span in the context
+	deploymentSpan := getTestSpan(t, exporter, "deployment traced")
+	assert.Equal(t, root.SpanContext().SpanID(), deploymentSpan.Parent.SpanID())
+	assert.Equal(t, codes.Error, deploymentSpan.Status.Code)
+	assert.Contains(t, deploymentSpan.Attributes, attributeDeploymentUUID.String("test"))
+
+	// Jobs and rollback handlers have their own spans
+	for _, name := range []string{"job a", "job b", "rollback a"} {
+		span := getTestSpan(t, exporter, name)
+		assert.Equal(t, deploymentSpan.SpanContext.SpanID(), span.Parent.SpanID(), name)
+	}
+	assert.Equal(t, codes.Error, getTestSpan(t, exporter, "job b").Status.Code)
+	assert.Equal(t, codes.Unset, getTestSpan(t, exporter, "job a").Status.Code)
+
+	// The trace ID is recorded in the deployment
+	stored, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	require.NotNil(t, stored.TraceID)
+	assert.Equal(t, root.SpanContext().TraceID().String(), *stored.TraceID)
+}
+
+func TestExecuteWithoutTracing(t *testing.T) {
+	service := newTestService(t)
+	tx := NewMemoryDeploymentStore()
+
+	// Reset the job data type registry
+	jobDataTypeRegistry = newDataTypeRegistry()
+
+	action, err := NewAction(Jobs{
+		createLeaseTestJob("a", func(tx DeploymentStore, deployment *Deployment) error {
+			return nil
+		}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.RegisterAction(nil, "traced", action))
+
+	executeInput := &ExecuteInput{ActionName: "traced", GroupID: "test"}
+	require.NoError(t, service.Execute(context.Background(), nil, tx, executeInput, nil))
+
+	// Deployments executed without a span in the context are not traced
+	stored, err := tx.GetDeployment("test")
+	require.NoError(t, err)
+	assert.Nil(t, stored.TraceID)
+}
@@ -1,7 +1,7 @@
 package actions
 
 import (
-	"github.com/jinzhu/gorm"
+	"context"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -33,7 +33,7 @@ func getTestData(t *testing.T) testData {
 	createJob := func(name string) *Job {
 		return &Job{
 			Name: name,
-			Execute: func(store Store, tx *gorm.DB, deployment *Deployment, value interface{}) (interface{}, error) {
+			Execute: func(ctx context.Context, store Store, tx DeploymentStore, deployment *Deployment, value interface{}) (interface{}, error) {
 				return value, nil
 			},
 			InputType:  NilJobDataType,
@@ -31,9 +31,19 @@ func (s *services) Simulations() simulations.Service {
 }
 
 // NewServices initializes a new Application Services implementation.
+// Status changes made through the simulation service are validated against the simulation status state machine, see
+// simulations.NewValidatedService.
 func NewServices(simulation simulations.Service, user users.Service) Services {
+	return NewServicesWithHistory(simulation, user, nil)
+}
+
+// NewServicesWithHistory initializes a new Application Services implementation that validates the status changes made
+// through the simulation service, and records them in `history`.
+func NewServicesWithHistory(simulation simulations.Service, user users.Service,
+	history simulations.TransitionHistory) Services {
+
 	return &services{
-		simulation: simulation,
+		simulation: simulations.NewValidatedService(simulation, history),
 		user:       user,
 	}
 }
@@ -0,0 +1,150 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+var (
+	// ErrSimulationNotStopped is returned when charging a simulation that has not been launched or has not stopped.
+	ErrSimulationNotStopped = errors.New("simulation has not stopped")
+)
+
+// Config contains the configuration of a Charger.
+type Config struct {
+	// Services is used to persist simulation rates, mark simulations as charged and find simulations that were never
+	// charged.
+	Services simulations.Service `validate:"required"`
+	// Ledger is used to record charges.
+	Ledger Ledger `validate:"required"`
+}
+
+// ReprocessReport contains the result of a Charger.Reprocess run.
+type ReprocessReport struct {
+	// Charged contains the ledger entries of the simulations that were charged.
+	Charged []*Entry
+	// Errors contains the errors returned while charging simulations, indexed by group id.
+	Errors map[simulations.GroupID]error
+}
+
+// Charger charges simulations for the time they run.
+// Simulations get a rate when they are launched, and are charged once they stop. The cost of a simulation is
+// calculated by the simulation itself, see simulations.Simulation.GetCost.
+type Charger interface {
+	// SetRate sets the rate of a simulation from the cost of the machines requested to run it.
+	// This is intended to be called when the simulation is launched.
+	SetRate(sim simulations.Simulation, m machines.Machines, inputs []machines.CreateMachinesInput) error
+	// Charge calculates the final cost of a stopped simulation, records it in the ledger and marks the simulation as
+	// charged. Charges are idempotent: simulations that were already charged are not charged again, and the existing
+	// ledger entry is returned. Returns ErrAlreadyCharged if the simulation was charged without being recorded in the
+	// ledger.
+	Charge(sim simulations.Simulation) (*Entry, error)
+	// Reprocess charges the simulations that stopped but were never charged.
+	Reprocess(ctx context.Context) (*ReprocessReport, error)
+}
+
+// charger is a Charger implementation.
+type charger struct {
+	Config
+}
+
+// NewCharger initializes a new Charger.
+func NewCharger(config Config) (Charger, error) {
+	if err := validator.New().Struct(config); err != nil {
+		return nil, err
+	}
+
+	return &charger{
+		Config: config,
+	}, nil
+}
+
+// SetRate sets the rate of a simulation from the cost of the machines requested to run it.
+func (c *charger) SetRate(sim simulations.Simulation, m machines.Machines, inputs []machines.CreateMachinesInput) error {
+	rate, err := m.CalculateCost(inputs)
+	if err != nil {
+		return err
+	}
+
+	sim.SetRate(rate)
+	return c.Services.Update(sim.GetGroupID(), sim)
+}
+
+// Charge calculates the final cost of a stopped simulation, records it in the ledger and marks it as charged.
+func (c *charger) Charge(sim simulations.Simulation) (*Entry, error) {
+	if sim.GetChargedAt() != nil {
+		// Simulations charged before being recorded in the ledger don't have an entry
+		entry, err := c.Ledger.Get(sim.GetGroupID())
+		if errors.Is(err, ErrEntryNotFound) {
+			return nil, ErrAlreadyCharged
+		}
+		return entry, err
+	}
+
+	launchedAt := sim.GetLaunchedAt()
+	stoppedAt := sim.GetStoppedAt()
+	if launchedAt == nil || stoppedAt == nil {
+		return nil, ErrSimulationNotStopped
+	}
+
+	amount, rate, err := sim.GetCost()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		GroupID:       sim.GetGroupID(),
+		Owner:         sim.GetOwner(),
+		Amount:        amount,
+		Currency:      rate.Currency,
+		RateAmount:    rate.Amount,
+		RateFrequency: rate.Frequency,
+		LaunchedAt:    *launchedAt,
+		StoppedAt:     *stoppedAt,
+	}
+
+	err = c.Ledger.Record(entry)
+	if errors.Is(err, ErrAlreadyCharged) {
+		// The simulation may have been recorded without being marked as charged
+		entry, err = c.Ledger.Get(sim.GetGroupID())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Services.MarkCharged(sim.GetGroupID()); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Reprocess charges the simulations that stopped but were never charged.
+func (c *charger) Reprocess(ctx context.Context) (*ReprocessReport, error) {
+	report := &ReprocessReport{
+		Errors: make(map[simulations.GroupID]error),
+	}
+
+	sims, err := c.Services.ListUncharged()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sim := range sims {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+
+		entry, err := c.Charge(sim)
+		if err != nil {
+			report.Errors[sim.GetGroupID()] = err
+			continue
+		}
+		report.Charged = append(report.Charged, entry)
+	}
+
+	return report, nil
+}
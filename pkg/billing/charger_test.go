@@ -0,0 +1,198 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/calculator"
+	cloudfake "github.com/gazebo-web/cloudsim/v4/pkg/cloud/fake"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// newTestSimulation returns a simulation that ran between `launchedAt` and `stoppedAt`.
+func newTestSimulation(groupID simulations.GroupID, launchedAt, stoppedAt *time.Time) simulations.Simulation {
+	owner := "owner"
+	sim := fake.NewSimulation(groupID, simulations.StatusTerminated, simulations.SimSingle, nil, "", time.Hour,
+		&owner, launchedAt)
+	sim.SetRate(calculator.Rate{Amount: 100, Currency: "usd", Frequency: time.Hour})
+	sim = fake.WithCost(sim, 100, nil)
+	return fake.WithStoppedAt(sim, stoppedAt)
+}
+
+func newTestCharger(t *testing.T) (Charger, *fake.Service, Ledger) {
+	services := fake.NewService()
+	ledger := NewMemoryLedger()
+
+	c, err := NewCharger(Config{
+		Services: services,
+		Ledger:   ledger,
+	})
+	require.NoError(t, err)
+
+	return c, services, ledger
+}
+
+func TestNewCharger_InvalidConfig(t *testing.T) {
+	_, err := NewCharger(Config{})
+	assert.Error(t, err)
+}
+
+func TestCharger_SetRate(t *testing.T) {
+	c, services, _ := newTestCharger(t)
+
+	sim := newTestSimulation("sim", nil, nil)
+	rate := calculator.Rate{Amount: 250, Currency: "usd", Frequency: time.Hour}
+	inputs := []machines.CreateMachinesInput{{}}
+
+	m := cloudfake.NewMachines()
+	m.On("CalculateCost", inputs).Return(rate, nil)
+	services.On("Update", simulations.GroupID("sim")).Return(nil)
+
+	require.NoError(t, c.SetRate(sim, m, inputs))
+	assert.Equal(t, rate, sim.GetRate())
+	services.AssertExpectations(t)
+}
+
+func TestCharger_Charge(t *testing.T) {
+	c, services, ledger := newTestCharger(t)
+	services.On("MarkCharged", simulations.GroupID("sim")).Return(nil)
+
+	launchedAt := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	stoppedAt := launchedAt.Add(90 * time.Minute)
+	sim := fake.WithCost(newTestSimulation("sim", &launchedAt, &stoppedAt), 200, nil)
+
+	entry, err := c.Charge(sim)
+	require.NoError(t, err)
+
+	// The amount is the cost calculated by the simulation
+	assert.Equal(t, uint(200), entry.Amount)
+	assert.Equal(t, "usd", entry.Currency)
+	assert.Equal(t, sim.GetRate(), entry.Rate())
+	assert.Equal(t, "owner", *entry.Owner)
+	assert.Equal(t, launchedAt, entry.LaunchedAt)
+	assert.Equal(t, stoppedAt, entry.StoppedAt)
+	services.AssertNumberOfCalls(t, "MarkCharged", 1)
+
+	recorded, err := ledger.Get("sim")
+	require.NoError(t, err)
+	assert.Equal(t, entry.Amount, recorded.Amount)
+
+	// Charging a simulation again returns the existing entry
+	sim.SetRate(calculator.Rate{Amount: 1000, Currency: "usd", Frequency: time.Hour})
+	again, err := c.Charge(fake.WithCost(sim, 1500, nil))
+	require.NoError(t, err)
+	assert.Equal(t, uint(200), again.Amount)
+	assert.Equal(t, recorded.ID, again.ID)
+}
+
+func TestCharger_ChargeNotStopped(t *testing.T) {
+	c, services, _ := newTestCharger(t)
+
+	launchedAt := time.Now()
+	_, err := c.Charge(newTestSimulation("sim", &launchedAt, nil))
+	assert.Equal(t, ErrSimulationNotStopped, err)
+
+	_, err = c.Charge(newTestSimulation("sim", nil, nil))
+	assert.Equal(t, ErrSimulationNotStopped, err)
+	services.AssertNotCalled(t, "MarkCharged", simulations.GroupID("sim"))
+}
+
+func TestCharger_ChargeAlreadyCharged(t *testing.T) {
+	c, services, ledger := newTestCharger(t)
+
+	launchedAt := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	stoppedAt := launchedAt.Add(time.Hour)
+	chargedAt := stoppedAt.Add(time.Minute)
+
+	// Simulations charged before the ledger existed are not charged again
+	sim := fake.WithChargedAt(newTestSimulation("sim-1", &launchedAt, &stoppedAt), &chargedAt)
+	_, err := c.Charge(sim)
+	assert.Equal(t, ErrAlreadyCharged, err)
+
+	_, err = ledger.Get("sim-1")
+	assert.Equal(t, ErrEntryNotFound, err)
+
+	// Simulations charged and recorded in the ledger return the existing entry
+	require.NoError(t, ledger.Record(&Entry{GroupID: "sim-2", Amount: 50}))
+	sim = fake.WithChargedAt(newTestSimulation("sim-2", &launchedAt, &stoppedAt), &chargedAt)
+	entry, err := c.Charge(sim)
+	require.NoError(t, err)
+	assert.Equal(t, uint(50), entry.Amount)
+
+	services.AssertNotCalled(t, "MarkCharged", simulations.GroupID("sim-1"))
+	services.AssertNotCalled(t, "MarkCharged", simulations.GroupID("sim-2"))
+}
+
+func TestCharger_ChargeCostError(t *testing.T) {
+	c, services, ledger := newTestCharger(t)
+
+	launchedAt := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	stoppedAt := launchedAt.Add(time.Hour)
+	costErr := errors.New("cost error")
+	sim := fake.WithCost(newTestSimulation("sim", &launchedAt, &stoppedAt), 0, costErr)
+
+	_, err := c.Charge(sim)
+	assert.Equal(t, costErr, err)
+
+	_, err = ledger.Get("sim")
+	assert.Equal(t, ErrEntryNotFound, err)
+	services.AssertNotCalled(t, "MarkCharged", simulations.GroupID("sim"))
+}
+
+func TestCharger_Reprocess(t *testing.T) {
+	launchedAt := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	stoppedAt := launchedAt.Add(time.Hour)
+
+	c, services, ledger := newTestCharger(t)
+	services.On("ListUncharged").Return([]simulations.Simulation{
+		newTestSimulation("sim-1", &launchedAt, &stoppedAt),
+		newTestSimulation("sim-2", &launchedAt, nil),
+		newTestSimulation("sim-3", &launchedAt, &stoppedAt),
+	}, nil)
+	services.On("MarkCharged", simulations.GroupID("sim-1")).Return(nil)
+	services.On("MarkCharged", simulations.GroupID("sim-3")).Return(nil)
+
+	// sim-3 was recorded in the ledger but never marked as charged
+	require.NoError(t, ledger.Record(&Entry{GroupID: "sim-3", Amount: 50}))
+
+	report, err := c.Reprocess(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, report.Charged, 2)
+	assert.Equal(t, simulations.GroupID("sim-1"), report.Charged[0].GroupID)
+	assert.Equal(t, uint(100), report.Charged[0].Amount)
+	assert.Equal(t, simulations.GroupID("sim-3"), report.Charged[1].GroupID)
+	assert.Equal(t, uint(50), report.Charged[1].Amount)
+
+	assert.Equal(t, map[simulations.GroupID]error{"sim-2": ErrSimulationNotStopped}, report.Errors)
+	services.AssertExpectations(t)
+	services.AssertNumberOfCalls(t, "MarkCharged", 2)
+}
+
+func TestCharger_ReprocessListError(t *testing.T) {
+	c, services, _ := newTestCharger(t)
+	listErr := errors.New("list error")
+	services.On("ListUncharged").Return([]simulations.Simulation(nil), listErr)
+
+	_, err := c.Reprocess(context.Background())
+	assert.Equal(t, listErr, err)
+}
+
+func TestMemoryLedger(t *testing.T) {
+	ledger := NewMemoryLedger()
+
+	_, err := ledger.Get("sim")
+	assert.Equal(t, ErrEntryNotFound, err)
+
+	require.NoError(t, ledger.Record(&Entry{GroupID: "sim", Amount: 100}))
+	assert.Equal(t, ErrAlreadyCharged, ledger.Record(&Entry{GroupID: "sim", Amount: 200}))
+
+	entry, err := ledger.Get("sim")
+	require.NoError(t, err)
+	assert.Equal(t, uint(100), entry.Amount)
+}
@@ -0,0 +1,22 @@
+package billing
+
+import (
+	gormUtils "github.com/gazebo-web/gz-go/v7/database/gorm"
+	"github.com/jinzhu/gorm"
+)
+
+// MigrateDB migrates billing database models, indexes and keys.
+func MigrateDB(tx *gorm.DB) error {
+	return gormUtils.MigrateModels(
+		tx,
+		&Entry{},
+	)
+}
+
+// DropDB drops billing database models, indexes and keys.
+func DropDB(tx *gorm.DB) error {
+	return gormUtils.DropModels(
+		tx,
+		&Entry{},
+	)
+}
@@ -0,0 +1,59 @@
+package billing
+
+import (
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/calculator"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+var (
+	// ErrAlreadyCharged is returned when a charge is recorded for a simulation that has already been charged.
+	ErrAlreadyCharged = errors.New("simulation already charged")
+	// ErrEntryNotFound is returned when a ledger entry does not exist.
+	ErrEntryNotFound = errors.New("ledger entry not found")
+)
+
+// Entry is a ledger entry with the charge of a single simulation.
+type Entry struct {
+	gorm.Model
+	// GroupID identifies the charged simulation. Simulations are charged at most once.
+	GroupID simulations.GroupID `gorm:"not null;unique"`
+	// Owner is the owner of the charged simulation.
+	Owner *string
+	// Amount is the money charged in the minimum Currency value (e.g. cents for USD).
+	Amount uint
+	// Currency is the ISO 4217 currency code in lowercase format.
+	Currency string
+	// RateAmount is the amount of the rate used to calculate the charge.
+	RateAmount uint
+	// RateFrequency is the frequency of the rate used to calculate the charge.
+	RateFrequency time.Duration
+	// LaunchedAt is the time the simulation was launched.
+	LaunchedAt time.Time
+	// StoppedAt is the time the simulation stopped.
+	StoppedAt time.Time
+}
+
+// TableName defines the database table name for ledger entries.
+func (Entry) TableName() string {
+	return "billing_ledger"
+}
+
+// Rate returns the rate used to calculate the charge.
+func (e *Entry) Rate() calculator.Rate {
+	return calculator.Rate{
+		Amount:    e.RateAmount,
+		Currency:  e.Currency,
+		Frequency: e.RateFrequency,
+	}
+}
+
+// Ledger keeps a record of the charges made to simulations.
+type Ledger interface {
+	// Record records a charge. Returns ErrAlreadyCharged if a charge was already recorded for the simulation.
+	Record(entry *Entry) error
+	// Get returns the charge recorded for a simulation. Returns ErrEntryNotFound if the simulation was not charged.
+	Get(groupID simulations.GroupID) (*Entry, error)
+}
@@ -0,0 +1,60 @@
+package billing
+
+import (
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/jinzhu/gorm"
+)
+
+// gormLedger is a Ledger implementation that persists entries in a relational database using gorm.
+// The database tables can be created with MigrateDB.
+type gormLedger struct {
+	// db contains the database connection or transaction used to persist entries.
+	db *gorm.DB
+}
+
+// NewGormLedger returns a Ledger that persists entries using the given gorm database connection or transaction.
+func NewGormLedger(db *gorm.DB) Ledger {
+	return &gormLedger{
+		db: db,
+	}
+}
+
+// Record records a charge.
+// Entries have a unique group id, so concurrent charges of the same simulation are rejected by the database.
+func (l *gormLedger) Record(entry *Entry) error {
+	_, err := l.Get(entry.GroupID)
+	if err == nil {
+		return ErrAlreadyCharged
+	}
+	if err != ErrEntryNotFound {
+		return err
+	}
+
+	if err := l.db.Model(&Entry{}).Create(entry).Error; err != nil {
+		// Check if the entry was recorded concurrently
+		if _, getErr := l.Get(entry.GroupID); getErr == nil {
+			return ErrAlreadyCharged
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Get returns the charge recorded for a simulation.
+func (l *gormLedger) Get(groupID simulations.GroupID) (*Entry, error) {
+	entry := &Entry{}
+
+	err := l.db.
+		Where("group_id = ?", groupID).
+		First(entry).
+		Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,56 @@
+package billing
+
+import (
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"sync"
+	"time"
+)
+
+// memoryLedger is a Ledger implementation that keeps entries in memory.
+// It is intended for unit tests.
+type memoryLedger struct {
+	// lock is used to allow concurrent access to the ledger.
+	lock sync.RWMutex
+	// entries contains the recorded entries, indexed by group id.
+	entries map[simulations.GroupID]*Entry
+}
+
+// NewMemoryLedger returns a Ledger that keeps entries in memory.
+func NewMemoryLedger() Ledger {
+	return &memoryLedger{
+		entries: make(map[simulations.GroupID]*Entry),
+	}
+}
+
+// Record records a charge.
+func (l *memoryLedger) Record(entry *Entry) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, ok := l.entries[entry.GroupID]; ok {
+		return ErrAlreadyCharged
+	}
+
+	entry.ID = uint(len(l.entries) + 1)
+	entry.CreatedAt = time.Now()
+	entry.UpdatedAt = entry.CreatedAt
+
+	stored := *entry
+	l.entries[entry.GroupID] = &stored
+
+	return nil
+}
+
+// Get returns the charge recorded for a simulation.
+func (l *memoryLedger) Get(groupID simulations.GroupID) (*Entry, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	entry, ok := l.entries[groupID]
+	if !ok {
+		return nil, ErrEntryNotFound
+	}
+
+	out := *entry
+	return &out, nil
+}
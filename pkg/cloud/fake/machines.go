@@ -1,6 +1,7 @@
 package fake
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/calculator"
 	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
 	"github.com/stretchr/testify/mock"
@@ -44,8 +45,8 @@ func (m *Machines) Count(input machines.CountMachinesInput) int {
 }
 
 // WaitOK mocks the WaitOK method.
-func (m *Machines) WaitOK(input []machines.WaitMachinesOKInput) error {
-	args := m.Called(input)
+func (m *Machines) WaitOK(ctx context.Context, input []machines.WaitMachinesOKInput) error {
+	args := m.Called(ctx, input)
 	return args.Error(0)
 }
 
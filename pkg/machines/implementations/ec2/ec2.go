@@ -2,6 +2,7 @@ package ec2
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
@@ -508,7 +509,7 @@ func (m *ec2Machines) Count(input machines.CountMachinesInput) int {
 }
 
 // WaitOK waits for EC2 machines to be in the OK status.
-func (m *ec2Machines) WaitOK(input []machines.WaitMachinesOKInput) error {
+func (m *ec2Machines) WaitOK(ctx context.Context, input []machines.WaitMachinesOKInput) error {
 	m.Logger.Debug(fmt.Sprintf("Waiting for machines to be OK: %+v", input))
 
 	// Collect all instance ids in a single slice
@@ -518,7 +519,7 @@ func (m *ec2Machines) WaitOK(input []machines.WaitMachinesOKInput) error {
 	}
 
 	// Perform request
-	err := m.API.WaitUntilInstanceStatusOk(&ec2.DescribeInstanceStatusInput{
+	err := m.API.WaitUntilInstanceStatusOkWithContext(ctx, &ec2.DescribeInstanceStatusInput{
 		InstanceIds: aws.StringSlice(instances),
 	})
 	if err != nil {
@@ -1,6 +1,7 @@
 package machines
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/calculator"
 	"github.com/pkg/errors"
 )
@@ -37,10 +38,32 @@ var (
 	ErrRetryable = errors.New("retryable error")
 )
 
+// retryableError wraps an error to signal that the operation that returned it is retryable.
+// It matches both ErrRetryable and the wrapped error.
+type retryableError struct {
+	err error
+}
+
+// Error returns the message of the wrapped error.
+func (e *retryableError) Error() string {
+	return e.err.Error() + ": " + ErrRetryable.Error()
+}
+
+// Is returns true if target is ErrRetryable.
+func (e *retryableError) Is(target error) bool {
+	return target == ErrRetryable
+}
+
+// Unwrap returns the wrapped error.
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
 // WrapRetryableError wraps an error with the ErrRetryable error.
-// This is typically done to signal that an API call is retryable.
+// This is typically done to signal that an API call is retryable. The original error can still be checked with
+// errors.Is.
 func WrapRetryableError(err error) error {
-	return errors.Wrap(ErrRetryable, err.Error())
+	return &retryableError{err: err}
 }
 
 // ErrorIsRetryable checks that an error is wrapped with the ErrRetryable error.
@@ -276,7 +299,8 @@ type Machines interface {
 	Count(input CountMachinesInput) int
 
 	// WaitOK is used to wait for the given machines input to be OK.
-	WaitOK(input []WaitMachinesOKInput) error
+	// Waiting stops and returns an error if the context is done.
+	WaitOK(ctx context.Context, input []WaitMachinesOKInput) error
 
 	// List returns a list of machines based on the given input.
 	List(input ListMachinesInput) (*ListMachinesOutput, error)
@@ -0,0 +1,102 @@
+package machines
+
+import (
+	"context"
+	"github.com/gazebo-web/cloudsim/v4/pkg/calculator"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer used to create machines spans.
+const tracerName = "github.com/gazebo-web/cloudsim/v4/pkg/machines"
+
+// WithTracing initializes a new Machines that creates a span for every call to the base Machines implementation.
+// Most Machines methods do not receive a context, so their spans are created as root spans and are not part of the
+// trace of the caller (e.g. the deployment trace of an action). They must be correlated with the caller's spans by
+// time, using the machine labels, which are added as span attributes to allow finding the spans of a specific
+// deployment. WaitOK spans are created as children of the span in the context it receives.
+func WithTracing(base Machines, tracerProvider trace.TracerProvider) Machines {
+	return &tracing{
+		Machines: base,
+		tracer:   tracerProvider.Tracer(tracerName),
+	}
+}
+
+// tracing is a Machines implementation that traces calls to another Machines implementation.
+type tracing struct {
+	Machines
+	tracer trace.Tracer
+}
+
+// start starts a new span for a Machines operation.
+// Operations that do not receive a context should pass context.Background() to create a root span.
+func (t *tracing) start(ctx context.Context, operation string, attributes ...attribute.KeyValue) trace.Span {
+	_, span := t.tracer.Start(ctx, "machines."+operation, trace.WithAttributes(attributes...))
+	return span
+}
+
+// end ends a span, recording `err` if it is not nil.
+func (t *tracing) end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Create creates a set of cloud machines with a certain configuration.
+func (t *tracing) Create(input []CreateMachinesInput) (output []CreateMachinesOutput, err error) {
+	attributes := []attribute.KeyValue{attribute.Int("cloudsim.machines.inputs", len(input))}
+	for _, in := range input {
+		attributes = append(attributes, attribute.String("cloudsim.machines.type", in.Type))
+		for key, value := range in.Labels {
+			attributes = append(attributes, attribute.String("cloudsim.machines.label."+key, value))
+		}
+	}
+
+	span := t.start(context.Background(), "Create", attributes...)
+	defer func() { t.end(span, err) }()
+
+	return t.Machines.Create(input)
+}
+
+// Terminate terminates a set of cloud machines that match a set of names.
+func (t *tracing) Terminate(input TerminateMachinesInput) (err error) {
+	span := t.start(context.Background(), "Terminate", attribute.StringSlice("cloudsim.machines.instances", input.Instances))
+	defer func() { t.end(span, err) }()
+
+	return t.Machines.Terminate(input)
+}
+
+// Count returns the number of cloud machines that match a set of selectors.
+func (t *tracing) Count(input CountMachinesInput) int {
+	span := t.start(context.Background(), "Count")
+	defer span.End()
+
+	return t.Machines.Count(input)
+}
+
+// WaitOK is used to wait for the given machines input to be OK.
+func (t *tracing) WaitOK(ctx context.Context, input []WaitMachinesOKInput) (err error) {
+	span := t.start(ctx, "WaitOK", attribute.Int("cloudsim.machines.inputs", len(input)))
+	defer func() { t.end(span, err) }()
+
+	return t.Machines.WaitOK(ctx, input)
+}
+
+// List returns a list of machines based on the given input.
+func (t *tracing) List(input ListMachinesInput) (output *ListMachinesOutput, err error) {
+	span := t.start(context.Background(), "List")
+	defer func() { t.end(span, err) }()
+
+	return t.Machines.List(input)
+}
+
+// CalculateCost calculates the cost rate at which a group of machines would be charged for when they get created.
+func (t *tracing) CalculateCost(inputs []CreateMachinesInput) (rate calculator.Rate, err error) {
+	span := t.start(context.Background(), "CalculateCost", attribute.Int("cloudsim.machines.inputs", len(inputs)))
+	defer func() { t.end(span, err) }()
+
+	return t.Machines.CalculateCost(inputs)
+}
@@ -0,0 +1,59 @@
+package machines
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+// tracingTestMachines is a Machines implementation used to test tracing.
+type tracingTestMachines struct {
+	Machines
+	err error
+}
+
+func (m *tracingTestMachines) Create(input []CreateMachinesInput) ([]CreateMachinesOutput, error) {
+	return nil, m.err
+}
+
+func (m *tracingTestMachines) WaitOK(ctx context.Context, input []WaitMachinesOKInput) error {
+	return nil
+}
+
+func TestWithTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, parent := tracerProvider.Tracer("test").Start(context.Background(), "parent")
+
+	m := WithTracing(&tracingTestMachines{err: errors.New("test")}, tracerProvider)
+
+	_, err := m.Create([]CreateMachinesInput{
+		{
+			Type:   "g3.4xlarge",
+			Labels: map[string]string{"cloudsim-group-id": "test"},
+		},
+	})
+	assert.Error(t, err)
+
+	require.NoError(t, m.WaitOK(ctx, []WaitMachinesOKInput{{}}))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	// Create does not receive a context, so its span is a root span
+	assert.Equal(t, "machines.Create", spans[0].Name)
+	assert.False(t, spans[0].Parent.IsValid())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Contains(t, spans[0].Attributes, attribute.String("cloudsim.machines.type", "g3.4xlarge"))
+	assert.Contains(t, spans[0].Attributes, attribute.String("cloudsim.machines.label.cloudsim-group-id", "test"))
+
+	assert.Equal(t, "machines.WaitOK", spans[1].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent.SpanID())
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+}
@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/spdy"
@@ -36,6 +37,7 @@ func (e *executor) Cmd(container string, command []string) error {
 
 	// Run command
 	err := runExec(runExecInput{
+		ctx:        context.Background(),
 		kubernetes: e.API,
 		namespace:  e.pod.Namespace(),
 		name:       e.pod.Name(),
@@ -3,11 +3,13 @@ package kubernetes
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/spdy"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
 	"github.com/gazebo-web/gz-go/v7"
+	"io"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/remotecommand"
@@ -29,6 +31,33 @@ func (r *reader) File(ctx context.Context, container string, paths ...string) (*
 		r.logger.Debug(fmt.Sprintf("Reading file from paths [%+v] on pod [%s] inside the container [%s]", paths, r.pod.Name(), container))
 	}
 
+	stdout, err := r.exec(ctx, container, append([]string{"cat"}, paths...))
+	if err != nil {
+		r.logger.Debug(fmt.Sprintf("Reading file from paths [%+v] on pod [%s] failed. Error: %s", paths, r.pod.Name(), err.Error()))
+		return nil, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Reading file from paths [%+v] on pod [%s] succeeded. Output: %s", paths, r.pod.Name(), stdout.String()))
+	return stdout, nil
+}
+
+// Archive is used to read the contents of a directory from inside a container as a tar archive.
+func (r *reader) Archive(ctx context.Context, container string, path string) (*bytes.Buffer, error) {
+	r.logger.Debug(fmt.Sprintf("Archiving directory [%s] on pod [%s] inside the container [%s]", path, r.pod.Name(), container))
+
+	stdout, err := r.exec(ctx, container, []string{"tar", "-C", path, "-cf", "-", "."})
+	if err != nil {
+		r.logger.Debug(fmt.Sprintf("Archiving directory [%s] on pod [%s] failed. Error: %s", path, r.pod.Name(), err.Error()))
+		return nil, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("Archiving directory [%s] on pod [%s] succeeded. Size: %d bytes", path, r.pod.Name(), stdout.Len()))
+	return stdout, nil
+}
+
+// exec runs a command inside a container and returns its standard output.
+// If `ctx` is done before the command finishes, the context error is returned.
+func (r *reader) exec(ctx context.Context, container string, command []string) (*bytes.Buffer, error) {
 	// Prepare buffers
 	var stdout, stderr bytes.Buffer
 
@@ -42,23 +71,24 @@ func (r *reader) File(ctx context.Context, container string, paths ...string) (*
 
 	// Run command
 	err := runExec(runExecInput{
+		ctx:        ctx,
 		kubernetes: r.API,
 		namespace:  r.pod.Namespace(),
 		name:       r.pod.Name(),
-		command:    append([]string{"cat"}, paths...),
+		command:    command,
 		options:    options,
 		spdy:       r.spdyInit,
 		container:  container,
 	})
-	if err == nil {
-		r.logger.Debug(fmt.Sprintf("Reading file from paths [%+v] on pod [%s] succeeded. Output: %s", paths, r.pod.Name(), stdout.String()))
-		return &stdout, nil
+	// The output streams may still be in use if the context is done
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, parseExecError(err, &stdout, &stderr)
 	}
 
-	err = parseExecError(err, &stdout, &stderr)
-
-	r.logger.Debug(fmt.Sprintf("Reading file from paths [%+v] on pod [%s] failed. Error: %s", paths, r.pod.Name(), err.Error()))
-	return nil, err
+	return &stdout, nil
 }
 
 // Logs returns the log from the given container running inside the resource.
@@ -66,10 +96,13 @@ func (r *reader) Logs(ctx context.Context, container string, lines int64) (strin
 	r.logger.Debug(fmt.Sprintf("Reading logs from container [%s] in pod [%s]", container, r.pod.Name()))
 
 	// Prepare request to get logs
-	req := r.API.CoreV1().Pods(r.pod.Namespace()).GetLogs(r.pod.Name(), &apiv1.PodLogOptions{
+	options := &apiv1.PodLogOptions{
 		Container: container,
-		TailLines: &lines,
-	})
+	}
+	if lines > 0 {
+		options.TailLines = &lines
+	}
+	req := r.API.CoreV1().Pods(r.pod.Namespace()).GetLogs(r.pod.Name(), options)
 
 	// Open data stream
 	re, err := req.Stream(ctx)
@@ -80,8 +113,7 @@ func (r *reader) Logs(ctx context.Context, container string, lines int64) (strin
 	defer re.Close()
 
 	// Read logs
-	var logs []byte
-	_, err = re.Read(logs)
+	logs, err := io.ReadAll(re)
 
 	if err != nil {
 		r.logger.Debug(fmt.Sprintf("Reading logs from container [%s] in pod [%s] failed. Error: %s", container, r.pod.Name(), err.Error()))
@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/spdy"
@@ -19,6 +20,8 @@ var (
 
 // runExecInput is the input of runExec.
 type runExecInput struct {
+	// ctx is used to stop waiting for the command when it is done.
+	ctx context.Context
 	// kubernetes has a reference to the kubernetes client.
 	kubernetes kubernetes.Interface
 	// namespace is the namespace where the command should be executed.
@@ -46,6 +49,10 @@ func runExec(input runExecInput) (err error) {
 		}
 	}()
 
+	if err := input.ctx.Err(); err != nil {
+		return err
+	}
+
 	// TODO: Find a way to avoid this line panicking on tests.
 	req := input.kubernetes.CoreV1().RESTClient().Post().
 		Resource("pods").
@@ -73,11 +80,24 @@ func runExec(input runExecInput) (err error) {
 		return err
 	}
 
-	err = ex.Stream(input.options)
-	if err != nil {
+	// The executor does not receive a context. The stream is run in the background and abandoned if the context is
+	// done, in which case it keeps writing to the output streams until the command finishes.
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- ErrPodExecFailed
+			}
+		}()
+		done <- ex.Stream(input.options)
+	}()
+
+	select {
+	case err := <-done:
 		return err
+	case <-input.ctx.Done():
+		return input.ctx.Err()
 	}
-	return nil
 }
 
 // parseExecError parses the errors returned from runExec.
@@ -545,3 +545,20 @@ func TestPods_List(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, list, 2)
 }
+
+func TestPods_ReaderContextDone(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	logger := gz.NewLoggerNoRollbar("TestPods", gz.VerbosityDebug)
+	m := NewPods(client, spdy.NewSPDYFakeInitializer(), logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := m.Reader(ctx, resource.NewResource("test", "default", nil))
+
+	_, err := r.Archive(ctx, "gzserver", "/tmp/logs")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = r.File(ctx, "gzserver", "/tmp/logs/server.log")
+	assert.ErrorIs(t, err, context.Canceled)
+}
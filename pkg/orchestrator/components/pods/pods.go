@@ -158,5 +158,8 @@ type Executor interface {
 // Reader groups a set of methods to read files and logs from a Pod.
 type Reader interface {
 	File(ctx context.Context, container string, paths ...string) (*bytes.Buffer, error)
+	// Archive returns an uncompressed tar archive with the contents of the directory located in the given path.
+	Archive(ctx context.Context, container string, path string) (*bytes.Buffer, error)
+	// Logs returns the last `lines` lines of the logs of a container. If `lines` is zero, the entire log is returned.
 	Logs(ctx context.Context, container string, lines int64) (string, error)
 }
@@ -0,0 +1,129 @@
+package pods
+
+import (
+	"context"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/waiter"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// tracerName is the name of the tracer used to create pods spans.
+const tracerName = "github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
+
+// WithTracing initializes a new Pods that creates a span for every call to the base Pods implementation.
+// Spans are created as children of the span contained in the context passed to each method. Waiting for pod
+// conditions is also traced, as it usually is the slowest part of launching pods.
+func WithTracing(base Pods, tracerProvider trace.TracerProvider) Pods {
+	return &tracing{
+		Pods:   base,
+		tracer: tracerProvider.Tracer(tracerName),
+	}
+}
+
+// tracing is a Pods implementation that traces calls to another Pods implementation.
+type tracing struct {
+	Pods
+	tracer trace.Tracer
+}
+
+// start starts a new span for a Pods operation on the pod with the given name and namespace.
+func (t *tracing) start(ctx context.Context, operation, name, namespace string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "pods."+operation, trace.WithAttributes(
+		attribute.String("cloudsim.pods.name", name),
+		attribute.String("cloudsim.pods.namespace", namespace),
+	))
+}
+
+// endSpan ends a span, recording `err` if it is not nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Create creates a new pod.
+func (t *tracing) Create(ctx context.Context, input CreatePodInput) (pod *PodResource, err error) {
+	ctx, span := t.start(ctx, "Create", input.Name, input.Namespace)
+	defer func() { endSpan(span, err) }()
+
+	return t.Pods.Create(ctx, input)
+}
+
+// WaitForCondition returns a waiter that creates a span when waiting for the given conditions.
+func (t *tracing) WaitForCondition(ctx context.Context, resource resource.Resource,
+	condition ...resource.Condition) waiter.Waiter {
+
+	return &tracingWaiter{
+		ctx:      ctx,
+		tracing:  t,
+		resource: resource,
+		waiter:   t.Pods.WaitForCondition(ctx, resource, condition...),
+	}
+}
+
+// Delete deletes a pod.
+func (t *tracing) Delete(ctx context.Context, resource resource.Resource) (deleted resource.Resource, err error) {
+	ctx, span := t.start(ctx, "Delete", resource.Name(), resource.Namespace())
+	defer func() { endSpan(span, err) }()
+
+	return t.Pods.Delete(ctx, resource)
+}
+
+// Get returns a pod.
+func (t *tracing) Get(ctx context.Context, name, namespace string) (pod *PodResource, err error) {
+	ctx, span := t.start(ctx, "Get", name, namespace)
+	defer func() { endSpan(span, err) }()
+
+	return t.Pods.Get(ctx, name, namespace)
+}
+
+// GetIP returns the IP of a pod.
+func (t *tracing) GetIP(ctx context.Context, name string, namespace string) (ip string, err error) {
+	ctx, span := t.start(ctx, "GetIP", name, namespace)
+	defer func() { endSpan(span, err) }()
+
+	return t.Pods.GetIP(ctx, name, namespace)
+}
+
+// List returns the pods that match a selector.
+func (t *tracing) List(ctx context.Context, namespace string, selector resource.Selector) (pods []PodResource,
+	err error) {
+
+	ctx, span := t.tracer.Start(ctx, "pods.List", trace.WithAttributes(
+		attribute.String("cloudsim.pods.namespace", namespace),
+		attribute.String("cloudsim.pods.selector", selector.String()),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return t.Pods.List(ctx, namespace, selector)
+}
+
+// tracingWaiter is a waiter.Waiter implementation that traces waiting for pod conditions.
+type tracingWaiter struct {
+	ctx      context.Context
+	tracing  *tracing
+	resource resource.Resource
+	waiter   waiter.Waiter
+}
+
+// Wait waits for the pod conditions to be met.
+func (w *tracingWaiter) Wait(timeout time.Duration, frequency time.Duration) error {
+	return w.WaitContext(w.ctx, timeout, frequency)
+}
+
+// WaitContext waits for the pod conditions to be met until the context is done.
+func (w *tracingWaiter) WaitContext(ctx context.Context, timeout time.Duration, frequency time.Duration) (err error) {
+	ctx, span := w.tracing.tracer.Start(ctx, "pods.WaitForCondition", trace.WithAttributes(
+		attribute.String("cloudsim.pods.selector", w.resource.Selector().String()),
+		attribute.String("cloudsim.pods.namespace", w.resource.Namespace()),
+		attribute.String("cloudsim.pods.timeout", timeout.String()),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return w.waiter.WaitContext(ctx, timeout, frequency)
+}
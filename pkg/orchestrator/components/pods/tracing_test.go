@@ -0,0 +1,58 @@
+package pods
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/waiter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+	"time"
+)
+
+// tracingTestPods is a Pods implementation used to test tracing.
+type tracingTestPods struct {
+	Pods
+	err error
+}
+
+func (p *tracingTestPods) Create(ctx context.Context, input CreatePodInput) (*PodResource, error) {
+	return nil, p.err
+}
+
+func (p *tracingTestPods) WaitForCondition(ctx context.Context, resource resource.Resource,
+	condition ...resource.Condition) waiter.Waiter {
+
+	return waiter.NewWaitRequest(func() (bool, error) {
+		return true, nil
+	})
+}
+
+func TestWithTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, parent := tracerProvider.Tracer("test").Start(context.Background(), "parent")
+
+	p := WithTracing(&tracingTestPods{err: errors.New("test")}, tracerProvider)
+
+	_, err := p.Create(ctx, CreatePodInput{Name: "pod", Namespace: "default"})
+	assert.Error(t, err)
+
+	res := resource.NewResource("pod", "default", resource.NewSelector(map[string]string{"app": "test"}))
+	require.NoError(t, p.WaitForCondition(ctx, res, resource.ReadyCondition).Wait(time.Second, time.Millisecond))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "pods.Create", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+
+	assert.Equal(t, "pods.WaitForCondition", spans[1].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent.SpanID())
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+}
@@ -0,0 +1,22 @@
+package simulations
+
+import (
+	gormUtils "github.com/gazebo-web/gz-go/v7/database/gorm"
+	"github.com/jinzhu/gorm"
+)
+
+// MigrateDB migrates simulations database models, indexes and keys.
+func MigrateDB(tx *gorm.DB) error {
+	return gormUtils.MigrateModels(
+		tx,
+		&Transition{},
+	)
+}
+
+// DropDB drops simulations database models, indexes and keys.
+func DropDB(tx *gorm.DB) error {
+	return gormUtils.DropModels(
+		tx,
+		&Transition{},
+	)
+}
@@ -20,13 +20,15 @@ type fakeSimulation struct {
 	platform   *string
 	launchedAt *time.Time
 	rate       *calculator.Rate
+	cost       uint
+	costErr    error
 	stoppedAt  *time.Time
+	chargedAt  *time.Time
 }
 
-// GetChargedAt mocks the GetChargedAt method.
+// GetChargedAt returns the charged at field.
 func (f *fakeSimulation) GetChargedAt() *time.Time {
-	now := time.Now()
-	return &now
+	return f.chargedAt
 }
 
 // GetRate returns the rate.
@@ -46,12 +48,15 @@ func (f *fakeSimulation) GetStoppedAt() *time.Time {
 	return f.stoppedAt
 }
 
-// GetCost mocks the GetCost method.
+// GetCost returns the cost set with WithCost.
 func (f *fakeSimulation) GetCost() (uint, calculator.Rate, error) {
+	if f.costErr != nil {
+		return 0, calculator.Rate{}, f.costErr
+	}
 	if f.rate == nil {
-		return 0, calculator.Rate{}, nil
+		return f.cost, calculator.Rate{}, nil
 	}
-	return 0, *f.rate, nil
+	return f.cost, *f.rate, nil
 }
 
 // SetRate sets the given rate.
@@ -146,3 +151,36 @@ func NewSimulation(groupID simulations.GroupID, status simulations.Status, kind
 		launchedAt: launchedAt,
 	}
 }
+
+// WithPlatform returns a copy of a fake simulation that runs in the given platform.
+// `sim` must have been created with NewSimulation.
+func WithPlatform(sim simulations.Simulation, platform *string) simulations.Simulation {
+	out := *sim.(*fakeSimulation)
+	out.platform = platform
+	return &out
+}
+
+// WithCost returns a copy of a fake simulation whose GetCost method returns `amount`, or `err` if it is not nil.
+// `sim` must have been created with NewSimulation.
+func WithCost(sim simulations.Simulation, amount uint, err error) simulations.Simulation {
+	out := *sim.(*fakeSimulation)
+	out.cost = amount
+	out.costErr = err
+	return &out
+}
+
+// WithStoppedAt returns a copy of a fake simulation that stopped at the given time.
+// `sim` must have been created with NewSimulation.
+func WithStoppedAt(sim simulations.Simulation, stoppedAt *time.Time) simulations.Simulation {
+	out := *sim.(*fakeSimulation)
+	out.stoppedAt = stoppedAt
+	return &out
+}
+
+// WithChargedAt returns a copy of a fake simulation that was charged at the given time.
+// `sim` must have been created with NewSimulation.
+func WithChargedAt(sim simulations.Simulation, chargedAt *time.Time) simulations.Simulation {
+	out := *sim.(*fakeSimulation)
+	out.chargedAt = chargedAt
+	return &out
+}
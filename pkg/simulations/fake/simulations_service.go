@@ -3,6 +3,7 @@ package fake
 import (
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 // Service is a fake simulations.Service implementation.
@@ -69,6 +70,12 @@ func (s *Service) Get(groupID simulations.GroupID) (simulations.Simulation, erro
 	return sim, args.Error(1)
 }
 
+// MarkLaunched is a mock for the MarkLaunched method.
+func (s *Service) MarkLaunched(groupID simulations.GroupID, launchedAt time.Time) error {
+	args := s.Called(groupID, launchedAt)
+	return args.Error(0)
+}
+
 // MarkStopped is a mock for the MarkStopped method.
 func (s *Service) MarkStopped(groupID simulations.GroupID) error {
 	args := s.Called(groupID)
@@ -81,6 +88,26 @@ func (s *Service) MarkCharged(groupID simulations.GroupID) error {
 	return args.Error(0)
 }
 
+// ListRunning is a mock for the ListRunning method.
+func (s *Service) ListRunning() ([]simulations.Simulation, error) {
+	args := s.Called()
+	sims := args.Get(0).([]simulations.Simulation)
+	return sims, args.Error(1)
+}
+
+// RecordStopReason is a mock for the RecordStopReason method.
+func (s *Service) RecordStopReason(groupID simulations.GroupID, reason string) error {
+	args := s.Called(groupID, reason)
+	return args.Error(0)
+}
+
+// ListUncharged is a mock for the ListUncharged method.
+func (s *Service) ListUncharged() ([]simulations.Simulation, error) {
+	args := s.Called()
+	sims := args.Get(0).([]simulations.Simulation)
+	return sims, args.Error(1)
+}
+
 // NewService initializes a new fake service implementation.
 func NewService() *Service {
 	return &Service{
@@ -86,6 +86,10 @@ var (
 	// StatusRestarted is used when a simulation has been restarted.
 	StatusRestarted Status = "restarted"
 
+	// StatusFailed is used when a simulation failed to run. Parent simulations are marked as failed when too many of
+	// their children fail.
+	StatusFailed Status = "failed"
+
 	// StatusUnknown is used to represent an unknown status.
 	StatusUnknown Status = "unknown"
 )
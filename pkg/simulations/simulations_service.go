@@ -1,5 +1,7 @@
 package simulations
 
+import "time"
+
 // CreateSimulationInput contains all the information needed to create a simulation.
 type CreateSimulationInput struct {
 	Name      string
@@ -11,6 +13,10 @@ type CreateSimulationInput struct {
 	Extra     string
 	Track     string
 	Robots    string
+	// Kind is the kind of simulation to create. Multisims are created as a SimParent with a SimChild for each run.
+	Kind Kind
+	// Parent is the group id of the parent simulation. It is only set when creating a SimChild.
+	Parent *GroupID
 }
 
 // Service is a generic simulation service interface.
@@ -35,12 +41,25 @@ type Service interface {
 	// GetRobots returns the robot list of the simulation with the given GroupID.
 	GetRobots(groupID GroupID) ([]Robot, error)
 
+	// MarkLaunched records the time the simulation with the given GroupID was launched. It sets the value returned by
+	// Simulation.GetLaunchedAt.
+	MarkLaunched(groupID GroupID, launchedAt time.Time) error
+
 	// MarkStopped marks a simulation identified with the given Group ID as stopped.
 	MarkStopped(groupID GroupID) error
 
 	// MarkCharged marks a simulation identified with the given Group ID as charged.
 	MarkCharged(groupID GroupID) error
 
+	// ListRunning returns the simulations that are currently running.
+	ListRunning() ([]Simulation, error)
+
+	// RecordStopReason records the reason the simulation with the given GroupID is being stopped.
+	RecordStopReason(groupID GroupID, reason string) error
+
+	// ListUncharged returns the simulations that stopped but were never charged.
+	ListUncharged() ([]Simulation, error)
+
 	// GetWebsocketToken returns a websocket token for a certain simulation with the given GroupID.
 	GetWebsocketToken(groupID GroupID) (string, error)
 }
@@ -1,5 +1,15 @@
 package simulations
 
+import (
+	"errors"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ErrNoStatistics is returned when a summary is generated for a simulation run without statistics.
+	ErrNoStatistics = errors.New("simulation has no statistics")
+)
+
 // Statistics contains the summary values of a simulation run.
 type Statistics struct {
 	// Started is true if the simulation was started.
@@ -11,3 +21,18 @@ type Statistics struct {
 	// ModelCount is the amount of models used in the simulation.
 	ModelCount int `yaml:"model_count"`
 }
+
+// WasStarted returns true if the simulation was started.
+func (s Statistics) WasStarted() bool {
+	return s.Started != 0
+}
+
+// ParseStatistics parses the YAML statistics file generated by a simulation run.
+func ParseStatistics(data []byte) (*Statistics, error) {
+	var stats Statistics
+	if err := yaml.Unmarshal(data, &stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,32 @@
+package simulations
+
+import (
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// Transition is a change in the status of a simulation.
+type Transition struct {
+	gorm.Model
+	// GroupID identifies the simulation that changed its status.
+	GroupID GroupID `gorm:"not null;index"`
+	// From is the status the simulation had before the transition.
+	From Status
+	// To is the status the simulation had after the transition.
+	To Status
+	// Timestamp is the time the transition happened.
+	Timestamp time.Time
+}
+
+// TableName defines the database table name for status transitions.
+func (Transition) TableName() string {
+	return "simulation_status_transitions"
+}
+
+// TransitionHistory keeps a record of the status transitions of simulations.
+type TransitionHistory interface {
+	// Record records a status transition.
+	Record(transition *Transition) error
+	// List returns the status transitions of a simulation, sorted by timestamp.
+	List(groupID GroupID) ([]Transition, error)
+}
@@ -0,0 +1,42 @@
+package simulations
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// gormTransitionHistory is a TransitionHistory implementation that persists transitions in a relational database
+// using gorm. The database tables can be created with MigrateDB.
+type gormTransitionHistory struct {
+	// db contains the database connection or transaction used to persist transitions.
+	db *gorm.DB
+}
+
+// NewGormTransitionHistory returns a TransitionHistory that persists transitions using the given gorm database
+// connection or transaction.
+func NewGormTransitionHistory(db *gorm.DB) TransitionHistory {
+	return &gormTransitionHistory{
+		db: db,
+	}
+}
+
+// Record records a status transition.
+func (h *gormTransitionHistory) Record(transition *Transition) error {
+	return h.db.Model(&Transition{}).Create(transition).Error
+}
+
+// List returns the status transitions of a simulation, sorted by timestamp.
+func (h *gormTransitionHistory) List(groupID GroupID) ([]Transition, error) {
+	var transitions []Transition
+
+	err := h.db.
+		Where("group_id = ?", groupID).
+		Order("timestamp asc").
+		Order("id asc").
+		Find(&transitions).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return transitions, nil
+}
@@ -0,0 +1,55 @@
+package simulations
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryTransitionHistory is a TransitionHistory implementation that keeps transitions in memory.
+// It is intended for unit tests.
+type memoryTransitionHistory struct {
+	// lock is used to allow concurrent access to the history.
+	lock sync.RWMutex
+	// transitions contains the recorded transitions, indexed by group id.
+	transitions map[GroupID][]Transition
+	// lastID is the id assigned to the last recorded transition.
+	lastID uint
+}
+
+// NewMemoryTransitionHistory returns a TransitionHistory that keeps transitions in memory.
+func NewMemoryTransitionHistory() TransitionHistory {
+	return &memoryTransitionHistory{
+		transitions: make(map[GroupID][]Transition),
+	}
+}
+
+// Record records a status transition.
+func (h *memoryTransitionHistory) Record(transition *Transition) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.lastID++
+	transition.ID = h.lastID
+	transition.CreatedAt = time.Now()
+	transition.UpdatedAt = transition.CreatedAt
+
+	h.transitions[transition.GroupID] = append(h.transitions[transition.GroupID], *transition)
+
+	return nil
+}
+
+// List returns the status transitions of a simulation, sorted by timestamp.
+func (h *memoryTransitionHistory) List(groupID GroupID) ([]Transition, error) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	out := make([]Transition, len(h.transitions[groupID]))
+	copy(out, h.transitions[groupID])
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Timestamp.Before(out[j].Timestamp)
+	})
+
+	return out, nil
+}
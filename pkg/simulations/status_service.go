@@ -0,0 +1,61 @@
+package simulations
+
+import (
+	"sync"
+	"time"
+)
+
+// validatedService is a Service implementation that validates status transitions before updating the status of
+// simulations, and records each transition in a history.
+type validatedService struct {
+	Service
+	// history is used to record status transitions.
+	history TransitionHistory
+	// lock prevents concurrent status updates from validating against a stale status.
+	lock sync.Mutex
+	// now returns the current time.
+	now func() time.Time
+}
+
+// NewValidatedService wraps a Service to validate status transitions before updating the status of simulations.
+// UpdateStatus returns an error wrapping ErrInvalidTransition if the transition is not allowed by the simulation
+// status state machine (see CanTransition). Valid transitions are recorded in `history`, if not nil.
+func NewValidatedService(service Service, history TransitionHistory) Service {
+	return &validatedService{
+		Service: service,
+		history: history,
+		now:     time.Now,
+	}
+}
+
+// UpdateStatus validates the status transition and updates the status of the simulation with the given groupID.
+func (s *validatedService) UpdateStatus(groupID GroupID, status Status) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sim, err := s.Service.Get(groupID)
+	if err != nil {
+		return err
+	}
+
+	from := sim.GetStatus()
+	if err := ValidateTransition(from, status); err != nil {
+		return err
+	}
+
+	if err := s.Service.UpdateStatus(groupID, status); err != nil {
+		return err
+	}
+
+	// Setting the current status again is not a transition
+	if s.history == nil || from == status {
+		return nil
+	}
+
+	return s.history.Record(&Transition{
+		GroupID:   groupID,
+		From:      from,
+		To:        status,
+		Timestamp: s.now(),
+	})
+}
@@ -0,0 +1,98 @@
+package simulations
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidTransition is returned when a simulation status cannot be changed to another status.
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// launchStatuses contains the statuses a simulation goes through while it is launched, in order.
+var launchStatuses = []Status{
+	StatusPending,
+	StatusLaunchingInstances,
+	StatusWaitingInstances,
+	StatusWaitingNodes,
+	StatusLaunchingPods,
+	StatusWaitingPods,
+	StatusRunning,
+}
+
+// terminationStatuses contains the statuses a simulation goes through while it is terminated, in order.
+var terminationStatuses = []Status{
+	StatusTerminateRequested,
+	StatusProcessingResults,
+	StatusDeletingPods,
+	StatusDeletingNodes,
+	StatusTerminatingInstances,
+	StatusTerminated,
+}
+
+// transitions contains the statuses each status can transition to.
+var transitions = newTransitions()
+
+// newTransitions creates the simulation status state machine.
+//
+// Simulations move forward through the launch statuses, and can skip statuses (e.g. a simulator that does not wait
+// for nodes goes from launching-instances to launching-pods). A simulation can be stopped at any point of the launch,
+// after which it moves forward through the termination statuses until it is terminated.
+//
+// Additionally:
+//   - Pending simulations can be rejected or superseded.
+//   - Simulations that are launching instances can go back to pending if there is not enough capacity to launch them.
+//   - Simulations can fail at any point before they reach a final status.
+//   - Terminated and failed simulations can be restarted.
+//
+// Rejected, superseded and restarted simulations cannot change their status.
+func newTransitions() map[Status]map[Status]bool {
+	t := make(map[Status]map[Status]bool)
+	allow := func(from Status, to ...Status) {
+		if t[from] == nil {
+			t[from] = make(map[Status]bool)
+		}
+		for _, status := range to {
+			t[from][status] = true
+		}
+	}
+
+	for i, from := range launchStatuses {
+		allow(from, launchStatuses[i+1:]...)
+		allow(from, terminationStatuses...)
+		allow(from, StatusFailed)
+	}
+
+	for i, from := range terminationStatuses[:len(terminationStatuses)-1] {
+		allow(from, terminationStatuses[i+1:]...)
+		allow(from, StatusFailed)
+	}
+
+	allow(StatusPending, StatusRejected, StatusSuperseded)
+	allow(StatusLaunchingInstances, StatusPending)
+	allow(StatusTerminated, StatusRestarted)
+	allow(StatusFailed, StatusRestarted)
+
+	return t
+}
+
+// CanTransition returns true if a simulation with the `from` status can change its status to `to`.
+// Setting the status a simulation already has is always allowed.
+func CanTransition(from, to Status) bool {
+	if from == to {
+		return true
+	}
+
+	return transitions[from][to]
+}
+
+// ValidateTransition returns an error wrapping ErrInvalidTransition if a simulation with the `from` status cannot
+// change its status to `to`.
+func ValidateTransition(from, to Status) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	return nil
+}
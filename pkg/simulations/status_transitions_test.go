@@ -0,0 +1,144 @@
+package simulations
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := [][2]Status{
+		{StatusPending, StatusLaunchingInstances},
+		{StatusLaunchingInstances, StatusWaitingInstances},
+		{StatusLaunchingInstances, StatusLaunchingPods},
+		{StatusWaitingPods, StatusRunning},
+		{StatusPending, StatusRunning},
+		{StatusLaunchingInstances, StatusPending},
+		{StatusRunning, StatusTerminateRequested},
+		{StatusRunning, StatusDeletingPods},
+		{StatusLaunchingPods, StatusDeletingPods},
+		{StatusDeletingPods, StatusTerminatingInstances},
+		{StatusTerminatingInstances, StatusTerminated},
+		{StatusRunning, StatusTerminated},
+		{StatusPending, StatusRejected},
+		{StatusPending, StatusSuperseded},
+		{StatusWaitingNodes, StatusFailed},
+		{StatusDeletingNodes, StatusFailed},
+		{StatusTerminated, StatusRestarted},
+		{StatusFailed, StatusRestarted},
+		{StatusRunning, StatusRunning},
+	}
+	for _, transition := range allowed {
+		assert.True(t, CanTransition(transition[0], transition[1]), "%s -> %s", transition[0], transition[1])
+	}
+
+	forbidden := [][2]Status{
+		{StatusTerminated, StatusRunning},
+		{StatusRunning, StatusPending},
+		{StatusLaunchingPods, StatusLaunchingInstances},
+		{StatusDeletingPods, StatusRunning},
+		{StatusTerminatingInstances, StatusDeletingPods},
+		{StatusRunning, StatusRejected},
+		{StatusRejected, StatusPending},
+		{StatusSuperseded, StatusRunning},
+		{StatusRestarted, StatusPending},
+		{StatusTerminated, StatusFailed},
+		{StatusFailed, StatusTerminated},
+		{StatusUnknown, StatusRunning},
+	}
+	for _, transition := range forbidden {
+		assert.False(t, CanTransition(transition[0], transition[1]), "%s -> %s", transition[0], transition[1])
+	}
+}
+
+func TestValidateTransition(t *testing.T) {
+	assert.NoError(t, ValidateTransition(StatusPending, StatusLaunchingInstances))
+
+	err := ValidateTransition(StatusTerminated, StatusRunning)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.Contains(t, err.Error(), "terminated -> running")
+}
+
+// testSimulation is a Simulation implementation with a status.
+type testSimulation struct {
+	Simulation
+	status Status
+}
+
+func (s *testSimulation) GetStatus() Status {
+	return s.status
+}
+
+// testService is a Service implementation that keeps simulations in memory.
+type testService struct {
+	Service
+	sims map[GroupID]*testSimulation
+}
+
+func (s *testService) Get(groupID GroupID) (Simulation, error) {
+	sim, ok := s.sims[groupID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return sim, nil
+}
+
+func (s *testService) UpdateStatus(groupID GroupID, status Status) error {
+	s.sims[groupID].status = status
+	return nil
+}
+
+func TestValidatedService_UpdateStatus(t *testing.T) {
+	service := &testService{sims: map[GroupID]*testSimulation{"sim": {status: StatusPending}}}
+	history := NewMemoryTransitionHistory()
+
+	s := NewValidatedService(service, history)
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.(*validatedService).now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	require.NoError(t, s.UpdateStatus("sim", StatusLaunchingInstances))
+	require.NoError(t, s.UpdateStatus("sim", StatusRunning))
+	// Setting the same status is not recorded
+	require.NoError(t, s.UpdateStatus("sim", StatusRunning))
+	require.NoError(t, s.UpdateStatus("sim", StatusTerminated))
+
+	// Invalid transitions are rejected
+	err := s.UpdateStatus("sim", StatusRunning)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.Equal(t, StatusTerminated, service.sims["sim"].status)
+
+	// Unknown simulations return the service error
+	assert.Error(t, s.UpdateStatus("missing", StatusRunning))
+
+	transitions, err := history.List("sim")
+	require.NoError(t, err)
+	require.Len(t, transitions, 3)
+
+	start := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	expected := []struct {
+		from, to Status
+	}{
+		{StatusPending, StatusLaunchingInstances},
+		{StatusLaunchingInstances, StatusRunning},
+		{StatusRunning, StatusTerminated},
+	}
+	for i, e := range expected {
+		assert.Equal(t, GroupID("sim"), transitions[i].GroupID)
+		assert.Equal(t, e.from, transitions[i].From)
+		assert.Equal(t, e.to, transitions[i].To)
+		assert.Equal(t, start.Add(time.Duration(i+1)*time.Minute), transitions[i].Timestamp)
+	}
+}
+
+func TestValidatedService_NoHistory(t *testing.T) {
+	service := &testService{sims: map[GroupID]*testSimulation{"sim": {status: StatusPending}}}
+
+	s := NewValidatedService(service, nil)
+	require.NoError(t, s.UpdateStatus("sim", StatusRejected))
+	assert.Equal(t, StatusRejected, service.sims["sim"].status)
+}
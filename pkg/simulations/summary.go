@@ -1,6 +1,10 @@
 package simulations
 
-import "github.com/jinzhu/gorm"
+import (
+	"github.com/jinzhu/gorm"
+	"math"
+	"strings"
+)
 
 // Summary contains the total score and average statistics for a certain simulation or group of simulations.
 type Summary struct {
@@ -26,3 +30,103 @@ type Summary struct {
 	// RunData contains specific simulation run information. It should only be passed for single sims.
 	RunData string `json:"-" gorm:"-"`
 }
+
+// RunResult contains the results of a simulation run used to generate a Summary.
+type RunResult struct {
+	// GroupID identifies the simulation.
+	GroupID GroupID
+	// Statistics contains the statistics of the run. It is nil if the statistics could not be read.
+	Statistics *Statistics
+	// Score is the simulation score. It is nil if the simulation was not scored.
+	Score *float64
+	// Error contains the simulation error. It is nil if the simulation did not fail.
+	Error *Error
+	// RunData contains specific simulation run information, such as the contents of the statistics file.
+	RunData string
+}
+
+// included returns true if the run should be used to generate the summary of its parent.
+func (r RunResult) included() bool {
+	return r.Error == nil && r.Statistics != nil && r.Statistics.WasStarted()
+}
+
+// NewSummary generates the summary of a single simulation run.
+// Averages are set to the values of the run, and standard deviations are zero.
+// Returns ErrSimulationWithError if the run failed, and ErrNoStatistics if the run has no statistics.
+func NewSummary(result RunResult) (*Summary, error) {
+	if result.Error != nil {
+		return nil, ErrSimulationWithError
+	}
+	if result.Statistics == nil {
+		return nil, ErrNoStatistics
+	}
+
+	groupID := result.GroupID
+	return &Summary{
+		GroupID:             &groupID,
+		Score:               result.Score,
+		SimTimeDurationAvg:  float64(result.Statistics.SimulationTime),
+		RealTimeDurationAvg: float64(result.Statistics.RealTime),
+		ModelCountAvg:       float64(result.Statistics.ModelCount),
+		RunData:             result.RunData,
+	}, nil
+}
+
+// NewParentSummary generates the summary of a parent simulation by aggregating the results of its children.
+// Children that failed, have no statistics or were never started are left out of the summary. Averages and population
+// standard deviations are computed from the remaining children, and their group ids are stored in Sources as a
+// comma-separated list.
+// The parent score is the average score of the children used to generate the summary that were scored.
+// If no child can be used, the summary has zero values, empty Sources and no score.
+func NewParentSummary(parent GroupID, children []RunResult) *Summary {
+	var simTimes, realTimes, modelCounts, scores []float64
+	sources := make([]string, 0, len(children))
+
+	for _, child := range children {
+		if !child.included() {
+			continue
+		}
+
+		sources = append(sources, child.GroupID.String())
+		simTimes = append(simTimes, float64(child.Statistics.SimulationTime))
+		realTimes = append(realTimes, float64(child.Statistics.RealTime))
+		modelCounts = append(modelCounts, float64(child.Statistics.ModelCount))
+		if child.Score != nil {
+			scores = append(scores, *child.Score)
+		}
+	}
+
+	summary := Summary{
+		GroupID: &parent,
+		Sources: strings.Join(sources, ","),
+	}
+	summary.SimTimeDurationAvg, summary.SimTimeDurationStdDev = meanStdDev(simTimes)
+	summary.RealTimeDurationAvg, summary.RealTimeDurationStdDev = meanStdDev(realTimes)
+	summary.ModelCountAvg, summary.ModelCountStdDev = meanStdDev(modelCounts)
+	if len(scores) > 0 {
+		score, _ := meanStdDev(scores)
+		summary.Score = &score
+	}
+
+	return &summary
+}
+
+// meanStdDev returns the mean and population standard deviation of a set of values.
+// It returns zero values if the set is empty.
+func meanStdDev(values []float64) (mean float64, stdDev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	for _, v := range values {
+		stdDev += (v - mean) * (v - mean)
+	}
+	stdDev = math.Sqrt(stdDev / float64(len(values)))
+
+	return mean, stdDev
+}
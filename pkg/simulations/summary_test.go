@@ -0,0 +1,112 @@
+package simulations
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseStatistics(t *testing.T) {
+	data := []byte("was_started: 1\nsim_time_duration_sec: 120\nreal_time_duration_sec: 240\nmodel_count: 5\n")
+
+	stats, err := ParseStatistics(data)
+	require.NoError(t, err)
+	assert.Equal(t, Statistics{Started: 1, SimulationTime: 120, RealTime: 240, ModelCount: 5}, *stats)
+	assert.True(t, stats.WasStarted())
+
+	_, err = ParseStatistics([]byte("model_count: [1"))
+	assert.Error(t, err)
+}
+
+func TestNewSummary(t *testing.T) {
+	score := 10.0
+	summary, err := NewSummary(RunResult{
+		GroupID:    "sim",
+		Statistics: &Statistics{Started: 1, SimulationTime: 120, RealTime: 240, ModelCount: 5},
+		Score:      &score,
+		RunData:    "data",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, GroupID("sim"), *summary.GroupID)
+	assert.Equal(t, &score, summary.Score)
+	assert.Equal(t, 120.0, summary.SimTimeDurationAvg)
+	assert.Equal(t, 240.0, summary.RealTimeDurationAvg)
+	assert.Equal(t, 5.0, summary.ModelCountAvg)
+	assert.Zero(t, summary.SimTimeDurationStdDev)
+	assert.Equal(t, "data", summary.RunData)
+	assert.Empty(t, summary.Sources)
+}
+
+func TestNewSummary_Errors(t *testing.T) {
+	simErr := Error("error")
+	_, err := NewSummary(RunResult{GroupID: "sim", Statistics: &Statistics{}, Error: &simErr})
+	assert.Equal(t, ErrSimulationWithError, err)
+
+	_, err = NewSummary(RunResult{GroupID: "sim"})
+	assert.Equal(t, ErrNoStatistics, err)
+}
+
+func TestNewParentSummary(t *testing.T) {
+	score1, score2, score3 := 10.0, 20.0, 100.0
+	simErr := Error("error")
+
+	summary := NewParentSummary("parent", []RunResult{
+		{
+			GroupID:    "child-1",
+			Statistics: &Statistics{Started: 1, SimulationTime: 100, RealTime: 200, ModelCount: 4},
+			Score:      &score1,
+		},
+		{
+			GroupID:    "child-2",
+			Statistics: &Statistics{Started: 1, SimulationTime: 300, RealTime: 400, ModelCount: 4},
+			Score:      &score2,
+		},
+		// Children that were not scored contribute to the statistics but not to the score
+		{
+			GroupID:    "child-3",
+			Statistics: &Statistics{Started: 1, SimulationTime: 200, RealTime: 300, ModelCount: 7},
+		},
+		// Children that failed, have no statistics or were never started are ignored
+		{
+			GroupID:    "failed",
+			Statistics: &Statistics{Started: 1, SimulationTime: 1000, RealTime: 1000, ModelCount: 1},
+			Score:      &score3,
+			Error:      &simErr,
+		},
+		{
+			GroupID: "no-statistics",
+			Score:   &score3,
+		},
+		{
+			GroupID:    "not-started",
+			Statistics: &Statistics{Started: 0},
+			Score:      &score3,
+		},
+	})
+
+	assert.Equal(t, GroupID("parent"), *summary.GroupID)
+	assert.Equal(t, "child-1,child-2,child-3", summary.Sources)
+	require.NotNil(t, summary.Score)
+	assert.Equal(t, 15.0, *summary.Score)
+	assert.Equal(t, 200.0, summary.SimTimeDurationAvg)
+	assert.InDelta(t, 81.6497, summary.SimTimeDurationStdDev, 0.0001)
+	assert.Equal(t, 300.0, summary.RealTimeDurationAvg)
+	assert.InDelta(t, 81.6497, summary.RealTimeDurationStdDev, 0.0001)
+	assert.Equal(t, 5.0, summary.ModelCountAvg)
+	assert.InDelta(t, 1.4142, summary.ModelCountStdDev, 0.0001)
+	assert.Empty(t, summary.RunData)
+}
+
+func TestNewParentSummary_NoChildren(t *testing.T) {
+	simErr := Error("error")
+	summary := NewParentSummary("parent", []RunResult{
+		{GroupID: "failed", Statistics: &Statistics{Started: 1}, Error: &simErr},
+	})
+
+	assert.Equal(t, GroupID("parent"), *summary.GroupID)
+	assert.Empty(t, summary.Sources)
+	assert.Nil(t, summary.Score)
+	assert.Zero(t, summary.SimTimeDurationAvg)
+	assert.Zero(t, summary.SimTimeDurationStdDev)
+}
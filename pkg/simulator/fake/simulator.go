@@ -0,0 +1,35 @@
+package fake
+
+import (
+	"context"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ simulator.Simulator = (*Simulator)(nil)
+
+// Simulator is a fake simulator.Simulator implementation.
+type Simulator struct {
+	*mock.Mock
+}
+
+// Start mocks the Start method.
+func (s *Simulator) Start(ctx context.Context, platform platform.Platform, groupID simulations.GroupID) error {
+	args := s.Called(ctx, platform, groupID)
+	return args.Error(0)
+}
+
+// Stop mocks the Stop method.
+func (s *Simulator) Stop(ctx context.Context, platform platform.Platform, groupID simulations.GroupID) error {
+	args := s.Called(ctx, platform, groupID)
+	return args.Error(0)
+}
+
+// NewSimulator initializes a new simulator.Simulator fake implementation.
+func NewSimulator() *Simulator {
+	return &Simulator{
+		Mock: new(mock.Mock),
+	}
+}
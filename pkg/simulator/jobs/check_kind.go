@@ -1,9 +1,9 @@
 package jobs
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
-	"github.com/jinzhu/gorm"
 )
 
 // CheckSimulationKindInput is the input of the CheckSimulationKind job.
@@ -25,7 +25,7 @@ var CheckSimulationKind = &actions.Job{
 }
 
 // checkSimulationKind is the execution of the CheckSimulationKind job.
-func checkSimulationKind(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func checkSimulationKind(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	input := value.(CheckSimulationKindInput)
 	return CheckSimulationKindOutput(input.Simulation.IsKind(input.Kind)), nil
 }
@@ -1,6 +1,7 @@
 package jobs
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulations/fake"
@@ -20,7 +21,7 @@ func TestCheckSimulationKind_Success(t *testing.T) {
 		Kind:       simulations.SimParent,
 	}
 
-	result, err := CheckSimulationKind.Run(s, nil, &actions.Deployment{CurrentJob: "test"}, input)
+	result, err := CheckSimulationKind.Run(context.Background(), s, nil, &actions.Deployment{CurrentJob: "test"}, input)
 	assert.NoError(t, err)
 
 	output, ok := result.(CheckSimulationKindOutput)
@@ -39,7 +40,7 @@ func TestCheckSimulationKind_ReturnsFalseWhenKindDoesNotMatch(t *testing.T) {
 		Kind:       simulations.SimParent,
 	}
 
-	result, err := CheckSimulationKind.Run(s, nil, &actions.Deployment{CurrentJob: "test"}, input)
+	result, err := CheckSimulationKind.Run(context.Background(), s, nil, &actions.Deployment{CurrentJob: "test"}, input)
 	assert.NoError(t, err)
 	output, ok := result.(CheckSimulationKindOutput)
 	assert.True(t, ok)
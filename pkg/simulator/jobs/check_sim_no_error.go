@@ -1,10 +1,10 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
-	"github.com/jinzhu/gorm"
 )
 
 // CheckSimulationNoErrorInput is the input of the CheckSimulationNoError job.
@@ -21,7 +21,7 @@ var CheckSimulationNoError = &actions.Job{
 }
 
 // checkSimulationNoError is the execute function of the CheckSimulationNoError job.
-func checkSimulationNoError(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func checkSimulationNoError(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	input := value.(CheckSimulationNoErrorInput)
 
 	for _, sim := range input {
@@ -1,9 +1,9 @@
 package jobs
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
-	"github.com/jinzhu/gorm"
 )
 
 // CheckSimulationStatusInput is the input of the CheckSimulationStatus job.
@@ -24,7 +24,7 @@ var CheckSimulationStatus = &actions.Job{
 }
 
 // checkSimulationStatus is the execute function of the CheckSimulationStatus job.
-func checkSimulationStatus(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func checkSimulationStatus(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	input := value.(CheckSimulationStatusInput)
 	output := CheckSimulationStatusOutput(input.Simulation.HasStatus(input.Status))
 	return output, nil
@@ -1,6 +1,7 @@
 package jobs
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulations/fake"
@@ -20,7 +21,7 @@ func TestCheckSimulationStatus_Success(t *testing.T) {
 		Status:     simulations.StatusPending,
 	}
 
-	result, err := CheckSimulationStatus.Run(s, nil, &actions.Deployment{CurrentJob: "test"}, input)
+	result, err := CheckSimulationStatus.Run(context.Background(), s, nil, &actions.Deployment{CurrentJob: "test"}, input)
 	assert.NoError(t, err)
 
 	output, ok := result.(CheckSimulationStatusOutput)
@@ -39,7 +40,7 @@ func TestCheckSimulationStatus_ErrWhenStatusDoesNotMatch(t *testing.T) {
 		Status:     simulations.StatusPending,
 	}
 
-	result, err := CheckSimulationStatus.Run(s, nil, &actions.Deployment{CurrentJob: "test"}, input)
+	result, err := CheckSimulationStatus.Run(context.Background(), s, nil, &actions.Deployment{CurrentJob: "test"}, input)
 	assert.NoError(t, err)
 
 	output, ok := result.(CheckSimulationStatusOutput)
@@ -0,0 +1,261 @@
+package jobs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
+	"github.com/gazebo-web/cloudsim/v4/pkg/storage"
+	"io"
+	"path"
+	"time"
+)
+
+const (
+	// jobCollectLogsDataKey is the key used to persist the list of log archives uploaded by the CollectLogs job.
+	jobCollectLogsDataKey = "collected-logs"
+	// logsArchiveContentType is the content type of the log archives uploaded by the CollectLogs job.
+	logsArchiveContentType = "application/x-gzip"
+)
+
+// LogDirectory is a directory with log files inside a pod container.
+type LogDirectory struct {
+	// Container is the name of the container the directory is read from.
+	Container string
+	// Path is the absolute path of the directory inside the container.
+	// Paths are usually taken from store.Ignition (e.g. GazeboServerLogsPath or ROSLogsPath).
+	Path string
+}
+
+// CollectPodLogsInput contains the logs to collect from a single pod.
+type CollectPodLogsInput struct {
+	// Pod is the pod to collect logs from.
+	Pod resource.Resource
+	// Containers contains the names of the containers whose logs are collected.
+	Containers []string
+	// Directories contains the log directories collected from the pod containers.
+	Directories []LogDirectory
+}
+
+// CollectLogsInput is the input of the CollectLogs job.
+type CollectLogsInput struct {
+	// Bucket is the bucket log archives are uploaded to. If empty, store.Ignition LogsBucket is used.
+	Bucket string
+	// Prefix is the prefix of the keys of the uploaded log archives. It is usually the simulation group id.
+	Prefix string
+	// Lines is the maximum number of lines read from each container log. If zero, the entire log is read.
+	Lines int64
+	// Pods contains the logs to collect from each pod.
+	Pods []CollectPodLogsInput
+}
+
+// CollectedLogs contains the location of the log archive uploaded for a single pod.
+type CollectedLogs struct {
+	// Pod is the name of the pod the logs were collected from.
+	Pod string
+	// Bucket is the bucket the log archive was uploaded to.
+	Bucket string
+	// Key is the key of the log archive in the bucket.
+	Key string
+	// Errors contains the errors returned while reading log sources, indexed by source.
+	// Sources that fail to be read are not included in the archive.
+	Errors map[string]string `json:",omitempty"`
+}
+
+// CollectLogsOutput is the output of the CollectLogs job.
+// It contains the log archives uploaded for each pod.
+type CollectLogsOutput []CollectedLogs
+
+// CollectLogs is a generic job to collect logs from pods and upload them to the logs bucket.
+// Container logs and log directories from each pod are packaged as a `<prefix>/<pod>.tar.gz` archive. Container logs
+// are stored as `<container>.log` and directories are stored under `<container>/<path>`.
+// Log collection is best-effort: sources that fail to be read are reported in the output instead of failing the job.
+// The job only returns an error if an archive cannot be uploaded. Log archives are not collected if logs copy is
+// disabled in store.Ignition.
+// The list of uploaded archives is persisted in the deployment, see GetCollectedLogs.
+var CollectLogs = &actions.Job{
+	Execute: collectLogs,
+}
+
+// GetCollectedLogs returns the list of log archives uploaded by the given CollectLogs job of a deployment.
+// These archives can be used to generate download links with storage.Storage GetURL.
+func GetCollectedLogs(tx actions.DeploymentStore, deployment *actions.Deployment, job string) (CollectLogsOutput, error) {
+	var out CollectLogsOutput
+	if err := deployment.GetJobDataOutValue(tx, &job, jobCollectLogsDataKey, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// collectLogs is the main function executed by the CollectLogs job.
+func collectLogs(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
+	value interface{}) (interface{}, error) {
+
+	s := store.State().(state.PlatformGetter)
+
+	// Parse input
+	input, ok := value.(CollectLogsInput)
+	if !ok {
+		return nil, simulator.ErrInvalidInput
+	}
+
+	ignition := s.Platform().Store().Ignition()
+	if !ignition.LogsCopyEnabled() {
+		return CollectLogsOutput{}, nil
+	}
+
+	bucket := input.Bucket
+	if bucket == "" {
+		bucket = ignition.LogsBucket()
+	}
+
+	out := make(CollectLogsOutput, 0, len(input.Pods))
+	for _, in := range input.Pods {
+		archive, sourceErrs, err := archivePodLogs(ctx, s.Platform().Orchestrator().Pods(), in, input.Lines)
+		if err != nil {
+			return nil, err
+		}
+
+		collected := CollectedLogs{
+			Pod:    in.Pod.Name(),
+			Bucket: bucket,
+			Key:    path.Join(input.Prefix, fmt.Sprintf("%s.tar.gz", in.Pod.Name())),
+			Errors: sourceErrs,
+		}
+
+		err = s.Platform().Storage().Upload(storage.UploadInput{
+			Bucket:        collected.Bucket,
+			Key:           collected.Key,
+			File:          bytes.NewReader(archive.Bytes()),
+			ContentLength: int64(archive.Len()),
+			ContentType:   logsArchiveContentType,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, collected)
+
+		// Persist the archives uploaded so far
+		if err := deployment.SetJobData(tx, nil, jobCollectLogsDataKey, out); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// archivePodLogs packages the logs of a pod in a tar.gz archive.
+// Errors reading a log source are returned indexed by source, and the source is left out of the archive. Directory
+// archives that end unexpectedly keep the entries read before the error.
+func archivePodLogs(ctx context.Context, p pods.Pods, input CollectPodLogsInput, lines int64) (*bytes.Buffer,
+	map[string]string, error) {
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	reader := p.Reader(ctx, input.Pod)
+	sourceErrs := make(map[string]string)
+
+	for _, container := range input.Containers {
+		logs, err := reader.Logs(ctx, container, lines)
+		if err != nil {
+			sourceErrs[container] = err.Error()
+			continue
+		}
+
+		err = tw.WriteHeader(&tar.Header{
+			Name:    fmt.Sprintf("%s.log", container),
+			Mode:    0644,
+			Size:    int64(len(logs)),
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := tw.Write([]byte(logs)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, dir := range input.Directories {
+		source := path.Join(dir.Container, dir.Path)
+
+		archive, err := reader.Archive(ctx, dir.Container, dir.Path)
+		if err != nil {
+			sourceErrs[source] = err.Error()
+			continue
+		}
+
+		sourceErr, err := copyTarEntries(tw, tar.NewReader(archive), source)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sourceErr != nil {
+			sourceErrs[source] = sourceErr.Error()
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(sourceErrs) == 0 {
+		sourceErrs = nil
+	}
+
+	return &buf, sourceErrs, nil
+}
+
+// copyTarEntries copies the entries of a tar archive into another tar archive, placing them inside the `dir`
+// directory.
+// Errors reading the source archive are returned as `sourceErr`, and the entries copied before the error are kept. An
+// entry whose contents end unexpectedly is padded with zeros to keep the destination archive valid. Errors writing
+// the destination archive are returned as `err`.
+func copyTarEntries(tw *tar.Writer, tr *tar.Reader, dir string) (sourceErr error, err error) {
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return err, nil
+		}
+
+		// Entries are joined to the root path first to prevent them from being placed outside of `dir`
+		header.Name = path.Join(dir, path.Join("/", header.Name))
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		n, err := io.Copy(tw, tr)
+		if err != nil {
+			if _, err := io.CopyN(tw, zeroReader{}, header.Size-n); err != nil {
+				return nil, err
+			}
+			return err, nil
+		}
+	}
+}
+
+// zeroReader is an io.Reader that reads an infinite stream of zeros.
+type zeroReader struct{}
+
+// Read fills p with zeros.
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
@@ -0,0 +1,264 @@
+package jobs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/implementations/kubernetes"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/storage"
+	fakeStore "github.com/gazebo-web/cloudsim/v4/pkg/store/implementations/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// collectLogsTestPods is a pods.Pods implementation that returns collectLogsTestReader readers.
+type collectLogsTestPods struct {
+	pods.Pods
+}
+
+func (p *collectLogsTestPods) Reader(ctx context.Context, resource resource.Resource) pods.Reader {
+	return &collectLogsTestReader{pod: resource.Name()}
+}
+
+// collectLogsTestReader is a pods.Reader implementation that returns test logs.
+// Reading logs from the "missing" container fails, and the archive of the "/tmp/truncated" directory ends unexpectedly.
+type collectLogsTestReader struct {
+	pods.Reader
+	pod string
+}
+
+func (r *collectLogsTestReader) Logs(ctx context.Context, container string, lines int64) (string, error) {
+	if container == "missing" {
+		return "", errors.New("container not found")
+	}
+	return r.pod + " " + container, nil
+}
+
+func (r *collectLogsTestReader) Archive(ctx context.Context, container string, path string) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	content := []byte(r.pod + " " + path)
+	size := int64(len(content))
+	if path == "/tmp/truncated" {
+		size = 1024
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "./server.log", Mode: 0644, Size: size}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return nil, err
+	}
+	if path == "/tmp/truncated" {
+		return &buf, nil
+	}
+	return &buf, tw.Close()
+}
+
+// collectLogsTestStorage is a storage.Storage implementation that keeps uploaded files in memory.
+type collectLogsTestStorage struct {
+	storage.Storage
+	uploads map[string]storage.UploadInput
+}
+
+func (s *collectLogsTestStorage) Upload(input storage.UploadInput) error {
+	s.uploads[input.Key] = input
+	return nil
+}
+
+// newCollectLogsTestStore returns an actions store with a platform used to test the CollectLogs job.
+func newCollectLogsTestStore(t *testing.T, enabled bool) (actions.Store, *collectLogsTestStorage) {
+	ignition := fakeStore.NewFakeIgnition()
+	ignition.On("LogsCopyEnabled").Return(enabled)
+	ignition.On("LogsBucket").Return("logs")
+
+	s := &collectLogsTestStorage{uploads: make(map[string]storage.UploadInput)}
+	p, err := platform.NewPlatform("test", platform.Components{
+		Cluster: kubernetes.NewCustomKubernetes(kubernetes.Config{Pods: &collectLogsTestPods{}}),
+		Storage: s,
+		Store:   fakeStore.NewFakeStore(nil, nil, ignition),
+	})
+	require.NoError(t, err)
+
+	state := &TestState{
+		platform: p,
+	}
+
+	return state.ToStore(), s
+}
+
+// readTestArchive returns the files contained in a tar.gz archive.
+func readTestArchive(t *testing.T, input storage.UploadInput) map[string]string {
+	gz, err := gzip.NewReader(input.File)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+
+	return files
+}
+
+func TestCollectLogs(t *testing.T) {
+	store, s := newCollectLogsTestStore(t, true)
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "collect"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	input := CollectLogsInput{
+		Prefix: "group",
+		Pods: []CollectPodLogsInput{
+			{
+				Pod:         resource.NewResource("sim-1", "default", nil),
+				Containers:  []string{"gzserver", "missing"},
+				Directories: []LogDirectory{{Container: "gzserver", Path: "/tmp/logs"}},
+			},
+			{
+				Pod:         resource.NewResource("sim-2", "default", nil),
+				Containers:  []string{"bridge"},
+				Directories: []LogDirectory{{Container: "bridge", Path: "/tmp/truncated"}},
+			},
+		},
+	}
+	out, err := CollectLogs.Run(context.Background(), store, tx, deployment, input)
+	require.NoError(t, err)
+
+	expected := CollectLogsOutput{
+		{
+			Pod:    "sim-1",
+			Bucket: "logs",
+			Key:    "group/sim-1.tar.gz",
+			Errors: map[string]string{"missing": "container not found"},
+		},
+		{
+			Pod:    "sim-2",
+			Bucket: "logs",
+			Key:    "group/sim-2.tar.gz",
+			Errors: map[string]string{"bridge/tmp/truncated": io.ErrUnexpectedEOF.Error()},
+		},
+	}
+	assert.Equal(t, expected, out)
+
+	// Logs are packaged in an archive per pod
+	require.Len(t, s.uploads, 2)
+	upload := s.uploads["group/sim-1.tar.gz"]
+	assert.Equal(t, "logs", upload.Bucket)
+	assert.Equal(t, logsArchiveContentType, upload.ContentType)
+	assert.Equal(t, map[string]string{
+		"gzserver.log":                 "sim-1 gzserver",
+		"gzserver/tmp/logs/server.log": "sim-1 /tmp/logs",
+	}, readTestArchive(t, upload))
+	// Truncated directory archives keep the data read before the error
+	truncated := "sim-2 /tmp/truncated"
+	assert.Equal(t, map[string]string{
+		"bridge.log":                      "sim-2 bridge",
+		"bridge/tmp/truncated/server.log": truncated + strings.Repeat("\x00", 1024-len(truncated)),
+	}, readTestArchive(t, s.uploads["group/sim-2.tar.gz"]))
+
+	// The uploaded archives are recorded in the deployment
+	collected, err := GetCollectedLogs(tx, deployment, "collect")
+	require.NoError(t, err)
+	assert.Equal(t, expected, collected)
+}
+
+func TestCollectLogs_Disabled(t *testing.T) {
+	store, s := newCollectLogsTestStore(t, false)
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "collect"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	input := CollectLogsInput{
+		Pods: []CollectPodLogsInput{
+			{
+				Pod:        resource.NewResource("sim-1", "default", nil),
+				Containers: []string{"gzserver"},
+			},
+		},
+	}
+	out, err := CollectLogs.Run(context.Background(), store, tx, deployment, input)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+	assert.Empty(t, s.uploads)
+
+	_, err = GetCollectedLogs(tx, deployment, "collect")
+	assert.True(t, errors.Is(err, actions.ErrDeploymentDataNotFound))
+}
+
+func TestCopyTarEntries_KeepsEntriesInsideDirectory(t *testing.T) {
+	var src bytes.Buffer
+	tw := tar.NewWriter(&src)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../../etc/passwd", Mode: 0644, ModTime: time.Now()}))
+	require.NoError(t, tw.Close())
+
+	var dst bytes.Buffer
+	tw = tar.NewWriter(&dst)
+	sourceErr, err := copyTarEntries(tw, tar.NewReader(&src), "gzserver/logs")
+	require.NoError(t, sourceErr)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	header, err := tar.NewReader(&dst).Next()
+	require.NoError(t, err)
+	assert.Equal(t, "gzserver/logs/etc/passwd", header.Name)
+}
+
+func TestCopyTarEntries_TruncatedSource(t *testing.T) {
+	var src bytes.Buffer
+	tw := tar.NewWriter(&src)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "server.log", Mode: 0644, Size: 5, ModTime: time.Now()}))
+	_, err := tw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "client.log", Mode: 0644, Size: 1024, ModTime: time.Now()}))
+	_, err = tw.Write(bytes.Repeat([]byte("a"), 1024))
+	require.NoError(t, err)
+	require.NoError(t, tw.Flush())
+
+	// Cut the source archive in the middle of the second entry
+	truncated := bytes.NewReader(src.Bytes()[:src.Len()-512])
+
+	var dst bytes.Buffer
+	tw = tar.NewWriter(&dst)
+	sourceErr, err := copyTarEntries(tw, tar.NewReader(truncated), "gzserver/logs")
+	require.NoError(t, err)
+	assert.ErrorIs(t, sourceErr, io.ErrUnexpectedEOF)
+	require.NoError(t, tw.Close())
+
+	// The destination archive is valid and keeps the entries read before the error
+	tr := tar.NewReader(&dst)
+	header, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "gzserver/logs/server.log", header.Name)
+	content, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	header, err = tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "gzserver/logs/client.log", header.Name)
+	content, err = io.ReadAll(tr)
+	require.NoError(t, err)
+	assert.Len(t, content, 1024)
+
+	_, err = tr.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"context"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
+)
+
+// ComputeSummaryInput is the input of the ComputeSummary job.
+type ComputeSummaryInput struct {
+	// GroupID identifies the simulation.
+	GroupID simulations.GroupID
+	// Pod is the gzserver pod the statistics file is read from.
+	Pod resource.Resource
+	// Container is the name of the container the statistics file is read from.
+	Container string
+	// Path is the path of the YAML statistics file inside the container.
+	Path string
+	// Score is the simulation score. It is nil if the simulation was not scored.
+	Score *float64
+}
+
+// SummaryOutput contains a simulation summary returned by a job.
+// Job outputs are persisted as JSON, and simulations.Summary does not serialize all of its fields to JSON. SummaryOutput
+// serializes every field, so that outputs can be restored when deployments are resumed or inspected.
+type SummaryOutput struct {
+	// GroupID identifies the simulation.
+	GroupID simulations.GroupID
+	// Score is the simulation score. It is nil if the simulation was not scored.
+	Score *float64
+	// SimTimeDurationAvg is the average value of the simulation time duration.
+	SimTimeDurationAvg float64
+	// SimTimeDurationStdDev is the standard deviation value of the simulation time duration.
+	SimTimeDurationStdDev float64
+	// RealTimeDurationAvg is the average value of the real time duration.
+	RealTimeDurationAvg float64
+	// RealTimeDurationStdDev is the standard deviation value of the real time duration.
+	RealTimeDurationStdDev float64
+	// ModelCountAvg is the average value of the model count.
+	ModelCountAvg float64
+	// ModelCountStdDev is the standard deviation value of the model count.
+	ModelCountStdDev float64
+	// Sources stores the group ids of the children used to generate the summary.
+	Sources string
+	// RunData contains specific simulation run information.
+	RunData string
+}
+
+// newSummaryOutput returns the SummaryOutput of a simulation summary.
+func newSummaryOutput(summary *simulations.Summary) SummaryOutput {
+	out := SummaryOutput{
+		Score:                  summary.Score,
+		SimTimeDurationAvg:     summary.SimTimeDurationAvg,
+		SimTimeDurationStdDev:  summary.SimTimeDurationStdDev,
+		RealTimeDurationAvg:    summary.RealTimeDurationAvg,
+		RealTimeDurationStdDev: summary.RealTimeDurationStdDev,
+		ModelCountAvg:          summary.ModelCountAvg,
+		ModelCountStdDev:       summary.ModelCountStdDev,
+		Sources:                summary.Sources,
+		RunData:                summary.RunData,
+	}
+	if summary.GroupID != nil {
+		out.GroupID = *summary.GroupID
+	}
+
+	return out
+}
+
+// Summary returns the simulation summary contained in the output.
+func (o SummaryOutput) Summary() *simulations.Summary {
+	groupID := o.GroupID
+
+	return &simulations.Summary{
+		GroupID:                &groupID,
+		Score:                  o.Score,
+		SimTimeDurationAvg:     o.SimTimeDurationAvg,
+		SimTimeDurationStdDev:  o.SimTimeDurationStdDev,
+		RealTimeDurationAvg:    o.RealTimeDurationAvg,
+		RealTimeDurationStdDev: o.RealTimeDurationStdDev,
+		ModelCountAvg:          o.ModelCountAvg,
+		ModelCountStdDev:       o.ModelCountStdDev,
+		Sources:                o.Sources,
+		RunData:                o.RunData,
+	}
+}
+
+// ComputeSummaryOutput is the output of the ComputeSummary job.
+type ComputeSummaryOutput struct {
+	SummaryOutput
+}
+
+// ComputeSummary is a generic job to generate the summary of a single simulation.
+// It reads the YAML statistics file from the gzserver pod of the simulation. The contents of the file are stored in
+// the summary RunData.
+var ComputeSummary = &actions.Job{
+	Execute: computeSummary,
+}
+
+// computeSummary is the main function executed by the ComputeSummary job.
+func computeSummary(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
+	value interface{}) (interface{}, error) {
+
+	s := store.State().(state.PlatformGetter)
+
+	// Parse input
+	input, ok := value.(ComputeSummaryInput)
+	if !ok {
+		return nil, simulator.ErrInvalidInput
+	}
+
+	// Read the statistics file
+	file, err := s.Platform().Orchestrator().Pods().Reader(ctx, input.Pod).File(ctx, input.Container, input.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := simulations.ParseStatistics(file.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := simulations.NewSummary(simulations.RunResult{
+		GroupID:    input.GroupID,
+		Statistics: stats,
+		Score:      input.Score,
+		RunData:    file.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputeSummaryOutput{SummaryOutput: newSummaryOutput(summary)}, nil
+}
+
+// AggregateSummariesInput is the input of the AggregateSummaries job.
+type AggregateSummariesInput struct {
+	// Parent identifies the parent simulation.
+	Parent simulations.GroupID
+	// Children contains the results of each child simulation.
+	Children []simulations.RunResult
+}
+
+// AggregateSummariesOutput is the output of the AggregateSummaries job.
+type AggregateSummariesOutput struct {
+	SummaryOutput
+}
+
+// AggregateSummaries is a generic job to generate the summary of a parent simulation from the results of its
+// children. See simulations.NewParentSummary for details on how children that failed or were never started are handled.
+var AggregateSummaries = &actions.Job{
+	Execute: aggregateSummaries,
+}
+
+// aggregateSummaries is the main function executed by the AggregateSummaries job.
+func aggregateSummaries(ctx context.Context, store actions.Store, tx actions.DeploymentStore,
+	deployment *actions.Deployment, value interface{}) (interface{}, error) {
+
+	// Parse input
+	input, ok := value.(AggregateSummariesInput)
+	if !ok {
+		return nil, simulator.ErrInvalidInput
+	}
+
+	summary := simulations.NewParentSummary(input.Parent, input.Children)
+
+	return AggregateSummariesOutput{SummaryOutput: newSummaryOutput(summary)}, nil
+}
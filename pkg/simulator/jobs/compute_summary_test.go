@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/implementations/kubernetes"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// statisticsTestPods is a pods.Pods implementation that returns readers for a set of files.
+type statisticsTestPods struct {
+	pods.Pods
+	files map[string]string
+}
+
+func (p *statisticsTestPods) Reader(ctx context.Context, resource resource.Resource) pods.Reader {
+	return &statisticsTestReader{files: p.files}
+}
+
+// statisticsTestReader is a pods.Reader implementation that reads files from memory.
+type statisticsTestReader struct {
+	pods.Reader
+	files map[string]string
+}
+
+func (r *statisticsTestReader) File(ctx context.Context, container string, paths ...string) (*bytes.Buffer, error) {
+	file, ok := r.files[paths[0]]
+	if !ok {
+		return nil, errors.New("file not found")
+	}
+	return bytes.NewBufferString(file), nil
+}
+
+// newStatisticsTestStore returns an actions store with a platform whose pods contain the given files.
+func newStatisticsTestStore(t *testing.T, files map[string]string) actions.Store {
+	p, err := platform.NewPlatform("test", platform.Components{
+		Cluster: kubernetes.NewCustomKubernetes(kubernetes.Config{Pods: &statisticsTestPods{files: files}}),
+	})
+	require.NoError(t, err)
+
+	state := &TestState{
+		platform: p,
+	}
+
+	return state.ToStore()
+}
+
+func TestComputeSummary(t *testing.T) {
+	stats := "was_started: 1\nsim_time_duration_sec: 120\nreal_time_duration_sec: 240\nmodel_count: 5\n"
+	store := newStatisticsTestStore(t, map[string]string{"/tmp/ign/logs/statistics.yml": stats})
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	input := ComputeSummaryInput{
+		GroupID:   "sim",
+		Pod:       resource.NewResource("sim-gzserver", "default", nil),
+		Container: "gzserver",
+		Path:      "/tmp/ign/logs/statistics.yml",
+	}
+	out, err := ComputeSummary.Run(context.Background(), store, tx, deployment, input)
+	require.NoError(t, err)
+
+	summary := out.(ComputeSummaryOutput).Summary()
+	assert.Equal(t, simulations.GroupID("sim"), *summary.GroupID)
+	assert.Equal(t, 120.0, summary.SimTimeDurationAvg)
+	assert.Equal(t, 240.0, summary.RealTimeDurationAvg)
+	assert.Equal(t, 5.0, summary.ModelCountAvg)
+	assert.Equal(t, stats, summary.RunData)
+
+	// Missing statistics files fail the job
+	input.Path = "/missing.yml"
+	_, err = ComputeSummary.Run(context.Background(), store, tx, deployment, input)
+	assert.Error(t, err)
+}
+
+func TestAggregateSummaries(t *testing.T) {
+	store := newStatisticsTestStore(t, nil)
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	input := AggregateSummariesInput{
+		Parent: "parent",
+		Children: []simulations.RunResult{
+			{GroupID: "child-1", Statistics: &simulations.Statistics{Started: 1, ModelCount: 2}},
+			{GroupID: "child-2", Statistics: &simulations.Statistics{Started: 1, ModelCount: 4}},
+			{GroupID: "child-3", Statistics: &simulations.Statistics{Started: 0, ModelCount: 10}},
+		},
+	}
+	out, err := AggregateSummaries.Run(context.Background(), store, tx, deployment, input)
+	require.NoError(t, err)
+
+	summary := out.(AggregateSummariesOutput).Summary()
+	assert.Equal(t, simulations.GroupID("parent"), *summary.GroupID)
+	assert.Equal(t, "child-1,child-2", summary.Sources)
+	assert.Equal(t, 3.0, summary.ModelCountAvg)
+	assert.Equal(t, 1.0, summary.ModelCountStdDev)
+}
+
+func TestSummaryOutput_PersistsAllFields(t *testing.T) {
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	// Create an action to register the job's datatypes in the registry
+	_, err := actions.NewAction(actions.Jobs{
+		ComputeSummary.Extend(actions.Job{
+			Name:       "compute",
+			InputType:  actions.GetJobDataType(ComputeSummaryInput{}),
+			OutputType: actions.GetJobDataType(ComputeSummaryOutput{}),
+		}),
+		AggregateSummaries.Extend(actions.Job{
+			Name:       "aggregate",
+			InputType:  actions.GetJobDataType(AggregateSummariesInput{}),
+			OutputType: actions.GetJobDataType(AggregateSummariesOutput{}),
+		}),
+	})
+	require.NoError(t, err)
+
+	score := 0.5
+	groupID := simulations.GroupID("sim")
+	summary := &simulations.Summary{
+		GroupID:                &groupID,
+		Score:                  &score,
+		SimTimeDurationAvg:     1,
+		SimTimeDurationStdDev:  2,
+		RealTimeDurationAvg:    3,
+		RealTimeDurationStdDev: 4,
+		ModelCountAvg:          5,
+		ModelCountStdDev:       6,
+		Sources:                "child-1,child-2",
+		RunData:                "run data",
+	}
+
+	for job, out := range map[string]interface{}{
+		"compute":   ComputeSummaryOutput{SummaryOutput: newSummaryOutput(summary)},
+		"aggregate": AggregateSummariesOutput{SummaryOutput: newSummaryOutput(summary)},
+	} {
+		job := job
+		require.NoError(t, deployment.SetJobData(tx, &job, actions.DeploymentJobOutput, out))
+
+		restored, err := deployment.GetJobData(tx, &job, actions.DeploymentJobOutput)
+		require.NoError(t, err)
+		assert.Equal(t, out, restored)
+	}
+
+	// Summaries are restored from outputs
+	restored := newSummaryOutput(summary).Summary()
+	assert.Equal(t, summary, restored)
+}
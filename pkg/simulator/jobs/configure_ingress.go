@@ -5,7 +5,6 @@ import (
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/ingresses"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 	"time"
 )
 
@@ -29,12 +28,12 @@ var ConfigureIngress = &actions.Job{
 }
 
 // configureIngress is used by the ConfigureIngress job as the execute function.
-func configureIngress(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func configureIngress(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	input := value.(ConfigureIngressInput)
 
-	res, err := s.Platform().Orchestrator().Ingresses().Get(context.TODO(), input.Name, input.Namespace)
+	res, err := s.Platform().Orchestrator().Ingresses().Get(ctx, input.Name, input.Namespace)
 	if err != nil {
 		return ConfigureIngressOutput{
 			Error: err,
@@ -51,13 +50,13 @@ func configureIngress(store actions.Store, tx *gorm.DB, deployment *actions.Depl
 
 		// Get the rule for the given host
 		var rule ingresses.Rule
-		rule, err = s.Platform().Orchestrator().IngressRules().Get(context.TODO(), res, input.Host)
+		rule, err = s.Platform().Orchestrator().IngressRules().Get(ctx, res, input.Host)
 		if err != nil {
 			continue
 		}
 
 		// Update paths.
-		err = s.Platform().Orchestrator().IngressRules().Upsert(context.TODO(), rule, input.Paths...)
+		err = s.Platform().Orchestrator().IngressRules().Upsert(ctx, rule, input.Paths...)
 		if err != nil {
 			continue
 		}
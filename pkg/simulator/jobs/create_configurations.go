@@ -7,7 +7,6 @@ import (
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // CreateConfigurationsInput is the input of the CreateConfigurations job.
@@ -31,7 +30,7 @@ var CreateConfigurations = &actions.Job{
 }
 
 // createConfigurations is the main function executed by the CreateConfigurations job.
-func createConfigurations(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func createConfigurations(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	// Parse input
@@ -57,7 +56,7 @@ func createConfigurations(store actions.Store, tx *gorm.DB, deployment *actions.
 
 	for _, in := range input {
 		var res resource.Resource
-		res, err = s.Platform().Orchestrator().Configurations().Create(context.TODO(), in)
+		res, err = s.Platform().Orchestrator().Configurations().Create(ctx, in)
 		if err != nil {
 			return nil, err
 		}
@@ -79,7 +78,7 @@ func createConfigurations(store actions.Store, tx *gorm.DB, deployment *actions.
 
 // DeleteCreatedConfigurationsOnFailure is an optional rollback handler that removes any created configurations when
 // an action fails.
-func DeleteCreatedConfigurationsOnFailure(store actions.Store, tx *gorm.DB, deployment *actions.Deployment,
+func DeleteCreatedConfigurationsOnFailure(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
 	value interface{}, err error) (interface{}, error) {
 
 	// Get the store
@@ -100,7 +99,7 @@ func DeleteCreatedConfigurationsOnFailure(store actions.Store, tx *gorm.DB, depl
 			namespace,
 			nil,
 		)
-		_, _ = s.Platform().Orchestrator().Configurations().Delete(context.TODO(), res)
+		_, _ = s.Platform().Orchestrator().Configurations().Delete(ctx, res)
 	}
 
 	return nil, nil
@@ -68,6 +68,7 @@ func (suite *testCreateConfigurationsSuite) TestRemoveCreatedConfigurationsOnFai
 
 	err = actions.CleanAndMigrateDB(db)
 	suite.Require().NoError(err)
+	tx := actions.NewGormDeploymentStore(db)
 
 	// Create action to register the job's datatypes in the registry
 	_, err = actions.NewAction(
@@ -100,7 +101,7 @@ func (suite *testCreateConfigurationsSuite) TestRemoveCreatedConfigurationsOnFai
 	suite.Require().Equal(0, suite.getNumberOfConfigurations())
 
 	// Create the configurations
-	_, err = CreateConfigurations.Run(store, db, deployment, CreateConfigurationsInput{
+	_, err = CreateConfigurations.Run(context.Background(), store, tx, deployment, CreateConfigurationsInput{
 		{
 			Name:      suite.configurationName1,
 			Namespace: suite.namespace,
@@ -121,7 +122,7 @@ func (suite *testCreateConfigurationsSuite) TestRemoveCreatedConfigurationsOnFai
 
 	// Run the rollback handler
 	err = errors.New("error")
-	_, err = DeleteCreatedConfigurationsOnFailure(store, db, deployment, nil, err)
+	_, err = DeleteCreatedConfigurationsOnFailure(context.Background(), store, tx, deployment, nil, err)
 	suite.Assert().NoError(err)
 
 	// Verify that configurations no longer exist
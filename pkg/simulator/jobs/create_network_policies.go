@@ -6,7 +6,6 @@ import (
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/network"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // CreateNetworkPoliciesInput is the input for the CreateNetworkPolicies job.
@@ -22,19 +21,25 @@ type CreateNetworkPoliciesOutput struct {
 }
 
 // CreateNetworkPolicies is a generic job to be used to create network policies.
+// It includes a rollback handler to remove the network policies that were created in this job.
 var CreateNetworkPolicies = &actions.Job{
-	Execute: createNetworkPolicies,
+	Execute:         createNetworkPolicies,
+	RollbackHandler: removeCreatedNetworkPolicies,
 }
 
+// jobCreateNetworkPoliciesDataKey is the key used to persist the list of network policies that were created in the
+// CreateNetworkPolicies job.
+const jobCreateNetworkPoliciesDataKey = "created-network-policies"
+
 // createNetworkPolicies is used by the CreateNetworkPolicies job as the execute function.
-func createNetworkPolicies(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func createNetworkPolicies(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	input := value.(CreateNetworkPoliciesInput)
 
 	resources := make([]resource.Resource, 0, len(input))
 	for _, in := range input {
-		res, err := s.Platform().Orchestrator().NetworkPolicies().Create(context.TODO(), in)
+		res, err := s.Platform().Orchestrator().NetworkPolicies().Create(ctx, in)
 
 		if err != nil {
 			return CreateNetworkPoliciesOutput{
@@ -44,6 +49,12 @@ func createNetworkPolicies(store actions.Store, tx *gorm.DB, deployment *actions
 		}
 
 		resources = append(resources, res)
+
+		// Persist the list of network policies after each one is created so that they can be removed by the rollback
+		// handler
+		if err := setCreatedResources(tx, deployment, jobCreateNetworkPoliciesDataKey, resources); err != nil {
+			return nil, err
+		}
 	}
 
 	return CreateNetworkPoliciesOutput{
@@ -51,3 +62,24 @@ func createNetworkPolicies(store actions.Store, tx *gorm.DB, deployment *actions
 		Error:    nil,
 	}, nil
 }
+
+// removeCreatedNetworkPolicies is the rollback handler of the CreateNetworkPolicies job. It removes the network
+// policies created by the job.
+func removeCreatedNetworkPolicies(ctx context.Context, store actions.Store, tx actions.DeploymentStore,
+	deployment *actions.Deployment, value interface{}, err error) (interface{}, error) {
+
+	s := store.State().(state.PlatformGetter)
+
+	// Get the list of network policies from the execute function
+	created, dataErr := getCreatedResources(tx, deployment, jobCreateNetworkPoliciesDataKey)
+	if dataErr != nil {
+		return nil, dataErr
+	}
+
+	// Remove the network policies
+	for _, res := range created {
+		_ = s.Platform().Orchestrator().NetworkPolicies().Remove(ctx, res.Name(), res.Namespace())
+	}
+
+	return nil, nil
+}
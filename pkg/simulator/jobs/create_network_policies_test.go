@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/network"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestCreateNetworkPolicies_RollbackRemovesCreatedPolicies(t *testing.T) {
+	store, api := newFakeKubernetesTestStore(t)
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	newInput := func(name string) network.CreateNetworkPolicyInput {
+		return network.CreateNetworkPolicyInput{
+			Name:        name,
+			Namespace:   "default",
+			PodSelector: resource.NewSelector(map[string]string{"app": name}),
+		}
+	}
+
+	// The last policy fails to be created because it already exists
+	out, err := CreateNetworkPolicies.Run(context.Background(), store, tx, deployment, CreateNetworkPoliciesInput{
+		newInput("policy-1"),
+		newInput("policy-2"),
+		newInput("policy-2"),
+	})
+	require.NoError(t, err)
+	require.Error(t, out.(CreateNetworkPoliciesOutput).Error)
+
+	list, err := api.NetworkingV1().NetworkPolicies("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 2)
+
+	// Run the rollback handler
+	_, err = CreateNetworkPolicies.RollbackHandler(context.Background(), store, tx, deployment, nil, nil)
+	require.NoError(t, err)
+
+	list, err = api.NetworkingV1().NetworkPolicies("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
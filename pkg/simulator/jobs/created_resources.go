@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+)
+
+// createdResource is a serializable representation of a cluster resource created by a job.
+// Jobs persist the resources they create as deployment job data to remove them if the action is rolled back.
+type createdResource struct {
+	Name      string
+	Namespace string
+	Selector  map[string]string
+}
+
+// newCreatedResource creates a createdResource from a resource.Resource.
+func newCreatedResource(res resource.Resource) createdResource {
+	created := createdResource{
+		Name:      res.Name(),
+		Namespace: res.Namespace(),
+	}
+	if res.Selector() != nil {
+		created.Selector = res.Selector().Map()
+	}
+
+	return created
+}
+
+// toResource returns the resource.Resource representation of the created resource.
+func (r createdResource) toResource() resource.Resource {
+	return resource.NewResource(r.Name, r.Namespace, resource.NewSelector(r.Selector))
+}
+
+// setCreatedResources persists the list of resources created by the current job of a deployment.
+func setCreatedResources(tx actions.DeploymentStore, deployment *actions.Deployment, key actions.DeploymentDataType,
+	resources []resource.Resource) error {
+
+	created := make([]createdResource, len(resources))
+	for i, res := range resources {
+		created[i] = newCreatedResource(res)
+	}
+
+	return deployment.SetJobData(tx, nil, key, created)
+}
+
+// getCreatedResources returns the list of resources persisted by the current job of a deployment.
+// Returns an empty list if the job did not persist any resources.
+func getCreatedResources(tx actions.DeploymentStore, deployment *actions.Deployment,
+	key actions.DeploymentDataType) ([]resource.Resource, error) {
+
+	var created []createdResource
+	err := deployment.GetJobDataOutValue(tx, nil, key, &created)
+	if errors.Is(err, actions.ErrDeploymentDataNotFound) || errors.Is(err, actions.ErrDeploymentDataNoData) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]resource.Resource, len(created))
+	for i, c := range created {
+		resources[i] = c.toResource()
+	}
+
+	return resources, nil
+}
@@ -1,10 +1,14 @@
 package jobs
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
+	"strings"
+	"time"
 )
 
 // LaunchInstancesInput is the input of the LaunchInstances job.
@@ -17,15 +21,44 @@ type LaunchInstancesOutput []machines.CreateMachinesOutput
 
 // LaunchInstances is a generic job to launch instances.
 // It includes a rollback handler to terminate the instances that were created in this job.
+// The job is not retried by default. Applications can retry requests that fail because the cloud provider is out of
+// capacity or is throttling requests by extending the job with a RetryPolicy, such as LaunchInstancesRetryPolicy.
+// Instances created by a failed attempt are terminated before retrying.
 var LaunchInstances = &actions.Job{
 	Execute:         launchInstances,
 	RollbackHandler: removeCreatedInstances,
 }
 
+// LaunchInstancesRetryPolicy is a retry policy for the LaunchInstances job.
+// It can be used by extending the job:
+//
+//	jobs.LaunchInstances.Extend(actions.Job{RetryPolicy: jobs.LaunchInstancesRetryPolicy})
+var LaunchInstancesRetryPolicy = &actions.RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 15 * time.Second,
+	MaxBackoff:     2 * time.Minute,
+	Multiplier:     2,
+	Jitter:         0.2,
+	Retryable:      machines.ErrorIsRetryable,
+}
+
 // jobLaunchInstancesDataKey is the key used to persist the list of machines that were created in the LaunchInstances job.
 const jobLaunchInstancesDataKey = "created-machines"
 
-func launchInstances(store actions.Store, tx *gorm.DB, deployment *actions.Deployment,
+// GetLaunchedInstances returns the list of instances created by a LaunchInstances job in a deployment.
+// `job` is the name of the LaunchInstances job in the deployment action.
+func GetLaunchedInstances(tx actions.DeploymentStore, deployment *actions.Deployment,
+	job string) (LaunchInstancesOutput, error) {
+
+	var out LaunchInstancesOutput
+	if err := deployment.GetJobDataOutValue(tx, &job, jobLaunchInstancesDataKey, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func launchInstances(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
 	value interface{}) (interface{}, error) {
 
 	// Get the store
@@ -34,6 +67,11 @@ func launchInstances(store actions.Store, tx *gorm.DB, deployment *actions.Deplo
 	// Parse the input
 	in := value.(LaunchInstancesInput)
 
+	// Terminate any instances created by a previous attempt
+	if _, err := removeCreatedInstances(ctx, store, tx, deployment, nil, nil); err != nil {
+		return nil, err
+	}
+
 	// Trigger the machine creation.
 	// If Machines.Create returns an error, it will return any machines that were successfully requested and provisioned
 	// until the error was encountered. This job does not end if an error is returned here. Instead, the next block is
@@ -53,7 +91,7 @@ func launchInstances(store actions.Store, tx *gorm.DB, deployment *actions.Deplo
 	return LaunchInstancesOutput(out), nil
 }
 
-func removeCreatedInstances(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{},
+func removeCreatedInstances(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{},
 	err error) (interface{}, error) {
 
 	// Get the store
@@ -61,6 +99,9 @@ func removeCreatedInstances(store actions.Store, tx *gorm.DB, deployment *action
 
 	// Get the list of instances from the execute function.
 	data, dataErr := deployment.GetJobData(tx, nil, jobLaunchInstancesDataKey)
+	if errors.Is(dataErr, actions.ErrDeploymentDataNotFound) || errors.Is(dataErr, actions.ErrDeploymentDataNoData) {
+		return nil, nil
+	}
 	if dataErr != nil {
 		return nil, dataErr
 	}
@@ -71,9 +112,15 @@ func removeCreatedInstances(store actions.Store, tx *gorm.DB, deployment *action
 		return nil, err
 	}
 
-	// Terminate the instances
+	// Terminate the instances. Errors are returned to avoid leaving instances running.
+	var errs []string
 	for _, c := range createdInstances {
-		_ = s.Platform().Machines().Terminate(c.ToTerminateMachinesInput())
+		if err := s.Platform().Machines().Terminate(c.ToTerminateMachinesInput()); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to terminate instances: %s", strings.Join(errs, "; "))
 	}
 
 	return nil, nil
@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"context"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/cloud/fake"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLaunchInstances_RetriesRetryableErrors(t *testing.T) {
+	m := fake.NewMachines()
+	p, err := platform.NewPlatform("test", platform.Components{
+		Machines: m,
+	})
+	require.NoError(t, err)
+	state := &TestState{
+		platform: p,
+	}
+	store := state.ToStore()
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	job := LaunchInstances.Extend(actions.Job{
+		Name:        "test",
+		RetryPolicy: &actions.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		InputType:   actions.GetJobDataType(LaunchInstancesInput{}),
+		OutputType:  actions.GetJobDataType(LaunchInstancesOutput{}),
+	})
+
+	// Create an action to register the job's datatypes in the registry
+	_, err = actions.NewAction(actions.Jobs{job})
+	require.NoError(t, err)
+
+	input := LaunchInstancesInput{{}, {}}
+	partial := []machines.CreateMachinesOutput{{Instances: []string{"partial"}}}
+	created := []machines.CreateMachinesOutput{{Instances: []string{"a"}}, {Instances: []string{"b"}}}
+
+	// The first attempt fails after creating some of the instances
+	createErr := machines.WrapRetryableError(machines.ErrInsufficientMachines)
+	m.On("Create", []machines.CreateMachinesInput(input)).Return(partial, createErr).Once()
+	m.On("Create", []machines.CreateMachinesInput(input)).Return(created, nil).Once()
+	m.On("Terminate", mock.Anything).Return(nil)
+
+	out, err := job.Run(context.Background(), store, tx, deployment, input)
+	require.NoError(t, err)
+	assert.Equal(t, LaunchInstancesOutput(created), out)
+
+	// Instances created by the failed attempt should have been terminated before retrying
+	m.AssertCalled(t, "Terminate", partial[0].ToTerminateMachinesInput())
+	m.AssertNumberOfCalls(t, "Terminate", 1)
+}
+
+func TestLaunchInstances_NotRetriedByDefault(t *testing.T) {
+	assert.Nil(t, LaunchInstances.RetryPolicy)
+}
+
+func TestLaunchInstances_RollbackReturnsTerminateErrors(t *testing.T) {
+	m := fake.NewMachines()
+	p, err := platform.NewPlatform("test", platform.Components{
+		Machines: m,
+	})
+	require.NoError(t, err)
+	state := &TestState{
+		platform: p,
+	}
+	store := state.ToStore()
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	job := LaunchInstances.Extend(actions.Job{
+		Name:       "test",
+		InputType:  actions.GetJobDataType(LaunchInstancesInput{}),
+		OutputType: actions.GetJobDataType(LaunchInstancesOutput{}),
+	})
+
+	// Create an action to register the job's datatypes in the registry
+	_, err = actions.NewAction(actions.Jobs{job})
+	require.NoError(t, err)
+
+	input := LaunchInstancesInput{{}, {}}
+	created := []machines.CreateMachinesOutput{{Instances: []string{"a"}}, {Instances: []string{"b"}}}
+	m.On("Create", []machines.CreateMachinesInput(input)).Return(created, nil).Once()
+	m.On("Terminate", created[0].ToTerminateMachinesInput()).Return(assert.AnError)
+	m.On("Terminate", created[1].ToTerminateMachinesInput()).Return(nil)
+
+	_, err = job.Run(context.Background(), store, tx, deployment, input)
+	require.NoError(t, err)
+
+	// Every instance is terminated, and termination errors are returned
+	_, err = job.RollbackHandler(context.Background(), store, tx, deployment, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), assert.AnError.Error())
+	m.AssertNumberOfCalls(t, "Terminate", 2)
+}
@@ -2,12 +2,16 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
+	"sort"
+	"strings"
+	"sync"
 )
 
 // LaunchPodsInput is the input of the LaunchPods job.
@@ -21,12 +25,55 @@ type LaunchPodsOutput struct {
 }
 
 // LaunchPods is a generic job to launch pods on a cluster.
+// Pods are created concurrently. If any pod fails to be created, the job returns a *LaunchPodsError with the error of
+// each pod that failed.
+// It includes a rollback handler to remove the pods that were created in this job.
 var LaunchPods = &actions.Job{
-	Execute: launchPods,
+	Execute:         launchPods,
+	RollbackHandler: removeCreatedPods,
 }
 
+// LaunchPodsConcurrency is the maximum number of pods created at the same time by the LaunchPods job.
+var LaunchPodsConcurrency = 10
+
+// LaunchPodsError is returned by the LaunchPods job when one or more pods fail to be created.
+type LaunchPodsError struct {
+	// Errors contains the error returned for each pod that failed to be created, indexed by pod name.
+	Errors map[string]error
+}
+
+// Error returns the errors of every pod that failed to be created, sorted by pod name.
+func (e *LaunchPodsError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = fmt.Sprintf("[%s]: %s", name, e.Errors[name])
+	}
+
+	return fmt.Sprintf("failed to launch %d pods: %s", len(names), strings.Join(messages, "; "))
+}
+
+// Is allows checking if any of the pod errors matches `target` using errors.Is.
+func (e *LaunchPodsError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jobLaunchPodsDataKey is the key used to persist the list of pods that were created in the LaunchPods job.
+const jobLaunchPodsDataKey = "created-pods"
+
 // launchPods is the main function executed by the LaunchPods job.
-func launchPods(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func launchPods(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	// Parse input
@@ -51,20 +98,100 @@ func launchPods(store actions.Store, tx *gorm.DB, deployment *actions.Deployment
 		}, nil
 	}
 
-	var created []resource.Resource
-	var err error
-
-	for _, in := range input {
-		var res resource.Resource
-		res, err = s.Platform().Orchestrator().Pods().Create(context.Background(), in)
-		if err != nil {
-			return nil, err
-		}
-		created = append(created, res)
+	created, err := createPods(ctx, s.Platform().Orchestrator().Pods(), tx, deployment, input)
+	if err != nil {
+		return nil, err
 	}
 
 	return LaunchPodsOutput{
 		Resources: created,
-		Error:     err,
+		Error:     nil,
 	}, nil
 }
+
+// createPods creates a set of pods concurrently, creating at most LaunchPodsConcurrency pods at the same time.
+// The list of created pods is persisted every time a pod is created, so that the rollback handler can remove them if
+// any of the pods fails to be created. All pods are attempted even if some of them fail.
+// Returns the created pods in the same order as the input, or a *LaunchPodsError if any pod failed to be created.
+func createPods(ctx context.Context, p pods.Pods, tx actions.DeploymentStore, deployment *actions.Deployment,
+	input LaunchPodsInput) ([]resource.Resource, error) {
+
+	concurrency := LaunchPodsConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]resource.Resource, len(input))
+	launchErr := &LaunchPodsError{
+		Errors: make(map[string]error),
+	}
+
+	// lock synchronizes access to results and launchErr, and serializes persisting the created pods
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i, in := range input {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, in pods.CreatePodInput) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res, err := p.Create(ctx, in)
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			if err != nil {
+				launchErr.Errors[in.Name] = err
+				return
+			}
+			results[i] = res
+
+			// Persist the list of pods after each pod is created so that they can be removed by the rollback handler
+			if err := setCreatedResources(tx, deployment, jobLaunchPodsDataKey, compactResources(results)); err != nil {
+				launchErr.Errors[in.Name] = err
+			}
+		}(i, in)
+	}
+	wg.Wait()
+
+	if len(launchErr.Errors) > 0 {
+		return nil, launchErr
+	}
+
+	return results, nil
+}
+
+// compactResources returns the non-nil resources in a list of resources.
+func compactResources(resources []resource.Resource) []resource.Resource {
+	out := make([]resource.Resource, 0, len(resources))
+	for _, res := range resources {
+		if res != nil {
+			out = append(out, res)
+		}
+	}
+
+	return out
+}
+
+// removeCreatedPods is the rollback handler of the LaunchPods job. It removes the pods created by the job.
+func removeCreatedPods(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
+	value interface{}, err error) (interface{}, error) {
+
+	s := store.State().(state.PlatformGetter)
+
+	// Get the list of pods from the execute function
+	created, dataErr := getCreatedResources(tx, deployment, jobLaunchPodsDataKey)
+	if dataErr != nil {
+		return nil, dataErr
+	}
+
+	// Remove the pods
+	for _, res := range created {
+		_, _ = s.Platform().Orchestrator().Pods().Delete(ctx, res)
+	}
+
+	return nil, nil
+}
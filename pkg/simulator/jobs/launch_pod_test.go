@@ -0,0 +1,178 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/implementations/kubernetes"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newFakeKubernetesTestStore returns an actions store with a platform that uses a fake Kubernetes cluster.
+func newFakeKubernetesTestStore(t *testing.T) (actions.Store, *fake.Clientset) {
+	cluster, api := kubernetes.NewFakeKubernetes(gz.NewLoggerNoRollbar("Test", gz.VerbosityWarning))
+	p, err := platform.NewPlatform("test", platform.Components{
+		Cluster: cluster,
+	})
+	require.NoError(t, err)
+
+	state := &TestState{
+		platform: p,
+	}
+
+	return state.ToStore(), api
+}
+
+func TestLaunchPods_RollbackRemovesCreatedPods(t *testing.T) {
+	store, api := newFakeKubernetesTestStore(t)
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	// The second pod fails to be created because it already exists
+	input := LaunchPodsInput{
+		{Name: "pod-1", Namespace: "default"},
+		{Name: "pod-2", Namespace: "default"},
+		{Name: "pod-2", Namespace: "default"},
+	}
+	_, err := LaunchPods.Run(context.Background(), store, tx, deployment, input)
+	require.Error(t, err)
+
+	list, err := api.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 2)
+
+	// Run the rollback handler
+	_, err = LaunchPods.RollbackHandler(context.Background(), store, tx, deployment, nil, err)
+	require.NoError(t, err)
+
+	// Pods created before the failure have been removed
+	list, err = api.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
+
+func TestLaunchPods_RollbackWithoutCreatedPods(t *testing.T) {
+	store, _ := newFakeKubernetesTestStore(t)
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	// The rollback handler does nothing if the job did not create any pods
+	_, err := LaunchPods.RollbackHandler(context.Background(), store, tx, deployment, nil, nil)
+	assert.NoError(t, err)
+
+	_, err = LaunchPods.Run(context.Background(), store, tx, deployment, LaunchPodsInput([]pods.CreatePodInput{}))
+	require.NoError(t, err)
+	_, err = LaunchPods.RollbackHandler(context.Background(), store, tx, deployment, nil, nil)
+	assert.NoError(t, err)
+}
+
+// concurrencyTestPods is a pods.Pods implementation that keeps track of the number of pods created at the same time.
+type concurrencyTestPods struct {
+	pods.Pods
+	lock    sync.Mutex
+	running int
+	max     int
+	// fail contains the names of the pods that fail to be created.
+	fail map[string]bool
+}
+
+func (p *concurrencyTestPods) Create(ctx context.Context, input pods.CreatePodInput) (*pods.PodResource, error) {
+	p.lock.Lock()
+	p.running++
+	if p.running > p.max {
+		p.max = p.running
+	}
+	p.lock.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	p.lock.Lock()
+	p.running--
+	p.lock.Unlock()
+
+	if p.fail[input.Name] {
+		return nil, errors.New("create failed")
+	}
+
+	return &pods.PodResource{
+		Resource: resource.NewResource(input.Name, input.Namespace, nil),
+	}, nil
+}
+
+func TestCreatePods_BoundedConcurrency(t *testing.T) {
+	defer func(concurrency int) { LaunchPodsConcurrency = concurrency }(LaunchPodsConcurrency)
+	LaunchPodsConcurrency = 3
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	p := &concurrencyTestPods{}
+	input := make(LaunchPodsInput, 10)
+	for i := range input {
+		input[i] = pods.CreatePodInput{Name: fmt.Sprintf("pod-%d", i), Namespace: "default"}
+	}
+
+	created, err := createPods(context.Background(), p, tx, deployment, input)
+	require.NoError(t, err)
+
+	// Pods are created concurrently without exceeding the limit
+	assert.Equal(t, 3, p.max)
+
+	// Pods are returned in the same order as the input
+	require.Len(t, created, len(input))
+	for i, res := range created {
+		assert.Equal(t, input[i].Name, res.Name())
+	}
+}
+
+func TestCreatePods_AggregatesErrorsAndPersistsCreatedPods(t *testing.T) {
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	p := &concurrencyTestPods{
+		fail: map[string]bool{"pod-1": true, "pod-3": true},
+	}
+	input := LaunchPodsInput{
+		{Name: "pod-0", Namespace: "default"},
+		{Name: "pod-1", Namespace: "default"},
+		{Name: "pod-2", Namespace: "default"},
+		{Name: "pod-3", Namespace: "default"},
+	}
+
+	_, err := createPods(context.Background(), p, tx, deployment, input)
+	require.Error(t, err)
+
+	// Every failed pod is reported
+	var launchErr *LaunchPodsError
+	require.True(t, errors.As(err, &launchErr))
+	assert.Len(t, launchErr.Errors, 2)
+	assert.Contains(t, launchErr.Errors, "pod-1")
+	assert.Contains(t, launchErr.Errors, "pod-3")
+	assert.Equal(t, "failed to launch 2 pods: [pod-1]: create failed; [pod-3]: create failed", err.Error())
+
+	// Only the pods that were created are persisted for the rollback handler
+	created, err := getCreatedResources(tx, deployment, jobLaunchPodsDataKey)
+	require.NoError(t, err)
+	names := make([]string, len(created))
+	for i, res := range created {
+		names[i] = res.Name()
+	}
+	assert.Equal(t, []string{"pod-0", "pod-2"}, names)
+}
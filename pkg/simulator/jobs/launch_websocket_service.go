@@ -6,7 +6,6 @@ import (
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/services"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // LaunchWebsocketServiceInput is the input of the LaunchWebsocketService job.
@@ -20,22 +19,56 @@ type LaunchWebsocketServiceOutput struct {
 }
 
 // LaunchWebsocketService is generic to job to launch a simulation's websocket service.
+// It includes a rollback handler to remove the service if it was created in this job.
 var LaunchWebsocketService = &actions.Job{
-	Execute: launchWebsocketService,
+	Execute:         launchWebsocketService,
+	RollbackHandler: removeCreatedWebsocketService,
 }
 
+// jobLaunchWebsocketServiceDataKey is the key used to persist the service created in the LaunchWebsocketService job.
+const jobLaunchWebsocketServiceDataKey = "created-services"
+
 // launchWebsocketService is the main function executed by the LaunchWebsocketService job.
-func launchWebsocketService(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func launchWebsocketService(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	// Parse input
 	input := value.(LaunchWebsocketServiceInput)
 
 	// Create service
-	res, err := s.Platform().Orchestrator().Services().Create(context.TODO(), services.CreateServiceInput(input))
+	res, err := s.Platform().Orchestrator().Services().Create(ctx, services.CreateServiceInput(input))
+
+	// Persist the service so that it can be removed by the rollback handler
+	if err == nil {
+		if dataErr := setCreatedResources(tx, deployment, jobLaunchWebsocketServiceDataKey,
+			[]resource.Resource{res}); dataErr != nil {
+			return nil, dataErr
+		}
+	}
 
 	return LaunchWebsocketServiceOutput{
 		Resource: res,
 		Error:    err,
 	}, nil
 }
+
+// removeCreatedWebsocketService is the rollback handler of the LaunchWebsocketService job. It removes the service
+// created by the job.
+func removeCreatedWebsocketService(ctx context.Context, store actions.Store, tx actions.DeploymentStore,
+	deployment *actions.Deployment, value interface{}, err error) (interface{}, error) {
+
+	s := store.State().(state.PlatformGetter)
+
+	// Get the service from the execute function
+	created, dataErr := getCreatedResources(tx, deployment, jobLaunchWebsocketServiceDataKey)
+	if dataErr != nil {
+		return nil, dataErr
+	}
+
+	// Remove the service
+	for _, res := range created {
+		_ = s.Platform().Orchestrator().Services().Remove(ctx, res)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestLaunchWebsocketService_RollbackRemovesCreatedService(t *testing.T) {
+	store, api := newFakeKubernetesTestStore(t)
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	out, err := LaunchWebsocketService.Run(context.Background(), store, tx, deployment, LaunchWebsocketServiceInput{
+		Name:          "websocket",
+		Namespace:     "default",
+		ServiceLabels: map[string]string{"app": "websocket"},
+		Ports:         map[string]int32{"websocket": 9002},
+	})
+	require.NoError(t, err)
+	require.NoError(t, out.(LaunchWebsocketServiceOutput).Error)
+
+	list, err := api.CoreV1().Services("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+
+	// Run the rollback handler
+	_, err = LaunchWebsocketService.RollbackHandler(context.Background(), store, tx, deployment, nil, nil)
+	require.NoError(t, err)
+
+	list, err = api.CoreV1().Services("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
@@ -5,12 +5,15 @@ import (
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // RemoveConfigurationsInput is the input for the RemoveConfigurations job.
 type RemoveConfigurationsInput struct {
+	// Resource is the configuration to remove.
 	Resource resource.Resource
+	// Resources contains additional configurations to remove. It allows removing multiple configurations in a single
+	// job.
+	Resources []resource.Resource
 }
 
 // RemoveConfigurationsOutput is the output of the RemoveConfigurations job.
@@ -25,12 +28,20 @@ var RemoveConfigurations = &actions.Job{
 }
 
 // removeConfigurations is used by the RemoveConfigurations job as the execute function.
-func removeConfigurations(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func removeConfigurations(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	input := value.(RemoveConfigurationsInput)
 
-	_, err := s.Platform().Orchestrator().Configurations().Delete(context.TODO(), input.Resource)
+	var err error
+	for _, res := range append([]resource.Resource{input.Resource}, input.Resources...) {
+		if res == nil {
+			continue
+		}
+		if _, deleteErr := s.Platform().Orchestrator().Configurations().Delete(ctx, res); deleteErr != nil {
+			err = deleteErr
+		}
+	}
 
 	return RemoveConfigurationsOutput{
 		Error: err,
@@ -5,7 +5,6 @@ import (
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/ingresses"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 	"time"
 )
 
@@ -29,12 +28,12 @@ var RemoveIngressRules = &actions.Job{
 }
 
 // configureIngress is used by the ConfigureIngress job as the execute function.
-func removeIngressRules(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func removeIngressRules(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	input := value.(RemoveIngressRulesInput)
 
-	res, err := s.Platform().Orchestrator().Ingresses().Get(context.TODO(), input.Name, input.Namespace)
+	res, err := s.Platform().Orchestrator().Ingresses().Get(ctx, input.Name, input.Namespace)
 	if err != nil {
 		return RemoveIngressRulesOutput{
 			Error: err,
@@ -50,12 +49,12 @@ func removeIngressRules(store actions.Store, tx *gorm.DB, deployment *actions.De
 		freq *= 2
 
 		var rule ingresses.Rule
-		rule, err = s.Platform().Orchestrator().IngressRules().Get(context.TODO(), res, input.Host)
+		rule, err = s.Platform().Orchestrator().IngressRules().Get(ctx, res, input.Host)
 		if err != nil {
 			continue
 		}
 
-		err = s.Platform().Orchestrator().IngressRules().Remove(context.TODO(), rule, input.Paths...)
+		err = s.Platform().Orchestrator().IngressRules().Remove(ctx, rule, input.Paths...)
 		if err != nil {
 			continue
 		}
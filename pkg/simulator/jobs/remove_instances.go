@@ -1,10 +1,10 @@
 package jobs
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // RemoveInstancesInput is the input of the RemoveInstances job.
@@ -16,7 +16,7 @@ var RemoveInstances = &actions.Job{
 	Execute: removeInstances,
 }
 
-func removeInstances(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func removeInstances(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	// Get the store
 	s := store.State().(state.PlatformGetter)
 
@@ -5,7 +5,6 @@ import (
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // RemoveNetworkPoliciesInput is the input for the RemoveNetworkPolicies job.
@@ -26,12 +25,12 @@ var RemoveNetworkPolicies = &actions.Job{
 }
 
 // removeNetworkPolicies is used by the RemoveNetworkPolicies job as the execute function.
-func removeNetworkPolicies(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func removeNetworkPolicies(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	input := value.(RemoveNetworkPoliciesInput)
 
-	err := s.Platform().Orchestrator().NetworkPolicies().RemoveBulk(context.TODO(), input.Namespace, input.Selector)
+	err := s.Platform().Orchestrator().NetworkPolicies().RemoveBulk(ctx, input.Namespace, input.Selector)
 
 	return RemoveNetworkPoliciesOutput{
 		Error: err,
@@ -6,7 +6,6 @@ import (
 	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // RemovePodsInput is the input of the RemovePods job.
@@ -25,7 +24,7 @@ var RemovePods = &actions.Job{
 }
 
 // removePods is the main function executed by the RemovePods job.
-func removePods(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func removePods(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	// Parse input
@@ -46,7 +45,7 @@ func removePods(store actions.Store, tx *gorm.DB, deployment *actions.Deployment
 
 	for _, in := range input {
 		var res resource.Resource
-		res, err = s.Platform().Orchestrator().Pods().Delete(context.Background(), in)
+		res, err = s.Platform().Orchestrator().Pods().Delete(ctx, in)
 		if err != nil {
 			return nil, err
 		}
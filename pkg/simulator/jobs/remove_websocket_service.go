@@ -4,7 +4,6 @@ import (
 	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // RemoveWebsocketServiceInput is the input of the RemoveWebsocketService job.
@@ -25,14 +24,14 @@ var RemoveWebsocketService = &actions.Job{
 }
 
 // removeWebsocketService is the main function executed by the RemoveWebsocketService job.
-func removeWebsocketService(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func removeWebsocketService(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	s := store.State().(state.PlatformGetter)
 
 	// Parse input
 	input := value.(RemoveWebsocketServiceInput)
 
 	// Get the service
-	res, err := s.Platform().Orchestrator().Services().Get(context.TODO(), input.Name, input.Namespace)
+	res, err := s.Platform().Orchestrator().Services().Get(ctx, input.Name, input.Namespace)
 	if err != nil {
 		return RemoveWebsocketServiceOutput{
 			Error: err,
@@ -40,7 +39,7 @@ func removeWebsocketService(store actions.Store, tx *gorm.DB, deployment *action
 	}
 
 	// Remove the service
-	err = s.Platform().Orchestrator().Services().Remove(context.TODO(), res)
+	err = s.Platform().Orchestrator().Services().Remove(ctx, res)
 
 	return RemoveWebsocketServiceOutput{
 		Error: err,
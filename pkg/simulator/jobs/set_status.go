@@ -1,10 +1,10 @@
 package jobs
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // SetSimulationStatusInput is the input for SetSimulationStatus job.
@@ -24,12 +24,15 @@ type SetSimulationStatusOutput struct {
 }
 
 // SetSimulationStatus is used to set a certain status to a simulation.
+// Status changes are validated by the simulation service. Services created with application.NewServices return an
+// error wrapping simulations.ErrInvalidTransition if the simulation cannot change its current status to the given
+// status.
 var SetSimulationStatus = &actions.Job{
 	Execute: setSimulationStatus,
 }
 
 // setSimulationStatus is the execute function of the SetSimulationStatus job.
-func setSimulationStatus(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func setSimulationStatus(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	input := value.(SetSimulationStatusInput)
 
 	s := store.State().(state.ServicesGetter)
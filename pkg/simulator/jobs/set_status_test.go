@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/application"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// servicesState is an actions store state that implements state.ServicesGetter.
+type servicesState struct {
+	services application.Services
+}
+
+func (s *servicesState) Services() application.Services {
+	return s.services
+}
+
+func TestSetSimulationStatus(t *testing.T) {
+	sim := fake.NewSimulation("test-group-id", simulations.StatusPending, simulations.SimSingle, nil, "test",
+		time.Minute, nil, nil)
+
+	service := fake.NewService()
+	service.On("Get", simulations.GroupID("test-group-id")).Return(sim, nil)
+	service.On("UpdateStatus", simulations.GroupID("test-group-id"), mock.AnythingOfType("simulations.Status")).
+		Run(func(args mock.Arguments) {
+			sim.SetStatus(args.Get(1).(simulations.Status))
+		}).
+		Return(nil)
+
+	history := simulations.NewMemoryTransitionHistory()
+	s := actions.NewStore(&servicesState{services: application.NewServicesWithHistory(service, nil, history)})
+	deployment := &actions.Deployment{CurrentJob: "test"}
+
+	input := SetSimulationStatusInput{
+		GroupID: "test-group-id",
+		Status:  simulations.StatusLaunchingInstances,
+	}
+	result, err := SetSimulationStatus.Run(context.Background(), s, nil, deployment, input)
+	require.NoError(t, err)
+	assert.Equal(t, SetSimulationStatusOutput(input), result)
+	assert.Equal(t, simulations.StatusLaunchingInstances, sim.GetStatus())
+
+	// Status changes are recorded in the history
+	transitions, err := history.List("test-group-id")
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, simulations.StatusPending, transitions[0].From)
+	assert.Equal(t, simulations.StatusLaunchingInstances, transitions[0].To)
+
+	// Invalid status changes are rejected
+	input.Status = simulations.StatusRestarted
+	_, err = SetSimulationStatus.Run(context.Background(), s, nil, deployment, input)
+	assert.True(t, errors.Is(err, simulations.ErrInvalidTransition))
+	assert.Equal(t, simulations.StatusLaunchingInstances, sim.GetStatus())
+	service.AssertNumberOfCalls(t, "UpdateStatus", 1)
+}
@@ -1,10 +1,10 @@
 package jobs
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
 	"github.com/gazebo-web/cloudsim/v4/pkg/waiter"
-	"github.com/jinzhu/gorm"
 	"time"
 )
 
@@ -34,7 +34,7 @@ var Wait = &actions.Job{
 //	Waiting for nodes to be registered in the cluster
 //	Waiting for pods to have an ip assigned.
 //	Waiting for pods to be on the "Ready" state.
-func wait(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, value interface{}) (interface{}, error) {
+func wait(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment, value interface{}) (interface{}, error) {
 	// If value is nil, bypass the job.
 	if value == nil {
 		return WaitOutput{
@@ -47,7 +47,7 @@ func wait(store actions.Store, tx *gorm.DB, deployment *actions.Deployment, valu
 		return nil, simulator.ErrInvalidInput
 	}
 
-	err := input.Request.Wait(input.Timeout, input.PollFrequency)
+	err := input.Request.WaitContext(ctx, input.Timeout, input.PollFrequency)
 	return WaitOutput{
 		Error: err,
 	}, nil
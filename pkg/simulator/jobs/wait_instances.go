@@ -1,10 +1,10 @@
 package jobs
 
 import (
+	"context"
 	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
 	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
 	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/state"
-	"github.com/jinzhu/gorm"
 )
 
 // WaitForInstancesInput is the input of the WaitForInstances job.
@@ -20,7 +20,7 @@ var WaitForInstances = &actions.Job{
 }
 
 // waitForInstances is the main process executed by WaitForInstances.
-func waitForInstances(store actions.Store, tx *gorm.DB, deployment *actions.Deployment,
+func waitForInstances(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
 	value interface{}) (interface{}, error) {
 
 	// Parse input data
@@ -36,7 +36,7 @@ func waitForInstances(store actions.Store, tx *gorm.DB, deployment *actions.Depl
 	s := store.State().(state.PlatformGetter)
 
 	// Wait until machines are OK.
-	err := s.Platform().Machines().WaitOK(waitMachinesOkInputs)
+	err := s.Platform().Machines().WaitOK(ctx, waitMachinesOkInputs)
 	if err != nil {
 		return nil, err
 	}
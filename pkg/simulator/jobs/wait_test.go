@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/waiter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWait_StopsWhenContextIsDone(t *testing.T) {
+	request := waiter.NewWaitRequest(func() (bool, error) {
+		return false, nil
+	})
+
+	tx := actions.NewMemoryDeploymentStore()
+	deployment := &actions.Deployment{UUID: "test", CurrentJob: "test"}
+	require.NoError(t, tx.CreateDeployment(deployment))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out, err := Wait.Execute(ctx, nil, tx, deployment, WaitInput{
+		Request:       request,
+		PollFrequency: time.Millisecond,
+		Timeout:       time.Minute,
+	})
+	require.NoError(t, err)
+	assert.True(t, errors.Is(out.(WaitOutput).Error, context.Canceled))
+}
@@ -0,0 +1,304 @@
+package multisim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
+	"github.com/gazebo-web/gz-go/v7"
+	"gopkg.in/go-playground/validator.v9"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoChildren is returned when a parent simulation is created without children.
+	ErrNoChildren = errors.New("parent simulation has no children")
+	// ErrTooManyChildrenFailed is returned when the number of failed children of a parent simulation exceeds the
+	// coordinator threshold.
+	ErrTooManyChildrenFailed = errors.New("too many child simulations failed")
+)
+
+// ChildrenFactory returns the requests used to create the children of a parent simulation.
+type ChildrenFactory interface {
+	// Children returns the list of requests used to create each child of the given parent request.
+	Children(parent simulations.CreateSimulationInput) ([]simulations.CreateSimulationInput, error)
+}
+
+// replicateChildren is a ChildrenFactory that creates a number of copies of the parent request.
+type replicateChildren int
+
+// Children returns a copy of the parent request for each child. Children are named `<parent>-<index>`.
+func (n replicateChildren) Children(parent simulations.CreateSimulationInput) ([]simulations.CreateSimulationInput, error) {
+	children := make([]simulations.CreateSimulationInput, n)
+	for i := range children {
+		children[i] = parent
+		children[i].Name = fmt.Sprintf("%s-%d", parent.Name, i+1)
+	}
+
+	return children, nil
+}
+
+// ReplicateChildren returns a ChildrenFactory that runs the parent request `n` times.
+func ReplicateChildren(n int) ChildrenFactory {
+	return replicateChildren(n)
+}
+
+// SummaryAggregator generates the summary of a parent simulation once all of its children have finished.
+// Implementations usually run the jobs.AggregateSummaries job.
+type SummaryAggregator interface {
+	// Aggregate generates the summary of a parent simulation from the given children.
+	Aggregate(ctx context.Context, parent simulations.GroupID, children []simulations.Simulation) error
+}
+
+// Config contains the configuration of a Coordinator.
+type Config struct {
+	// Platform is the platform children are launched in.
+	Platform platform.Platform `validate:"required"`
+	// Services is used to create simulations and update their statuses.
+	Services simulations.Service `validate:"required"`
+	// Simulator is used to launch children.
+	Simulator simulator.Simulator `validate:"required"`
+	// Children is used to create the children of parent simulations.
+	Children ChildrenFactory `validate:"required"`
+	// Aggregator is used to generate the summary of a parent simulation once all of its children finished.
+	// If nil, summaries are not generated.
+	Aggregator SummaryAggregator
+	// Concurrency is the maximum number of children that are launched at the same time.
+	Concurrency int `validate:"min=1"`
+	// MaxFailures is the maximum number of children that can fail without marking the parent as failed.
+	MaxFailures int `validate:"min=0"`
+	// PollInterval is the time between checks of the status of children.
+	PollInterval time.Duration `validate:"gt=0"`
+	// Logger is used to log children errors.
+	Logger gz.Logger `validate:"required"`
+}
+
+// Coordinator runs parent simulations as a set of child simulations.
+type Coordinator interface {
+	// Create creates a parent simulation and its children from a parent request.
+	// If a child fails to be created, the parent and the children created so far are returned with the error.
+	Create(input simulations.CreateSimulationInput) (simulations.Simulation, []simulations.Simulation, error)
+	// Launch launches the given children, limiting the number of children launched at the same time.
+	// Children that fail to launch are marked as failed.
+	Launch(ctx context.Context, children []simulations.GroupID) error
+	// Status returns the status of a parent simulation based on the status of its children.
+	// See ParentStatus for details.
+	Status(children []simulations.GroupID) (simulations.Status, error)
+	// Run launches the children of a parent simulation and tracks them until all of them finish. Once finished, the
+	// parent summary is generated and the parent status is updated.
+	// Returns ErrTooManyChildrenFailed if the parent is marked as failed.
+	Run(ctx context.Context, parent simulations.GroupID, children []simulations.GroupID) error
+}
+
+// coordinator is a Coordinator implementation.
+type coordinator struct {
+	Config
+}
+
+// NewCoordinator initializes a new Coordinator.
+func NewCoordinator(config Config) (Coordinator, error) {
+	if err := validator.New().Struct(config); err != nil {
+		return nil, err
+	}
+
+	return &coordinator{
+		Config: config,
+	}, nil
+}
+
+// Create creates a parent simulation and its children from a parent request.
+func (c *coordinator) Create(input simulations.CreateSimulationInput) (simulations.Simulation,
+	[]simulations.Simulation, error) {
+
+	inputs, err := c.Children.Children(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(inputs) == 0 {
+		return nil, nil, ErrNoChildren
+	}
+
+	input.Kind = simulations.SimParent
+	input.Parent = nil
+	parent, err := c.Services.Create(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	groupID := parent.GetGroupID()
+
+	children := make([]simulations.Simulation, 0, len(inputs))
+	for _, in := range inputs {
+		in.Kind = simulations.SimChild
+		in.Parent = &groupID
+
+		child, err := c.Services.Create(in)
+		if err != nil {
+			return parent, children, err
+		}
+		children = append(children, child)
+	}
+
+	return parent, children, nil
+}
+
+// Launch launches the given children, limiting the number of children launched at the same time.
+func (c *coordinator) Launch(ctx context.Context, children []simulations.GroupID) error {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var updateErr error
+
+	sem := make(chan struct{}, c.Concurrency)
+	for _, child := range children {
+		// Wait for a launch slot, stopping if the context is cancelled
+		if ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case sem <- struct{}{}:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			wg.Wait()
+			return err
+		}
+
+		wg.Add(1)
+		go func(child simulations.GroupID) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := c.Simulator.Start(ctx, c.Platform, child)
+			if err == nil {
+				return
+			}
+			c.Logger.Warning(fmt.Sprintf("Failed to launch child simulation [%s]. Error: %s", child, err))
+
+			if err := c.Services.UpdateStatus(child, simulations.StatusFailed); err != nil {
+				lock.Lock()
+				updateErr = err
+				lock.Unlock()
+			}
+		}(child)
+	}
+	wg.Wait()
+
+	return updateErr
+}
+
+// Status returns the status of a parent simulation based on the status of its children.
+func (c *coordinator) Status(children []simulations.GroupID) (simulations.Status, error) {
+	sims, err := c.getChildren(children)
+	if err != nil {
+		return simulations.StatusUnknown, err
+	}
+
+	return ParentStatus(sims, c.MaxFailures), nil
+}
+
+// Run launches the children of a parent simulation and tracks them until all of them finish.
+func (c *coordinator) Run(ctx context.Context, parent simulations.GroupID, children []simulations.GroupID) error {
+	if err := c.Services.UpdateStatus(parent, simulations.StatusRunning); err != nil {
+		return err
+	}
+
+	if err := c.Launch(ctx, children); err != nil {
+		return err
+	}
+
+	// Wait for all children to finish
+	var sims []simulations.Simulation
+	var status simulations.Status
+	for {
+		var err error
+		sims, err = c.getChildren(children)
+		if err != nil {
+			return err
+		}
+
+		status = ParentStatus(sims, c.MaxFailures)
+		if status != simulations.StatusRunning {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.PollInterval):
+		}
+	}
+
+	var aggregateErr error
+	if c.Aggregator != nil {
+		aggregateErr = c.Aggregator.Aggregate(ctx, parent, sims)
+	}
+
+	if err := c.Services.UpdateStatus(parent, status); err != nil {
+		return err
+	}
+
+	if aggregateErr != nil {
+		return aggregateErr
+	}
+
+	if status == simulations.StatusFailed {
+		return ErrTooManyChildrenFailed
+	}
+
+	return nil
+}
+
+// getChildren returns the simulations for the given children group ids.
+func (c *coordinator) getChildren(children []simulations.GroupID) ([]simulations.Simulation, error) {
+	sims := make([]simulations.Simulation, len(children))
+	for i, child := range children {
+		sim, err := c.Services.Get(child)
+		if err != nil {
+			return nil, err
+		}
+		sims[i] = sim
+	}
+
+	return sims, nil
+}
+
+// ParentStatus returns the status of a parent simulation based on the status of its children.
+// The parent is running until all of its children reach a terminal status. Once all children finish, the parent is
+// terminated, unless more than `maxFailures` children failed, in which case the parent failed.
+// Children fail if they have an error, or if they were rejected or failed to launch.
+func ParentStatus(children []simulations.Simulation, maxFailures int) simulations.Status {
+	var failed int
+	for _, child := range children {
+		if !isTerminal(child) {
+			return simulations.StatusRunning
+		}
+		if isFailed(child) {
+			failed++
+		}
+	}
+
+	if failed > maxFailures {
+		return simulations.StatusFailed
+	}
+
+	return simulations.StatusTerminated
+}
+
+// isTerminal returns true if a child simulation finished.
+func isTerminal(sim simulations.Simulation) bool {
+	switch sim.GetStatus() {
+	case simulations.StatusTerminated, simulations.StatusRejected, simulations.StatusSuperseded,
+		simulations.StatusFailed:
+		return true
+	}
+
+	return false
+}
+
+// isFailed returns true if a child simulation failed.
+func isFailed(sim simulations.Simulation) bool {
+	return sim.GetError() != nil || sim.HasStatus(simulations.StatusRejected) || sim.HasStatus(simulations.StatusFailed)
+}
@@ -0,0 +1,266 @@
+package multisim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations/fake"
+	simfake "github.com/gazebo-web/cloudsim/v4/pkg/simulator/fake"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestServices returns a fake simulations.Service that creates the simulations of a multisim with 5 children.
+// The parent simulation is "sim-1", and the children are "sim-2" to "sim-6". Status updates are applied to the
+// returned simulations.
+func newTestServices() (*fake.Service, map[simulations.GroupID]simulations.Simulation) {
+	services := fake.NewService()
+	sims := make(map[simulations.GroupID]simulations.Simulation)
+
+	for i := 1; i <= 6; i++ {
+		groupID := simulations.GroupID(fmt.Sprintf("sim-%d", i))
+		name, kind := "multisim", simulations.SimParent
+		if i > 1 {
+			name, kind = fmt.Sprintf("multisim-%d", i-1), simulations.SimChild
+		}
+		sims[groupID] = fake.NewSimulation(groupID, simulations.StatusPending, kind, nil, "", time.Hour, nil, nil)
+
+		services.On("Create", mock.MatchedBy(func(input simulations.CreateSimulationInput) bool {
+			return input.Name == name
+		})).Return(sims[groupID], nil)
+		services.On("Get", groupID).Return(sims[groupID], nil)
+	}
+
+	var lock sync.Mutex
+	services.On("UpdateStatus", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		sims[args.Get(0).(simulations.GroupID)].SetStatus(args.Get(1).(simulations.Status))
+	}).Return(nil)
+
+	return services, sims
+}
+
+// createInputs returns the inputs used to create simulations through a fake simulations.Service, indexed by name.
+func createInputs(services *fake.Service) map[string]simulations.CreateSimulationInput {
+	out := make(map[string]simulations.CreateSimulationInput)
+	for _, call := range services.Calls {
+		if call.Method == "Create" {
+			input := call.Arguments.Get(0).(simulations.CreateSimulationInput)
+			out[input.Name] = input
+		}
+	}
+	return out
+}
+
+// statusCalls returns the statuses set for a simulation through a fake simulations.Service.
+func statusCalls(services *fake.Service, groupID simulations.GroupID) []simulations.Status {
+	var out []simulations.Status
+	for _, call := range services.Calls {
+		if call.Method == "UpdateStatus" && call.Arguments.Get(0) == groupID {
+			out = append(out, call.Arguments.Get(1).(simulations.Status))
+		}
+	}
+	return out
+}
+
+// testConcurrency keeps track of the maximum number of simulations started concurrently.
+type testConcurrency struct {
+	lock    sync.Mutex
+	running int
+	max     int
+}
+
+// newTestSimulator returns a fake simulator.Simulator that terminates simulations when they are started.
+// Starting the simulations in `fail` fails.
+func newTestSimulator(sims map[simulations.GroupID]simulations.Simulation, concurrency *testConcurrency,
+	fail []simulations.GroupID) *simfake.Simulator {
+
+	simulator := simfake.NewSimulator()
+	failed := make(map[simulations.GroupID]bool)
+	for _, groupID := range fail {
+		failed[groupID] = true
+	}
+
+	start := func(args mock.Arguments) {
+		concurrency.lock.Lock()
+		concurrency.running++
+		if concurrency.running > concurrency.max {
+			concurrency.max = concurrency.running
+		}
+		concurrency.lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		concurrency.lock.Lock()
+		concurrency.running--
+		concurrency.lock.Unlock()
+	}
+	for groupID, sim := range sims {
+		if failed[groupID] {
+			simulator.On("Start", mock.Anything, mock.Anything, groupID).Run(start).
+				Return(errors.New("start failed"))
+			continue
+		}
+		sim := sim
+		simulator.On("Start", mock.Anything, mock.Anything, groupID).Run(func(args mock.Arguments) {
+			start(args)
+			sim.SetStatus(simulations.StatusTerminated)
+		}).Return(nil)
+	}
+
+	return simulator
+}
+
+// testAggregator is a SummaryAggregator implementation that records the children it received.
+type testAggregator struct {
+	parent   simulations.GroupID
+	children []simulations.Simulation
+}
+
+func (a *testAggregator) Aggregate(ctx context.Context, parent simulations.GroupID,
+	children []simulations.Simulation) error {
+
+	a.parent = parent
+	a.children = children
+	return nil
+}
+
+func newTestCoordinator(t *testing.T, maxFailures int, fail ...simulations.GroupID) (Coordinator, *fake.Service,
+	map[simulations.GroupID]simulations.Simulation, *testConcurrency, *testAggregator) {
+
+	services, sims := newTestServices()
+	concurrency := &testConcurrency{}
+	aggregator := &testAggregator{}
+
+	p, err := platform.NewPlatform("test", platform.Components{})
+	require.NoError(t, err)
+
+	c, err := NewCoordinator(Config{
+		Platform:     p,
+		Services:     services,
+		Simulator:    newTestSimulator(sims, concurrency, fail),
+		Children:     ReplicateChildren(5),
+		Aggregator:   aggregator,
+		Concurrency:  2,
+		MaxFailures:  maxFailures,
+		PollInterval: time.Millisecond,
+		Logger:       gz.NewLoggerNoRollbar("TestCoordinator", gz.VerbosityWarning),
+	})
+	require.NoError(t, err)
+
+	return c, services, sims, concurrency, aggregator
+}
+
+// groupIDs returns the group ids of a list of simulations.
+func groupIDs(sims []simulations.Simulation) []simulations.GroupID {
+	out := make([]simulations.GroupID, len(sims))
+	for i, sim := range sims {
+		out[i] = sim.GetGroupID()
+	}
+	return out
+}
+
+func TestNewCoordinator_InvalidConfig(t *testing.T) {
+	_, err := NewCoordinator(Config{Concurrency: 1, PollInterval: time.Second})
+	assert.Error(t, err)
+}
+
+func TestCoordinator_Create(t *testing.T) {
+	c, services, _, _, _ := newTestCoordinator(t, 0)
+
+	parent, children, err := c.Create(simulations.CreateSimulationInput{Name: "multisim"})
+	require.NoError(t, err)
+	require.Len(t, children, 5)
+
+	inputs := createInputs(services)
+	assert.Equal(t, simulations.SimParent, inputs["multisim"].Kind)
+	for i, child := range children {
+		name := fmt.Sprintf("multisim-%d", i+1)
+		assert.Equal(t, simulations.GroupID(fmt.Sprintf("sim-%d", i+2)), child.GetGroupID())
+		input := inputs[name]
+		assert.Equal(t, simulations.SimChild, input.Kind)
+		require.NotNil(t, input.Parent)
+		assert.Equal(t, parent.GetGroupID(), *input.Parent)
+	}
+}
+
+func TestCoordinator_Run(t *testing.T) {
+	c, services, sims, concurrency, aggregator := newTestCoordinator(t, 1, "sim-3")
+
+	parent, children, err := c.Create(simulations.CreateSimulationInput{Name: "multisim"})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Run(context.Background(), parent.GetGroupID(), groupIDs(children)))
+
+	// Children are launched concurrently without exceeding the limit
+	assert.Equal(t, 2, concurrency.max)
+
+	// Children that failed to launch are marked as failed
+	assert.Equal(t, simulations.StatusFailed, sims["sim-3"].GetStatus())
+
+	// The parent is running until all children finish
+	assert.Equal(t, []simulations.Status{simulations.StatusRunning, simulations.StatusTerminated},
+		statusCalls(services, parent.GetGroupID()))
+
+	// The summary is generated once all children finished
+	assert.Equal(t, parent.GetGroupID(), aggregator.parent)
+	assert.Len(t, aggregator.children, 5)
+}
+
+func TestCoordinator_RunTooManyFailures(t *testing.T) {
+	c, _, sims, _, _ := newTestCoordinator(t, 1, "sim-2", "sim-4")
+
+	parent, children, err := c.Create(simulations.CreateSimulationInput{Name: "multisim"})
+	require.NoError(t, err)
+
+	err = c.Run(context.Background(), parent.GetGroupID(), groupIDs(children))
+	assert.Equal(t, ErrTooManyChildrenFailed, err)
+	assert.Equal(t, simulations.StatusFailed, sims[parent.GetGroupID()].GetStatus())
+}
+
+func TestCoordinator_RunCancelled(t *testing.T) {
+	c, _, _, _, aggregator := newTestCoordinator(t, 0)
+
+	parent, children, err := c.Create(simulations.CreateSimulationInput{Name: "multisim"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = c.Run(ctx, parent.GetGroupID(), groupIDs(children))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Empty(t, aggregator.children)
+}
+
+func TestParentStatus(t *testing.T) {
+	simErr := simulations.Error("error")
+	newSim := func(status simulations.Status, err *simulations.Error) simulations.Simulation {
+		return fake.NewSimulation("child", status, simulations.SimChild, err, "", time.Hour, nil, nil)
+	}
+
+	// Parents are running until all children finish
+	children := []simulations.Simulation{
+		newSim(simulations.StatusTerminated, nil),
+		newSim(simulations.StatusRunning, nil),
+	}
+	assert.Equal(t, simulations.StatusRunning, ParentStatus(children, 0))
+
+	// Children with errors, rejected and failed children count as failures
+	children = []simulations.Simulation{
+		newSim(simulations.StatusTerminated, nil),
+		newSim(simulations.StatusTerminated, &simErr),
+		newSim(simulations.StatusRejected, nil),
+		newSim(simulations.StatusFailed, nil),
+	}
+	assert.Equal(t, simulations.StatusTerminated, ParentStatus(children, 3))
+	assert.Equal(t, simulations.StatusFailed, ParentStatus(children, 2))
+}
@@ -0,0 +1,214 @@
+// Package reference contains a reference simulator.Simulator implementation composed from the generic jobs in the
+// jobs package.
+//
+// The reference simulator launches a simulation by creating a set of machines and running a set of pods on them,
+// and stops it by removing everything it created. Applications describe the resources of each simulation with a Spec,
+// and can customize the start and stop actions with Hooks.
+package reference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/application"
+	"github.com/gazebo-web/cloudsim/v4/pkg/billing"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/configurations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/ingresses"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/network"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/services"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const (
+	// ActionStart is the name of the action used to start simulations.
+	ActionStart = "start-simulation"
+	// ActionStop is the name of the action used to stop simulations.
+	ActionStop = "stop-simulation"
+
+	// LabelGroupID is the label added to the nodes, pods and network policies of a simulation. Its value is the
+	// simulation group id.
+	LabelGroupID = "cloudsim-group-id"
+)
+
+// Spec describes the resources created for a simulation.
+// Spec functions are called both when starting and stopping a simulation, and must return the same resources for the
+// same simulation.
+//
+// Pods, configurations, network policies and the websocket service are created in the namespace of the platform
+// orchestrator, and the namespaces returned by Spec functions are ignored.
+type Spec struct {
+	// Machines returns the machines to launch for a simulation.
+	Machines func(sim simulations.Simulation) ([]machines.CreateMachinesInput, error) `validate:"required"`
+	// Pods returns the pods to launch for a simulation.
+	Pods func(sim simulations.Simulation) ([]pods.CreatePodInput, error) `validate:"required"`
+	// Configurations returns the configurations to create for a simulation. If nil, no configurations are created.
+	Configurations func(sim simulations.Simulation) ([]configurations.CreateConfigurationInput, error)
+	// NetworkPolicies returns the network policies to create for a simulation. If nil, no network policies are
+	// created.
+	NetworkPolicies func(sim simulations.Simulation) ([]network.CreateNetworkPolicyInput, error)
+	// WebsocketService returns the websocket service to launch for a simulation. If nil, no service is launched.
+	WebsocketService func(sim simulations.Simulation) (*services.CreateServiceInput, error)
+	// IngressPaths returns the paths added to the platform ingress for a simulation. If nil, the ingress is not
+	// configured.
+	IngressPaths func(sim simulations.Simulation) ([]ingresses.Path, error)
+}
+
+// Hooks contains jobs that are run at specific points of the start and stop actions.
+// Hook jobs receive and return a *State value, and are usually created with NewHook.
+type Hooks struct {
+	// BeforeStart is run after checking that the simulation can be started and before launching any resources.
+	BeforeStart actions.Jobs
+	// AfterStart is run after the simulation is running.
+	AfterStart actions.Jobs
+	// BeforeStop is run after checking that the simulation can be stopped and before removing any resources.
+	BeforeStop actions.Jobs
+	// AfterStop is run after the simulation is terminated.
+	AfterStop actions.Jobs
+}
+
+// Config contains the configuration of the reference simulator.
+type Config struct {
+	// ApplicationName is the name of the application the start and stop actions are registered for.
+	ApplicationName string `validate:"required"`
+	// Services contains the application services.
+	Services application.Services `validate:"required"`
+	// ActionService is used to register and execute the start and stop actions.
+	ActionService actions.Servicer `validate:"required"`
+	// DeploymentStore is used to persist the deployments of the start and stop actions.
+	DeploymentStore actions.DeploymentStore `validate:"required"`
+	// Spec describes the resources created for each simulation.
+	Spec Spec
+	// Hooks contains optional jobs run at specific points of the start and stop actions.
+	Hooks Hooks
+	// Charger is used to charge simulations for the time they run. If nil, simulations are not charged.
+	// Simulations get a rate after their instances are launched, and are charged after they are terminated.
+	Charger billing.Charger
+}
+
+// referenceSimulator is a simulator.Simulator implementation composed from the generic jobs.
+type referenceSimulator struct {
+	Config
+}
+
+// NewSimulator initializes a new reference simulator, and registers its start and stop actions in the action service.
+func NewSimulator(config Config) (simulator.Simulator, error) {
+	if err := validator.New().Struct(config); err != nil {
+		return nil, err
+	}
+
+	start, err := actions.NewAction(startJobs(config.Spec, config.Hooks, config.Charger))
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ActionService.RegisterAction(&config.ApplicationName, ActionStart, start); err != nil {
+		return nil, err
+	}
+
+	stop, err := actions.NewAction(stopJobs(config.Spec, config.Hooks, config.Charger))
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ActionService.RegisterAction(&config.ApplicationName, ActionStop, stop); err != nil {
+		return nil, err
+	}
+
+	return &referenceSimulator{
+		Config: config,
+	}, nil
+}
+
+// Start runs the start action for the given simulation.
+func (s *referenceSimulator) Start(ctx context.Context, platform platform.Platform, groupID simulations.GroupID) error {
+	return s.execute(ctx, platform, ActionStart, groupID)
+}
+
+// Stop runs the stop action for the given simulation.
+func (s *referenceSimulator) Stop(ctx context.Context, platform platform.Platform, groupID simulations.GroupID) error {
+	return s.execute(ctx, platform, ActionStop, groupID)
+}
+
+// execute runs an action for the given simulation.
+// Every run of an action is executed in a new deployment. Stop actions record the deployment of the last start action
+// of the simulation in their State, to find the instances it launched.
+func (s *referenceSimulator) execute(ctx context.Context, platform platform.Platform, action string,
+	groupID simulations.GroupID) error {
+
+	attempt, err := lastAttempt(s.DeploymentStore, action, groupID)
+	if err != nil {
+		return err
+	}
+
+	state := NewState(platform, s.Services, groupID)
+	if action == ActionStop {
+		start, err := lastAttempt(s.DeploymentStore, ActionStart, groupID)
+		if err != nil {
+			return err
+		}
+		if start >= 0 {
+			state.StartDeploymentID = DeploymentID(ActionStart, groupID, start)
+		}
+	}
+
+	input := &actions.ExecuteInput{
+		GroupID:         DeploymentID(action, groupID, attempt+1),
+		ApplicationName: &s.ApplicationName,
+		ActionName:      action,
+	}
+
+	value := &State{
+		GroupID:           groupID,
+		StartDeploymentID: state.StartDeploymentID,
+	}
+
+	return s.ActionService.Execute(ctx, actions.NewStore(state), s.DeploymentStore, input, value)
+}
+
+// DeploymentID returns the UUID of the deployment that runs an action for a simulation.
+// Actions can run multiple times for the same simulation, e.g. when a simulation is restarted. `attempt` is the
+// zero-based number of the run.
+func DeploymentID(action string, groupID simulations.GroupID, attempt int) string {
+	if attempt == 0 {
+		return fmt.Sprintf("%s-%s", action, groupID)
+	}
+	return fmt.Sprintf("%s-%s-%d", action, groupID, attempt)
+}
+
+// lastAttempt returns the attempt number of the last deployment that ran an action for a simulation.
+// It returns -1 if the action never ran for the simulation.
+func lastAttempt(tx actions.DeploymentStore, action string, groupID simulations.GroupID) (int, error) {
+	attempt := -1
+	for {
+		_, err := tx.GetDeployment(DeploymentID(action, groupID, attempt+1))
+		if errors.Is(err, actions.ErrDeploymentNotFound) {
+			return attempt, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		attempt++
+	}
+}
+
+// groupIDLabels returns the labels used to identify the resources of a simulation.
+func groupIDLabels(groupID simulations.GroupID) map[string]string {
+	return map[string]string{
+		LabelGroupID: groupID.String(),
+	}
+}
+
+// addGroupIDLabel returns a copy of a set of labels that includes the group id label.
+func addGroupIDLabel(labels map[string]string, groupID simulations.GroupID) map[string]string {
+	out := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[LabelGroupID] = groupID.String()
+
+	return out
+}
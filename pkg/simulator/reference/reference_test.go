@@ -0,0 +1,470 @@
+package reference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/application"
+	"github.com/gazebo-web/cloudsim/v4/pkg/billing"
+	"github.com/gazebo-web/cloudsim/v4/pkg/calculator"
+	cloudFake "github.com/gazebo-web/cloudsim/v4/pkg/cloud/fake"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/configurations"
+	kubernetesConfigMaps "github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/configurations/implementations/kubernetes"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/nodes"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods"
+	kubernetesPods "github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/pods/implementations/kubernetes"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/components/spdy"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/implementations/kubernetes"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations/fake"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
+	fakeStore "github.com/gazebo-web/cloudsim/v4/pkg/store/implementations/fake"
+	"github.com/gazebo-web/cloudsim/v4/pkg/waiter"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetesFake "k8s.io/client-go/kubernetes/fake"
+	"testing"
+	"time"
+)
+
+const testNamespace = "default"
+
+// testRate is the rate of the machines launched by the tests.
+var testRate = calculator.Rate{Amount: 10, Currency: "usd", Frequency: time.Hour}
+
+// testNodes is a nodes.Nodes implementation where nodes are always ready.
+type testNodes struct {
+	nodes.Nodes
+	selectors []resource.Selector
+}
+
+func (n *testNodes) WaitForCondition(ctx context.Context, node resource.Resource,
+	condition resource.Condition) waiter.Waiter {
+
+	n.selectors = append(n.selectors, node.Selector())
+	return waiter.NewWaitRequest(func() (bool, error) {
+		return true, nil
+	})
+}
+
+// testPods is a pods.Pods implementation where pods are ready as soon as they are created.
+type testPods struct {
+	pods.Pods
+}
+
+func (p *testPods) WaitForCondition(ctx context.Context, resource resource.Resource,
+	condition ...resource.Condition) waiter.Waiter {
+
+	return waiter.NewWaitRequest(func() (bool, error) {
+		return true, nil
+	})
+}
+
+type referenceTest struct {
+	simulator   simulator.Simulator
+	deployments actions.DeploymentStore
+	services    *fake.Service
+	machines    *cloudFake.Machines
+	nodes       *testNodes
+	api         *kubernetesFake.Clientset
+	platform    platform.Platform
+	ledger      billing.Ledger
+	// statuses contains the statuses set by the actions, in order.
+	statuses []simulations.Status
+	// created contains the machines requested by the actions.
+	created []machines.CreateMachinesInput
+	// instances is the number of instances returned by the Create calls expected with expectCreate.
+	instances int
+	// terminated contains the instances terminated by the actions.
+	terminated []string
+	hooks      []string
+	// stopErrors contains the removal errors received by the after-stop hook.
+	stopErrors []string
+}
+
+func newReferenceTest(t *testing.T, sims ...simulations.Simulation) *referenceTest {
+	return setupReferenceTest(t, false, sims...)
+}
+
+// newChargedReferenceTest returns a test that charges simulations in test.ledger.
+func newChargedReferenceTest(t *testing.T, sims ...simulations.Simulation) *referenceTest {
+	return setupReferenceTest(t, true, sims...)
+}
+
+func setupReferenceTest(t *testing.T, charged bool, sims ...simulations.Simulation) *referenceTest {
+	logger := gz.NewLoggerNoRollbar("TestReferenceSimulator", gz.VerbosityWarning)
+	api := kubernetesFake.NewSimpleClientset()
+
+	test := &referenceTest{
+		deployments: actions.NewMemoryDeploymentStore(),
+		services:    fake.NewService(),
+		machines:    cloudFake.NewMachines(),
+		nodes:       &testNodes{},
+		api:         api,
+	}
+	for _, sim := range sims {
+		sim := sim
+		test.services.On("Get", sim.GetGroupID()).Return(sim, nil)
+		test.services.On("UpdateStatus", sim.GetGroupID(), mock.AnythingOfType("simulations.Status")).
+			Run(func(args mock.Arguments) {
+				status := args.Get(1).(simulations.Status)
+				sim.SetStatus(status)
+				test.statuses = append(test.statuses, status)
+			}).
+			Return(nil)
+	}
+
+	test.machines.On("WaitOK", mock.Anything, mock.Anything).Return(nil)
+	test.machines.On("Terminate", mock.AnythingOfType("machines.TerminateMachinesInput")).
+		Run(func(args mock.Arguments) {
+			test.terminated = append(test.terminated, args.Get(0).(machines.TerminateMachinesInput).Instances...)
+		}).
+		Return(nil)
+
+	storeOrchestrator := fakeStore.NewFakeOrchestrator()
+	storeOrchestrator.On("Namespace").Return(testNamespace)
+	storeOrchestrator.On("Timeout").Return(time.Second)
+	storeOrchestrator.On("PollFrequency").Return(time.Millisecond)
+
+	cluster := kubernetes.NewCustomKubernetes(kubernetes.Config{
+		Nodes:          test.nodes,
+		Pods:           &testPods{Pods: kubernetesPods.NewPods(api, spdy.NewSPDYFakeInitializer(), logger)},
+		Configurations: kubernetesConfigMaps.NewConfigMaps(api, logger),
+	})
+
+	p, err := platform.NewPlatform("test", platform.Components{
+		Machines: test.machines,
+		Cluster:  cluster,
+		Store:    fakeStore.NewFakeStore(nil, storeOrchestrator, fakeStore.NewFakeIgnition()),
+	})
+	require.NoError(t, err)
+	test.platform = p
+
+	hook := func(name string) *actions.Job {
+		return NewHook(name, func(ctx context.Context, s *State) error {
+			test.hooks = append(test.hooks, name)
+			test.stopErrors = s.Errors
+			return nil
+		})
+	}
+
+	var charger billing.Charger
+	if charged {
+		test.services.On("Update", mock.Anything).Return(nil)
+		test.services.On("MarkStopped", mock.Anything).Return(nil)
+		test.services.On("MarkCharged", mock.Anything).Return(nil)
+		test.machines.On("CalculateCost", mock.Anything).Return(testRate, nil)
+
+		test.ledger = billing.NewMemoryLedger()
+		charger, err = billing.NewCharger(billing.Config{
+			Services: test.services,
+			Ledger:   test.ledger,
+		})
+		require.NoError(t, err)
+	}
+
+	s, err := NewSimulator(Config{
+		ApplicationName: "test",
+		Services:        application.NewServices(test.services, nil),
+		ActionService:   actions.NewService(logger),
+		DeploymentStore: test.deployments,
+		Spec: Spec{
+			Machines: func(sim simulations.Simulation) ([]machines.CreateMachinesInput, error) {
+				return []machines.CreateMachinesInput{{Type: "test"}, {Type: "test"}}, nil
+			},
+			Pods: func(sim simulations.Simulation) ([]pods.CreatePodInput, error) {
+				return []pods.CreatePodInput{
+					{Name: fmt.Sprintf("%s-server", sim.GetGroupID()), Labels: map[string]string{"role": "server"}},
+					{Name: fmt.Sprintf("%s-robot", sim.GetGroupID())},
+				}, nil
+			},
+			Configurations: func(sim simulations.Simulation) ([]configurations.CreateConfigurationInput, error) {
+				return []configurations.CreateConfigurationInput{
+					{Name: fmt.Sprintf("%s-config", sim.GetGroupID()), Data: map[string]string{"key": "value"}},
+				}, nil
+			},
+		},
+		Hooks: Hooks{
+			BeforeStart: actions.Jobs{hook("before-start")},
+			AfterStart:  actions.Jobs{hook("after-start")},
+			BeforeStop:  actions.Jobs{hook("before-stop")},
+			AfterStop:   actions.Jobs{hook("after-stop")},
+		},
+		Charger: charger,
+	})
+	require.NoError(t, err)
+	test.simulator = s
+
+	return test
+}
+
+// expectCreate sets the result of the next call to Machines.Create. If `err` is nil, the call creates an instance for
+// each of the two machines of the test Spec, numbered after the instances created by previous calls.
+func (test *referenceTest) expectCreate(err error) {
+	call := test.machines.On("Create", mock.AnythingOfType("[]machines.CreateMachinesInput")).
+		Run(func(args mock.Arguments) {
+			test.created = append(test.created, args.Get(0).([]machines.CreateMachinesInput)...)
+		}).
+		Once()
+
+	if err != nil {
+		call.Return([]machines.CreateMachinesOutput(nil), err)
+		return
+	}
+
+	out := make([]machines.CreateMachinesOutput, 2)
+	for i := range out {
+		test.instances++
+		out[i] = machines.CreateMachinesOutput{Instances: []string{fmt.Sprintf("instance-%d", test.instances)}}
+	}
+	call.Return(out, nil)
+}
+
+func (test *referenceTest) countPods(t *testing.T) int {
+	list, err := test.api.CoreV1().Pods(testNamespace).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	return len(list.Items)
+}
+
+func (test *referenceTest) countConfigMaps(t *testing.T) int {
+	list, err := test.api.CoreV1().ConfigMaps(testNamespace).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	return len(list.Items)
+}
+
+func newPendingSimulation(groupID simulations.GroupID, kind simulations.Kind) simulations.Simulation {
+	return fake.NewSimulation(groupID, simulations.StatusPending, kind, nil, "", time.Hour, nil, nil)
+}
+
+func TestNewSimulator_InvalidConfig(t *testing.T) {
+	_, err := NewSimulator(Config{})
+	assert.Error(t, err)
+}
+
+func TestReferenceSimulator_StartStop(t *testing.T) {
+	sim := newPendingSimulation("sim", simulations.SimSingle)
+	test := newReferenceTest(t, sim)
+	test.expectCreate(nil)
+	ctx := context.Background()
+
+	require.NoError(t, test.simulator.Start(ctx, test.platform, "sim"))
+
+	// Machines are labeled with the group id, and nodes are waited for using the label
+	require.Len(t, test.created, 2)
+	for _, in := range test.created {
+		assert.Equal(t, "sim", in.Labels[LabelGroupID])
+	}
+	require.Len(t, test.nodes.selectors, 1)
+	assert.Equal(t, map[string]string{LabelGroupID: "sim"}, test.nodes.selectors[0].Map())
+
+	// Pods are launched in the orchestrator namespace with the group id label
+	server, err := test.api.CoreV1().Pods(testNamespace).Get(ctx, "sim-server", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"role": "server", LabelGroupID: "sim"}, server.Labels)
+	assert.Equal(t, 2, test.countPods(t))
+	assert.Equal(t, 1, test.countConfigMaps(t))
+
+	assert.Equal(t, []simulations.Status{
+		simulations.StatusLaunchingInstances,
+		simulations.StatusLaunchingPods,
+		simulations.StatusRunning,
+	}, test.statuses)
+	assert.Equal(t, []string{"before-start", "after-start"}, test.hooks)
+
+	require.NoError(t, test.simulator.Stop(ctx, test.platform, "sim"))
+
+	// All the resources created by the start action are removed
+	assert.Equal(t, 0, test.countPods(t))
+	assert.Equal(t, 0, test.countConfigMaps(t))
+	assert.ElementsMatch(t, []string{"instance-1", "instance-2"}, test.terminated)
+
+	assert.Equal(t, []simulations.Status{
+		simulations.StatusLaunchingInstances,
+		simulations.StatusLaunchingPods,
+		simulations.StatusRunning,
+		simulations.StatusTerminateRequested,
+		simulations.StatusDeletingPods,
+		simulations.StatusTerminatingInstances,
+		simulations.StatusTerminated,
+	}, test.statuses)
+	assert.Equal(t, []string{"before-start", "after-start", "before-stop", "after-stop"}, test.hooks)
+	assert.Empty(t, test.stopErrors)
+
+	// Simulations are not charged if the simulator has no charger
+	test.machines.AssertNotCalled(t, "CalculateCost", mock.Anything)
+	test.services.AssertNotCalled(t, "MarkStopped", mock.Anything)
+}
+
+func TestReferenceSimulator_Charge(t *testing.T) {
+	// The simulation service sets the stop time of the simulation when it is marked as stopped
+	launchedAt := time.Now()
+	stoppedAt := launchedAt.Add(time.Hour)
+	sim := fake.NewSimulation("sim", simulations.StatusPending, simulations.SimSingle, nil, "", time.Hour, nil,
+		&launchedAt)
+	sim = fake.WithStoppedAt(fake.WithCost(sim, 100, nil), &stoppedAt)
+
+	test := newChargedReferenceTest(t, sim)
+	test.expectCreate(nil)
+	ctx := context.Background()
+
+	// Simulations get the rate of their machines when they are launched
+	require.NoError(t, test.simulator.Start(ctx, test.platform, "sim"))
+	assert.Equal(t, testRate, sim.GetRate())
+	test.services.AssertCalled(t, "Update", simulations.GroupID("sim"))
+	test.services.AssertNotCalled(t, "MarkCharged", mock.Anything)
+
+	// Simulations are charged once they are terminated
+	require.NoError(t, test.simulator.Stop(ctx, test.platform, "sim"))
+	assert.Empty(t, test.stopErrors)
+	test.services.AssertCalled(t, "MarkStopped", simulations.GroupID("sim"))
+	test.services.AssertCalled(t, "MarkCharged", simulations.GroupID("sim"))
+
+	entry, err := test.ledger.Get("sim")
+	require.NoError(t, err)
+	assert.Equal(t, uint(100), entry.Amount)
+	assert.Equal(t, testRate, entry.Rate())
+}
+
+func TestReferenceSimulator_ChargeError(t *testing.T) {
+	// Simulations that were never marked as launched can't be charged
+	sim := newPendingSimulation("sim", simulations.SimSingle)
+	test := newChargedReferenceTest(t, sim)
+	test.expectCreate(nil)
+	ctx := context.Background()
+
+	require.NoError(t, test.simulator.Start(ctx, test.platform, "sim"))
+	require.NoError(t, test.simulator.Stop(ctx, test.platform, "sim"))
+
+	// Charge errors are reported without failing the action
+	assert.Equal(t, []string{billing.ErrSimulationNotStopped.Error()}, test.stopErrors)
+	assert.Equal(t, simulations.StatusTerminated, sim.GetStatus())
+	test.services.AssertNotCalled(t, "MarkCharged", mock.Anything)
+}
+
+func TestReferenceSimulator_StartTwice(t *testing.T) {
+	sim := newPendingSimulation("sim", simulations.SimSingle)
+	test := newReferenceTest(t, sim)
+	test.expectCreate(errors.New("out of capacity"))
+	test.expectCreate(nil)
+	test.expectCreate(nil)
+	ctx := context.Background()
+
+	assert.Error(t, test.simulator.Start(ctx, test.platform, "sim"))
+
+	// Starting the simulation again runs the start action in a new deployment
+	sim.SetStatus(simulations.StatusPending)
+	require.NoError(t, test.simulator.Start(ctx, test.platform, "sim"))
+	assert.Equal(t, simulations.StatusRunning, sim.GetStatus())
+
+	first, err := test.deployments.GetDeployment(DeploymentID(ActionStart, "sim", 0))
+	require.NoError(t, err)
+	second, err := test.deployments.GetDeployment(DeploymentID(ActionStart, "sim", 1))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	// Stopping the simulation terminates the instances launched by the last start action
+	require.NoError(t, test.simulator.Stop(ctx, test.platform, "sim"))
+	assert.ElementsMatch(t, []string{"instance-1", "instance-2"}, test.terminated)
+
+	// Restarted simulations are stopped with a new stop deployment
+	sim.SetStatus(simulations.StatusPending)
+	require.NoError(t, test.simulator.Start(ctx, test.platform, "sim"))
+	require.NoError(t, test.simulator.Stop(ctx, test.platform, "sim"))
+	assert.ElementsMatch(t, []string{"instance-1", "instance-2", "instance-3", "instance-4"}, test.terminated)
+
+	_, err = test.deployments.GetDeployment(DeploymentID(ActionStop, "sim", 1))
+	assert.NoError(t, err)
+}
+
+func TestReferenceSimulator_StartChecks(t *testing.T) {
+	running := fake.NewSimulation("running", simulations.StatusRunning, simulations.SimSingle, nil, "", time.Hour,
+		nil, nil)
+	simErr := simulations.Error("error")
+	failed := fake.NewSimulation("failed", simulations.StatusPending, simulations.SimSingle, &simErr, "", time.Hour,
+		nil, nil)
+
+	test := newReferenceTest(t, running, failed, newPendingSimulation("parent", simulations.SimParent))
+	ctx := context.Background()
+
+	assert.True(t, errors.Is(test.simulator.Start(ctx, test.platform, "running"), simulations.ErrIncorrectStatus))
+	assert.True(t, errors.Is(test.simulator.Start(ctx, test.platform, "parent"), simulations.ErrIncorrectKind))
+	assert.Error(t, test.simulator.Start(ctx, test.platform, "failed"))
+
+	// Simulations that fail the checks do not launch any resources, and are not marked as failed
+	test.machines.AssertNotCalled(t, "Create", mock.Anything)
+	assert.Empty(t, test.statuses)
+	assert.Empty(t, test.hooks)
+}
+
+func TestReferenceSimulator_StartRollback(t *testing.T) {
+	sim := newPendingSimulation("sim", simulations.SimSingle)
+	test := newReferenceTest(t, sim)
+	test.expectCreate(errors.New("out of capacity"))
+
+	err := test.simulator.Start(context.Background(), test.platform, "sim")
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, test.countPods(t))
+	assert.Equal(t, []string{"before-start"}, test.hooks)
+
+	// Simulations that fail to start are marked as failed
+	assert.Equal(t, []simulations.Status{
+		simulations.StatusLaunchingInstances,
+		simulations.StatusFailed,
+	}, test.statuses)
+	assert.Equal(t, simulations.StatusFailed, sim.GetStatus())
+}
+
+func TestReferenceSimulator_StopChecks(t *testing.T) {
+	terminated := fake.NewSimulation("terminated", simulations.StatusTerminated, simulations.SimSingle, nil, "",
+		time.Hour, nil, nil)
+	failed := fake.NewSimulation("failed", simulations.StatusFailed, simulations.SimSingle, nil, "", time.Hour,
+		nil, nil)
+
+	test := newReferenceTest(t, newPendingSimulation("pending", simulations.SimSingle), terminated, failed)
+	ctx := context.Background()
+
+	assert.True(t, errors.Is(test.simulator.Stop(ctx, test.platform, "pending"), simulations.ErrIncorrectStatus))
+	assert.True(t, errors.Is(test.simulator.Stop(ctx, test.platform, "terminated"), simulations.ErrIncorrectStatus))
+	assert.True(t, errors.Is(test.simulator.Stop(ctx, test.platform, "failed"), simulations.ErrIncorrectStatus))
+
+	// Simulations that fail the checks are not changed
+	assert.Empty(t, test.statuses)
+	assert.Empty(t, test.hooks)
+	assert.Empty(t, test.terminated)
+}
+
+func TestReferenceSimulator_StopNotStarted(t *testing.T) {
+	// The simulation is running, but the simulator never started it
+	sim := fake.NewSimulation("sim", simulations.StatusRunning, simulations.SimSingle, nil, "", time.Hour, nil, nil)
+	test := newReferenceTest(t, sim)
+
+	require.NoError(t, test.simulator.Stop(context.Background(), test.platform, "sim"))
+	assert.Empty(t, test.terminated)
+
+	// Resources that were never created are reported without failing the action
+	assert.Len(t, test.stopErrors, 1)
+	assert.Equal(t, simulations.StatusTerminated, sim.GetStatus())
+}
+
+func TestReferenceSimulator_StopRequested(t *testing.T) {
+	// Simulations can be stopped after their termination was requested
+	sim := newPendingSimulation("sim", simulations.SimSingle)
+	test := newReferenceTest(t, sim)
+	test.expectCreate(nil)
+	ctx := context.Background()
+
+	require.NoError(t, test.simulator.Start(ctx, test.platform, "sim"))
+	sim.SetStatus(simulations.StatusTerminateRequested)
+
+	require.NoError(t, test.simulator.Stop(ctx, test.platform, "sim"))
+	assert.Equal(t, simulations.StatusTerminated, sim.GetStatus())
+	assert.ElementsMatch(t, []string{"instance-1", "instance-2"}, test.terminated)
+}
@@ -0,0 +1,359 @@
+package reference
+
+import (
+	"context"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/billing"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/jobs"
+)
+
+// jobLaunchInstances is the name of the job that launches the instances of a simulation.
+// The stop action uses it to find the instances created by the start action.
+const jobLaunchInstances = "launch-instances"
+
+// startJobs returns the sequence of jobs of the start action.
+// Simulations that fail to start after passing the checks and the BeforeStart hooks are marked as failed when the
+// action is rolled back.
+// If `charger` is not nil, the rate of the simulation is set after launching its instances.
+func startJobs(spec Spec, hooks Hooks, charger billing.Charger) actions.Jobs {
+	var sequence actions.Jobs
+
+	sequence = append(sequence,
+		checkStatusJob(),
+		checkKindJob(),
+		checkNoErrorJob(),
+	)
+	sequence = append(sequence, hooks.BeforeStart...)
+
+	sequence = append(sequence,
+		failOnRollback(setStatusJob("set-status-launching-instances", simulations.StatusLaunchingInstances)),
+		launchInstancesJob(spec),
+	)
+
+	if charger != nil {
+		sequence = append(sequence, setRateJob(spec, charger))
+	}
+
+	sequence = append(sequence,
+		waitInstancesJob(),
+		waitNodesJob(),
+	)
+
+	if spec.Configurations != nil {
+		sequence = append(sequence, createConfigurationsJob(spec))
+	}
+	if spec.NetworkPolicies != nil {
+		sequence = append(sequence, createNetworkPoliciesJob(spec))
+	}
+
+	sequence = append(sequence,
+		setStatusJob("set-status-launching-pods", simulations.StatusLaunchingPods),
+		launchPodsJob(spec),
+		waitPodsJob(),
+	)
+
+	if spec.WebsocketService != nil {
+		sequence = append(sequence, launchWebsocketServiceJob(spec))
+	}
+	if spec.IngressPaths != nil {
+		sequence = append(sequence, configureIngressJob(spec))
+	}
+
+	sequence = append(sequence, setStatusJob("set-status-running", simulations.StatusRunning))
+	sequence = append(sequence, hooks.AfterStart...)
+
+	return sequence
+}
+
+// checkStatusJob returns a job that checks that the simulation is pending.
+func checkStatusJob() *actions.Job {
+	return extendJob(jobs.CheckSimulationStatus, "check-simulation-status",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			return jobs.CheckSimulationStatusInput{
+				Simulation: sim,
+				Status:     simulations.StatusPending,
+			}, nil
+		},
+		func(s *State, value interface{}) error {
+			if !value.(jobs.CheckSimulationStatusOutput) {
+				return simulations.ErrIncorrectStatus
+			}
+			return nil
+		},
+	)
+}
+
+// failOnRollback returns a copy of a job that sets the status of the simulation to failed when the action is rolled
+// back. Jobs are rolled back in reverse order, so the status is set after the resources of the simulation are removed.
+func failOnRollback(job *actions.Job) *actions.Job {
+	out := *job
+	out.RollbackHandler = func(ctx context.Context, store actions.Store, tx actions.DeploymentStore,
+		deployment *actions.Deployment, value interface{}, err error) (interface{}, error) {
+
+		s := store.State().(*State)
+		if err := s.Services().Simulations().UpdateStatus(s.GroupID, simulations.StatusFailed); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return &out
+}
+
+// checkKindJob returns a job that checks that the simulation is not a parent simulation.
+// Parent simulations are run as a set of child simulations and do not launch any resources.
+func checkKindJob() *actions.Job {
+	return extendJob(jobs.CheckSimulationKind, "check-simulation-kind",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			return jobs.CheckSimulationKindInput{
+				Simulation: sim,
+				Kind:       simulations.SimParent,
+			}, nil
+		},
+		func(s *State, value interface{}) error {
+			if value.(jobs.CheckSimulationKindOutput) {
+				return simulations.ErrIncorrectKind
+			}
+			return nil
+		},
+	)
+}
+
+// checkNoErrorJob returns a job that checks that the simulation does not have an error.
+func checkNoErrorJob() *actions.Job {
+	return extendJob(jobs.CheckSimulationNoError, "check-simulation-no-error",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			return jobs.CheckSimulationNoErrorInput{sim}, nil
+		},
+		func(s *State, value interface{}) error {
+			return value.(jobs.CheckSimulationNoErrorOutput).Error
+		},
+	)
+}
+
+// launchInstancesJob returns a job that launches the machines of the simulation.
+// Machines are labeled with the simulation group id to find the nodes they register in the cluster.
+func launchInstancesJob(spec Spec) *actions.Job {
+	return extendJob(jobs.LaunchInstances, jobLaunchInstances,
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			inputs, err := machineInputs(spec, sim)
+			if err != nil {
+				return nil, err
+			}
+			return jobs.LaunchInstancesInput(inputs), nil
+		},
+		func(s *State, value interface{}) error {
+			s.Instances = value.(jobs.LaunchInstancesOutput)
+			return nil
+		},
+	)
+}
+
+// setRateJob returns a job that sets the rate the simulation is charged at from the cost of its machines.
+func setRateJob(spec Spec, charger billing.Charger) *actions.Job {
+	return NewHook("set-rate", func(ctx context.Context, s *State) error {
+		sim, err := s.simulation()
+		if err != nil {
+			return err
+		}
+		inputs, err := machineInputs(spec, sim)
+		if err != nil {
+			return err
+		}
+		return charger.SetRate(sim, s.Platform().Machines(), inputs)
+	})
+}
+
+// machineInputs returns the machines to launch for the simulation, labeled with the simulation group id.
+func machineInputs(spec Spec, sim simulations.Simulation) ([]machines.CreateMachinesInput, error) {
+	inputs, err := spec.Machines(sim)
+	if err != nil {
+		return nil, err
+	}
+	for i := range inputs {
+		inputs[i].Labels = addGroupIDLabel(inputs[i].Labels, sim.GetGroupID())
+	}
+	return inputs, nil
+}
+
+// waitInstancesJob returns a job that waits for the instances of the simulation to be ready.
+func waitInstancesJob() *actions.Job {
+	return extendJob(jobs.WaitForInstances, "wait-instances",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			return jobs.WaitForInstancesInput(s.Instances), nil
+		}, nil)
+}
+
+// waitNodesJob returns a job that waits for the nodes of the simulation to be ready.
+func waitNodesJob() *actions.Job {
+	return extendJob(jobs.Wait, "wait-nodes",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			orchestrator := s.Platform().Store().Orchestrator()
+			nodes := resource.NewResource("", "", resource.NewSelector(groupIDLabels(s.GroupID)))
+			return jobs.WaitInput{
+				Request:       s.Platform().Orchestrator().Nodes().WaitForCondition(ctx, nodes, resource.ReadyCondition),
+				PollFrequency: orchestrator.PollFrequency(),
+				Timeout:       orchestrator.Timeout(),
+			}, nil
+		},
+		waitOutput,
+	)
+}
+
+// createConfigurationsJob returns a job that creates the configurations of the simulation.
+func createConfigurationsJob(spec Spec) *actions.Job {
+	return extendJob(jobs.CreateConfigurations, "create-configurations",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			inputs, err := spec.Configurations(sim)
+			if err != nil {
+				return nil, err
+			}
+			namespace := s.Platform().Store().Orchestrator().Namespace()
+			for i := range inputs {
+				inputs[i].Namespace = namespace
+			}
+			return jobs.CreateConfigurationsInput(inputs), nil
+		},
+		func(s *State, value interface{}) error {
+			return value.(jobs.CreateConfigurationsOutput).Error
+		},
+	)
+}
+
+// createNetworkPoliciesJob returns a job that creates the network policies of the simulation.
+// Network policies are labeled with the simulation group id to be removed when the simulation stops.
+func createNetworkPoliciesJob(spec Spec) *actions.Job {
+	return extendJob(jobs.CreateNetworkPolicies, "create-network-policies",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			inputs, err := spec.NetworkPolicies(sim)
+			if err != nil {
+				return nil, err
+			}
+			namespace := s.Platform().Store().Orchestrator().Namespace()
+			for i := range inputs {
+				inputs[i].Namespace = namespace
+				inputs[i].Labels = addGroupIDLabel(inputs[i].Labels, s.GroupID)
+			}
+			return jobs.CreateNetworkPoliciesInput(inputs), nil
+		},
+		func(s *State, value interface{}) error {
+			return value.(jobs.CreateNetworkPoliciesOutput).Error
+		},
+	)
+}
+
+// launchPodsJob returns a job that launches the pods of the simulation.
+// Pods are labeled with the simulation group id to be found when waiting for them and when the simulation stops.
+func launchPodsJob(spec Spec) *actions.Job {
+	return extendJob(jobs.LaunchPods, "launch-pods",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			inputs, err := spec.Pods(sim)
+			if err != nil {
+				return nil, err
+			}
+			namespace := s.Platform().Store().Orchestrator().Namespace()
+			for i := range inputs {
+				inputs[i].Namespace = namespace
+				inputs[i].Labels = addGroupIDLabel(inputs[i].Labels, s.GroupID)
+			}
+			return jobs.LaunchPodsInput(inputs), nil
+		}, nil)
+}
+
+// waitPodsJob returns a job that waits for the pods of the simulation to be ready.
+func waitPodsJob() *actions.Job {
+	return extendJob(jobs.Wait, "wait-pods",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			orchestrator := s.Platform().Store().Orchestrator()
+			res := resource.NewResource("", orchestrator.Namespace(), resource.NewSelector(groupIDLabels(s.GroupID)))
+			return jobs.WaitInput{
+				Request:       s.Platform().Orchestrator().Pods().WaitForCondition(ctx, res, resource.ReadyCondition),
+				PollFrequency: orchestrator.PollFrequency(),
+				Timeout:       orchestrator.Timeout(),
+			}, nil
+		},
+		waitOutput,
+	)
+}
+
+// launchWebsocketServiceJob returns a job that launches the websocket service of the simulation.
+func launchWebsocketServiceJob(spec Spec) *actions.Job {
+	return extendJob(jobs.LaunchWebsocketService, "launch-websocket-service",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			input, err := spec.WebsocketService(sim)
+			if err != nil {
+				return nil, err
+			}
+			input.Namespace = s.Platform().Store().Orchestrator().Namespace()
+			return jobs.LaunchWebsocketServiceInput(*input), nil
+		},
+		func(s *State, value interface{}) error {
+			return value.(jobs.LaunchWebsocketServiceOutput).Error
+		},
+	)
+}
+
+// configureIngressJob returns a job that adds the paths of the simulation to the platform ingress.
+func configureIngressJob(spec Spec) *actions.Job {
+	return extendJob(jobs.ConfigureIngress, "configure-ingress",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			paths, err := spec.IngressPaths(sim)
+			if err != nil {
+				return nil, err
+			}
+			orchestrator := s.Platform().Store().Orchestrator()
+			return jobs.ConfigureIngressInput{
+				Name:      orchestrator.IngressName(),
+				Namespace: orchestrator.IngressNamespace(),
+				Host:      orchestrator.IngressHost(),
+				Paths:     paths,
+			}, nil
+		},
+		func(s *State, value interface{}) error {
+			return value.(jobs.ConfigureIngressOutput).Error
+		},
+	)
+}
+
+// waitOutput returns the error of a Wait job.
+func waitOutput(s *State, value interface{}) error {
+	return value.(jobs.WaitOutput).Error
+}
@@ -0,0 +1,164 @@
+package reference
+
+import (
+	"context"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/application"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/jobs"
+)
+
+// State is the action store state used by the reference simulator actions.
+// It implements state.PlatformGetter and state.ServicesGetter, and it is passed as the input and output value of
+// every job in the start and stop actions. Only exported fields are persisted in the deployment job data.
+type State struct {
+	platform platform.Platform
+	services application.Services
+	// GroupID is the group id of the simulation being started or stopped.
+	GroupID simulations.GroupID
+	// StartDeploymentID is the UUID of the deployment of the last start action of the simulation being stopped.
+	// It is empty if the simulation was never started.
+	StartDeploymentID string
+	// Instances contains the instances launched for the simulation.
+	Instances []machines.CreateMachinesOutput
+	// Errors contains the errors returned while removing the resources of the simulation and charging it.
+	// These errors do not fail the stop action, to make sure that every resource is removed.
+	Errors []string
+}
+
+// NewState initializes a new State. It can be used to create the state of deployments resumed by an
+// actions.Resumer.
+func NewState(platform platform.Platform, services application.Services, groupID simulations.GroupID) *State {
+	return &State{
+		platform: platform,
+		services: services,
+		GroupID:  groupID,
+	}
+}
+
+// Platform returns the platform the simulation runs in.
+func (s *State) Platform() platform.Platform {
+	return s.platform
+}
+
+// Services returns the application services.
+func (s *State) Services() application.Services {
+	return s.services
+}
+
+// simulation returns the simulation being started or stopped.
+func (s *State) simulation() (simulations.Simulation, error) {
+	return s.services.Simulations().Get(s.GroupID)
+}
+
+// recordError records an error returned while removing a resource of the simulation or charging it.
+func (s *State) recordError(err error) {
+	if err != nil {
+		s.Errors = append(s.Errors, err.Error())
+	}
+}
+
+// stateType is the job data type of the values passed between the reference simulator jobs.
+var stateType = actions.GetJobDataType(&State{})
+
+// restoreState copies the persisted fields of the value received by a job into the store state.
+// This allows resumed deployments to continue with the state recorded by the last job that ran.
+func restoreState(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
+	value interface{}) (interface{}, error) {
+
+	in, ok := value.(*State)
+	if !ok {
+		return nil, simulator.ErrInvalidInput
+	}
+
+	s := store.State().(*State)
+	s.GroupID = in.GroupID
+	s.StartDeploymentID = in.StartDeploymentID
+	s.Instances = in.Instances
+	s.Errors = in.Errors
+
+	return s, nil
+}
+
+// HookFunc is the function signature used by hooks created with NewHook.
+type HookFunc func(ctx context.Context, s *State) error
+
+// NewHook creates a job that can be added to the reference simulator Hooks.
+// Hooks receive the state of the simulation being started or stopped, and return an error to fail the action.
+func NewHook(name string, fn HookFunc) *actions.Job {
+	return actions.NewJob(actions.TypedJob[*State, *State]{
+		Name: name,
+		PreHooks: []actions.TypedHookFunc[*State]{
+			func(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
+				value *State) (*State, error) {
+
+				out, err := restoreState(ctx, store, tx, deployment, value)
+				if err != nil {
+					return nil, err
+				}
+				return out.(*State), nil
+			},
+		},
+		Execute: func(ctx context.Context, store actions.Store, tx actions.DeploymentStore,
+			deployment *actions.Deployment, value *State) (*State, error) {
+
+			if err := fn(ctx, value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		},
+	})
+}
+
+// inputFunc returns the input of a generic job from the state of the simulation being started or stopped.
+type inputFunc func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error)
+
+// outputFunc processes the output of a generic job, updating the state of the simulation being started or stopped.
+// Returning an error fails the job.
+type outputFunc func(s *State, value interface{}) error
+
+// extendJob extends a generic job to receive and return the State.
+// The `input` function is used to create the input of the job, and the optional `output` function processes its
+// output.
+func extendJob(job *actions.Job, name string, input inputFunc, output outputFunc) *actions.Job {
+	return job.Extend(actions.Job{
+		Name: name,
+		PreHooks: []actions.JobFunc{
+			restoreState,
+			func(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
+				value interface{}) (interface{}, error) {
+
+				return input(ctx, tx, store.State().(*State))
+			},
+		},
+		PostHooks: []actions.JobFunc{
+			func(ctx context.Context, store actions.Store, tx actions.DeploymentStore, deployment *actions.Deployment,
+				value interface{}) (interface{}, error) {
+
+				s := store.State().(*State)
+				if output != nil {
+					if err := output(s, value); err != nil {
+						return nil, err
+					}
+				}
+				return s, nil
+			},
+		},
+		InputType:  stateType,
+		OutputType: stateType,
+	})
+}
+
+// setStatusJob returns a job that sets the status of the simulation being started or stopped.
+func setStatusJob(name string, status simulations.Status) *actions.Job {
+	return extendJob(jobs.SetSimulationStatus, name,
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			return jobs.SetSimulationStatusInput{
+				GroupID: s.GroupID,
+				Status:  status,
+			}, nil
+		}, nil)
+}
@@ -0,0 +1,257 @@
+package reference
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/actions"
+	"github.com/gazebo-web/cloudsim/v4/pkg/billing"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/orchestrator/resource"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator/jobs"
+)
+
+// stopJobs returns the sequence of jobs of the stop action.
+// Only simulations that were launched can be stopped. Their status is set to terminate-requested before running the
+// BeforeStop hooks.
+// Errors returned while removing ingress rules, services, network policies and configurations are recorded in the
+// State instead of failing the action, as these resources may have not been created or may have already been removed.
+// If `charger` is not nil, the simulation is charged after it is terminated.
+func stopJobs(spec Spec, hooks Hooks, charger billing.Charger) actions.Jobs {
+	var sequence actions.Jobs
+
+	sequence = append(sequence,
+		checkStoppableJob(),
+		setStatusJob("set-status-terminate-requested", simulations.StatusTerminateRequested),
+	)
+	sequence = append(sequence, hooks.BeforeStop...)
+	sequence = append(sequence, setStatusJob("set-status-deleting-pods", simulations.StatusDeletingPods))
+
+	if spec.IngressPaths != nil {
+		sequence = append(sequence, removeIngressRulesJob(spec))
+	}
+	if spec.WebsocketService != nil {
+		sequence = append(sequence, removeWebsocketServiceJob(spec))
+	}
+
+	sequence = append(sequence, removePodsJob())
+
+	if spec.NetworkPolicies != nil {
+		sequence = append(sequence, removeNetworkPoliciesJob())
+	}
+	if spec.Configurations != nil {
+		sequence = append(sequence, removeConfigurationsJob(spec))
+	}
+
+	sequence = append(sequence,
+		setStatusJob("set-status-terminating-instances", simulations.StatusTerminatingInstances),
+		removeInstancesJob(),
+		setStatusJob("set-status-terminated", simulations.StatusTerminated),
+	)
+
+	if charger != nil {
+		sequence = append(sequence, chargeJob(charger))
+	}
+
+	sequence = append(sequence, hooks.AfterStop...)
+
+	return sequence
+}
+
+// checkStoppableJob returns a job that checks that the simulation is being launched, is running, or was requested to
+// terminate. Pending simulations have not launched any resources, and simulations that already finished can't be
+// stopped again.
+func checkStoppableJob() *actions.Job {
+	return NewHook("check-simulation-status", func(ctx context.Context, s *State) error {
+		sim, err := s.simulation()
+		if err != nil {
+			return err
+		}
+		if sim.HasStatus(simulations.StatusPending) ||
+			!simulations.CanTransition(sim.GetStatus(), simulations.StatusTerminateRequested) {
+			return simulations.ErrIncorrectStatus
+		}
+		return nil
+	})
+}
+
+// removeIngressRulesJob returns a job that removes the paths of the simulation from the platform ingress.
+func removeIngressRulesJob(spec Spec) *actions.Job {
+	return extendJob(jobs.RemoveIngressRules, "remove-ingress-rules",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			paths, err := spec.IngressPaths(sim)
+			if err != nil {
+				return nil, err
+			}
+			orchestrator := s.Platform().Store().Orchestrator()
+			return jobs.RemoveIngressRulesInput{
+				Name:      orchestrator.IngressName(),
+				Namespace: orchestrator.IngressNamespace(),
+				Host:      orchestrator.IngressHost(),
+				Paths:     paths,
+			}, nil
+		},
+		func(s *State, value interface{}) error {
+			s.recordError(value.(jobs.RemoveIngressRulesOutput).Error)
+			return nil
+		},
+	)
+}
+
+// removeWebsocketServiceJob returns a job that removes the websocket service of the simulation.
+func removeWebsocketServiceJob(spec Spec) *actions.Job {
+	return extendJob(jobs.RemoveWebsocketService, "remove-websocket-service",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			input, err := spec.WebsocketService(sim)
+			if err != nil {
+				return nil, err
+			}
+			return jobs.RemoveWebsocketServiceInput{
+				Name:      input.Name,
+				Namespace: s.Platform().Store().Orchestrator().Namespace(),
+			}, nil
+		},
+		func(s *State, value interface{}) error {
+			s.recordError(value.(jobs.RemoveWebsocketServiceOutput).Error)
+			return nil
+		},
+	)
+}
+
+// removePodsJob returns a job that removes the pods labeled with the simulation group id.
+func removePodsJob() *actions.Job {
+	return extendJob(jobs.RemovePods, "remove-pods",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			namespace := s.Platform().Store().Orchestrator().Namespace()
+			selector := resource.NewSelector(groupIDLabels(s.GroupID))
+			list, err := s.Platform().Orchestrator().Pods().List(ctx, namespace, selector)
+			if err != nil {
+				return nil, err
+			}
+			input := make(jobs.RemovePodsInput, len(list))
+			for i, pod := range list {
+				input[i] = pod.Resource
+			}
+			return input, nil
+		},
+		func(s *State, value interface{}) error {
+			return value.(jobs.RemovePodsOutput).Error
+		},
+	)
+}
+
+// removeNetworkPoliciesJob returns a job that removes the network policies labeled with the simulation group id.
+func removeNetworkPoliciesJob() *actions.Job {
+	return extendJob(jobs.RemoveNetworkPolicies, "remove-network-policies",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			return jobs.RemoveNetworkPoliciesInput{
+				Selector:  resource.NewSelector(groupIDLabels(s.GroupID)),
+				Namespace: s.Platform().Store().Orchestrator().Namespace(),
+			}, nil
+		},
+		func(s *State, value interface{}) error {
+			s.recordError(value.(jobs.RemoveNetworkPoliciesOutput).Error)
+			return nil
+		},
+	)
+}
+
+// removeConfigurationsJob returns a job that removes the configurations of the simulation.
+func removeConfigurationsJob(spec Spec) *actions.Job {
+	return extendJob(jobs.RemoveConfigurations, "remove-configurations",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			sim, err := s.simulation()
+			if err != nil {
+				return nil, err
+			}
+			inputs, err := spec.Configurations(sim)
+			if err != nil {
+				return nil, err
+			}
+			namespace := s.Platform().Store().Orchestrator().Namespace()
+			var input jobs.RemoveConfigurationsInput
+			for _, in := range inputs {
+				input.Resources = append(input.Resources, resource.NewResource(in.Name, namespace, nil))
+			}
+			return input, nil
+		},
+		func(s *State, value interface{}) error {
+			s.recordError(value.(jobs.RemoveConfigurationsOutput).Error)
+			return nil
+		},
+	)
+}
+
+// removeInstancesJob returns a job that terminates the instances launched by the start action of the simulation.
+// Instances are read from the start action deployment, and no instances are terminated if the simulation was never
+// started.
+func removeInstancesJob() *actions.Job {
+	return extendJob(jobs.RemoveInstances, "remove-instances",
+		func(ctx context.Context, tx actions.DeploymentStore, s *State) (interface{}, error) {
+			instances := s.Instances
+			if len(instances) == 0 {
+				var err error
+				if instances, err = getLaunchedInstances(tx, s.StartDeploymentID); err != nil {
+					return nil, err
+				}
+			}
+			input := make(jobs.RemoveInstancesInput, len(instances))
+			for i, instance := range instances {
+				input[i] = instance.ToTerminateMachinesInput()
+			}
+			return input, nil
+		}, nil)
+}
+
+// getLaunchedInstances returns the instances launched by the start action deployment with the given UUID.
+func getLaunchedInstances(tx actions.DeploymentStore, uuid string) ([]machines.CreateMachinesOutput, error) {
+	if uuid == "" {
+		return nil, nil
+	}
+
+	deployment, err := tx.GetDeployment(uuid)
+	if errors.Is(err, actions.ErrDeploymentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := jobs.GetLaunchedInstances(tx, deployment, jobLaunchInstances)
+	if errors.Is(err, actions.ErrDeploymentDataNotFound) || errors.Is(err, actions.ErrDeploymentDataNoData) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// chargeJob returns a job that marks the simulation as stopped and charges it.
+// Charge errors are recorded in the State instead of failing the action, as the simulation has already been terminated.
+// Simulations that fail to be charged are charged again by billing.Charger.Reprocess.
+func chargeJob(charger billing.Charger) *actions.Job {
+	return NewHook("charge", func(ctx context.Context, s *State) error {
+		if err := s.Services().Simulations().MarkStopped(s.GroupID); err != nil {
+			s.recordError(err)
+			return nil
+		}
+		sim, err := s.simulation()
+		if err != nil {
+			s.recordError(err)
+			return nil
+		}
+		_, err = charger.Charge(sim)
+		s.recordError(err)
+		return nil
+	})
+}
@@ -0,0 +1,22 @@
+package scheduler
+
+import (
+	gormUtils "github.com/gazebo-web/gz-go/v7/database/gorm"
+	"github.com/jinzhu/gorm"
+)
+
+// MigrateDB migrates scheduler database models, indexes and keys.
+func MigrateDB(tx *gorm.DB) error {
+	return gormUtils.MigrateModels(
+		tx,
+		&Item{},
+	)
+}
+
+// DropDB drops scheduler database models, indexes and keys.
+func DropDB(tx *gorm.DB) error {
+	return gormUtils.DropModels(
+		tx,
+		&Item{},
+	)
+}
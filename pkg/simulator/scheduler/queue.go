@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// ErrAlreadyQueued is returned when pushing a simulation that is already in the queue.
+	ErrAlreadyQueued = errors.New("simulation already queued")
+	// ErrItemNotFound is returned when a simulation is not in the queue.
+	ErrItemNotFound = errors.New("simulation not queued")
+)
+
+// Item is a pending simulation waiting in the queue.
+type Item struct {
+	gorm.Model
+	// GroupID identifies the pending simulation. Simulations are queued at most once.
+	GroupID simulations.GroupID `gorm:"not null;unique"`
+	// Priority is the priority of the simulation. Simulations with higher priorities are launched first.
+	Priority int
+	// Machines is the number of machines required to launch the simulation.
+	Machines int
+}
+
+// TableName defines the database table name for queue items.
+func (Item) TableName() string {
+	return "scheduler_queue"
+}
+
+// Queue keeps track of the pending simulations waiting to be launched.
+type Queue interface {
+	// Push adds a simulation to the queue. Returns ErrAlreadyQueued if the simulation is already in the queue.
+	// Items that were listed and removed from the queue keep their position when they are pushed again.
+	Push(item *Item) error
+	// Remove removes a simulation from the queue. Returns ErrItemNotFound if the simulation is not in the queue.
+	Remove(groupID simulations.GroupID) error
+	// List returns the simulations in the queue in the order they should be launched.
+	// Items are sorted by descending priority. Items with the same priority are sorted in the order they were pushed.
+	List() ([]*Item, error)
+}
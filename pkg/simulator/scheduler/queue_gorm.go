@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/jinzhu/gorm"
+)
+
+// gormQueue is a Queue implementation that persists items in a relational database using gorm.
+// The database tables can be created with MigrateDB.
+type gormQueue struct {
+	// db contains the database connection or transaction used to persist items.
+	db *gorm.DB
+}
+
+// NewGormQueue returns a Queue that persists items using the given gorm database connection or transaction.
+func NewGormQueue(db *gorm.DB) Queue {
+	return &gormQueue{
+		db: db,
+	}
+}
+
+// Push adds a simulation to the queue.
+// Items have a unique group id, so concurrent pushes of the same simulation are rejected by the database.
+func (q *gormQueue) Push(item *Item) error {
+	exists, err := q.exists(item.GroupID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrAlreadyQueued
+	}
+
+	if err := q.db.Model(&Item{}).Create(item).Error; err != nil {
+		// Check if the item was pushed concurrently
+		if exists, existsErr := q.exists(item.GroupID); existsErr == nil && exists {
+			return ErrAlreadyQueued
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Remove removes a simulation from the queue.
+// Items are permanently deleted, so that the simulation can be queued again.
+func (q *gormQueue) Remove(groupID simulations.GroupID) error {
+	result := q.db.Unscoped().Where("group_id = ?", groupID).Delete(&Item{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrItemNotFound
+	}
+
+	return nil
+}
+
+// List returns the simulations in the queue in the order they should be launched.
+func (q *gormQueue) List() ([]*Item, error) {
+	var items []*Item
+
+	if err := q.db.Order("priority desc").Order("id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// exists returns true if a simulation is in the queue.
+func (q *gormQueue) exists(groupID simulations.GroupID) (bool, error) {
+	var count int
+	if err := q.db.Model(&Item{}).Where("group_id = ?", groupID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryQueue is a Queue implementation that keeps items in memory.
+// It is intended for unit tests.
+type memoryQueue struct {
+	// lock is used to allow concurrent access to the queue.
+	lock sync.RWMutex
+	// items contains the queued items, indexed by group id.
+	items map[simulations.GroupID]*Item
+	// lastID is the id assigned to the last pushed item.
+	lastID uint
+}
+
+// NewMemoryQueue returns a Queue that keeps items in memory.
+func NewMemoryQueue() Queue {
+	return &memoryQueue{
+		items: make(map[simulations.GroupID]*Item),
+	}
+}
+
+// Push adds a simulation to the queue.
+func (q *memoryQueue) Push(item *Item) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if _, ok := q.items[item.GroupID]; ok {
+		return ErrAlreadyQueued
+	}
+
+	// Items that were queued before keep their id, and therefore their position in the queue
+	if item.ID == 0 {
+		q.lastID++
+		item.ID = q.lastID
+		item.CreatedAt = time.Now()
+	}
+	item.UpdatedAt = time.Now()
+
+	stored := *item
+	q.items[item.GroupID] = &stored
+
+	return nil
+}
+
+// Remove removes a simulation from the queue.
+func (q *memoryQueue) Remove(groupID simulations.GroupID) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if _, ok := q.items[groupID]; !ok {
+		return ErrItemNotFound
+	}
+	delete(q.items, groupID)
+
+	return nil
+}
+
+// List returns the simulations in the queue in the order they should be launched.
+func (q *memoryQueue) List() ([]*Item, error) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	items := make([]*Item, 0, len(q.items))
+	for _, item := range q.items {
+		out := *item
+		items = append(items, &out)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Priority != items[j].Priority {
+			return items[i].Priority > items[j].Priority
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	return items, nil
+}
@@ -0,0 +1,341 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform/manager"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
+	"github.com/gazebo-web/gz-go/v7"
+	"gopkg.in/go-playground/validator.v9"
+	"sync"
+	"time"
+)
+
+const (
+	// UnlimitedMachines is returned by AvailableMachines for platforms without a machine limit.
+	UnlimitedMachines = -1
+)
+
+var (
+	// ErrInvalidInterval is returned when a scheduler is run with an interval lower than 1.
+	ErrInvalidInterval = errors.New("scheduler interval must be positive")
+	// ErrInvalidMachines is returned when queueing a simulation that requires less than one machine.
+	ErrInvalidMachines = errors.New("simulations require at least one machine")
+	// ErrCountMachinesFailed is returned when the number of machines running in a platform cannot be counted.
+	ErrCountMachinesFailed = errors.New("failed to count machines")
+)
+
+// Config contains the configuration of a Scheduler.
+type Config struct {
+	// Queue is used to persist pending simulations.
+	Queue Queue `validate:"required"`
+	// Services is used to get simulations, update their statuses and record when they are launched.
+	Services simulations.Service `validate:"required"`
+	// Platforms is used to find the platform each simulation runs in.
+	Platforms manager.Manager `validate:"required"`
+	// Simulator is used to launch simulations.
+	Simulator simulator.Simulator `validate:"required"`
+	// Logger is used to log scheduler results.
+	Logger gz.Logger `validate:"required"`
+}
+
+// Report contains the result of a Scheduler.Schedule run.
+type Report struct {
+	// Launched contains the simulations that were launched.
+	Launched []simulations.GroupID
+	// Waiting contains the simulations that remain in the queue.
+	Waiting []simulations.GroupID
+	// Errors contains the errors returned while launching simulations, indexed by group id.
+	// Simulations that fail to launch are removed from the queue and marked as failed.
+	Errors map[simulations.GroupID]error
+}
+
+// Scheduler holds pending simulations in a queue and launches them once there is enough capacity to run them.
+//
+// Simulations are launched in order of priority, and simulations with the same priority are launched in the order
+// they were queued. If a simulation does not fit in the capacity available in its platform, the simulations queued
+// after it in the same platform wait until it is launched. This prevents small simulations from starving large ones.
+type Scheduler interface {
+	// Enqueue adds a pending simulation to the queue.
+	// `machines` is the number of machines required to run the simulation.
+	Enqueue(sim simulations.Simulation, machines int, priority int) error
+	// Dequeue removes a simulation from the queue.
+	Dequeue(groupID simulations.GroupID) error
+	// Schedule launches the queued simulations that fit in the capacity available in their platforms.
+	// Schedule returns once the launched simulations have started.
+	Schedule(ctx context.Context) (*Report, error)
+	// Run calls Schedule every `interval` until the context is cancelled.
+	// Simulations waiting for capacity are scheduled again on the next run, so `interval` bounds how long a queued
+	// simulation waits after capacity frees up. Run blocks until the context is cancelled.
+	Run(ctx context.Context, interval time.Duration) error
+}
+
+// scheduler is a Scheduler implementation.
+type scheduler struct {
+	Config
+	// lock prevents concurrent schedule runs from selecting the same simulations. It is released before the selected
+	// simulations are started.
+	lock sync.Mutex
+	// now returns the current time.
+	now func() time.Time
+}
+
+// NewScheduler initializes a new Scheduler.
+func NewScheduler(config Config) (Scheduler, error) {
+	if err := validator.New().Struct(config); err != nil {
+		return nil, err
+	}
+
+	return &scheduler{
+		Config: config,
+		now:    time.Now,
+	}, nil
+}
+
+// Enqueue adds a pending simulation to the queue.
+func (s *scheduler) Enqueue(sim simulations.Simulation, machines int, priority int) error {
+	if !sim.HasStatus(simulations.StatusPending) {
+		return simulations.ErrIncorrectStatus
+	}
+	if machines < 1 {
+		return ErrInvalidMachines
+	}
+
+	return s.Queue.Push(&Item{
+		GroupID:  sim.GetGroupID(),
+		Priority: priority,
+		Machines: machines,
+	})
+}
+
+// Dequeue removes a simulation from the queue.
+func (s *scheduler) Dequeue(groupID simulations.GroupID) error {
+	return s.Queue.Remove(groupID)
+}
+
+// launch contains a simulation selected to be launched.
+type launch struct {
+	item     *Item
+	platform platform.Platform
+}
+
+// Schedule launches the queued simulations that fit in the capacity available in their platforms.
+func (s *scheduler) Schedule(ctx context.Context) (*Report, error) {
+	report := &Report{
+		Errors: make(map[simulations.GroupID]error),
+	}
+
+	launches, err := s.dequeue(ctx, report)
+	if err != nil {
+		return report, err
+	}
+
+	s.launch(ctx, launches, report)
+
+	return report, nil
+}
+
+// dequeue selects the queued simulations that fit in the capacity available in their platforms, and removes them from
+// the queue to avoid launching them twice.
+// Simulations are started without holding the scheduler lock, so a concurrent run may select simulations before the
+// machines of the previous run are created. Simulations that fail to start because the capacity was taken are queued
+// again by launch.
+func (s *scheduler) dequeue(ctx context.Context, report *Report) ([]launch, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	items, err := s.Queue.List()
+	if err != nil {
+		return nil, err
+	}
+
+	// available contains the number of machines available in each platform
+	available := make(map[string]int)
+	// blocked contains the platforms where a simulation is waiting for capacity
+	blocked := make(map[string]bool)
+
+	var launches []launch
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		sim, err := s.Services.Get(item.GroupID)
+		if err != nil {
+			report.Errors[item.GroupID] = err
+			continue
+		}
+
+		// Simulations that are no longer pending (e.g. were stopped) do not need to be launched
+		if !sim.HasStatus(simulations.StatusPending) {
+			if err := s.Queue.Remove(item.GroupID); err != nil {
+				report.Errors[item.GroupID] = err
+			}
+			continue
+		}
+
+		p, err := manager.GetSimulationPlatform(s.Platforms, sim)
+		if err != nil {
+			s.fail(item.GroupID, err, report)
+			continue
+		}
+
+		name := p.GetName()
+		if blocked[name] {
+			report.Waiting = append(report.Waiting, item.GroupID)
+			continue
+		}
+
+		if _, ok := available[name]; !ok {
+			if available[name], err = AvailableMachines(p); err != nil {
+				report.Errors[item.GroupID] = err
+				report.Waiting = append(report.Waiting, item.GroupID)
+				blocked[name] = true
+				continue
+			}
+		}
+
+		if available[name] != UnlimitedMachines {
+			if item.Machines > available[name] {
+				report.Waiting = append(report.Waiting, item.GroupID)
+				blocked[name] = true
+				continue
+			}
+			available[name] -= item.Machines
+		}
+
+		// Remove the simulation from the queue before launching it to avoid launching it twice
+		if err := s.Queue.Remove(item.GroupID); err != nil {
+			report.Errors[item.GroupID] = err
+			continue
+		}
+
+		launches = append(launches, launch{item: item, platform: p})
+	}
+
+	return launches, nil
+}
+
+// launch launches the given simulations concurrently, and waits for them to start.
+func (s *scheduler) launch(ctx context.Context, launches []launch, report *Report) {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+
+	for _, l := range launches {
+		wg.Add(1)
+		go func(l launch) {
+			defer wg.Done()
+
+			groupID := l.item.GroupID
+			err := s.start(ctx, l)
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			switch {
+			case err == nil:
+				report.Launched = append(report.Launched, groupID)
+			case errors.Is(err, machines.ErrInsufficientMachines):
+				// Capacity was taken before the simulation started, queue it again
+				if err := s.requeue(l.item); err != nil {
+					report.Errors[groupID] = err
+					return
+				}
+				report.Waiting = append(report.Waiting, groupID)
+			default:
+				s.fail(groupID, err, report)
+			}
+		}(l)
+	}
+	wg.Wait()
+}
+
+// start starts a simulation and records the time it was launched.
+func (s *scheduler) start(ctx context.Context, l launch) error {
+	if err := s.Simulator.Start(ctx, l.platform, l.item.GroupID); err != nil {
+		return err
+	}
+
+	return s.Services.MarkLaunched(l.item.GroupID, s.now())
+}
+
+// requeue puts back a simulation that could not be launched in the queue, keeping its position.
+func (s *scheduler) requeue(item *Item) error {
+	if err := s.Services.UpdateStatus(item.GroupID, simulations.StatusPending); err != nil {
+		return err
+	}
+
+	return s.Queue.Push(item)
+}
+
+// fail marks a simulation that failed to launch as failed.
+func (s *scheduler) fail(groupID simulations.GroupID, err error, report *Report) {
+	report.Errors[groupID] = err
+	s.Logger.Warning(fmt.Sprintf("Failed to launch simulation [%s]. Error: %s", groupID, err))
+
+	if err := s.Queue.Remove(groupID); err != nil && !errors.Is(err, ErrItemNotFound) {
+		s.Logger.Warning(fmt.Sprintf("Failed to remove simulation [%s] from the queue. Error: %s", groupID, err))
+	}
+
+	if err := s.Services.UpdateStatus(groupID, simulations.StatusFailed); err != nil {
+		s.Logger.Warning(fmt.Sprintf("Failed to mark simulation [%s] as failed. Error: %s", groupID, err))
+	}
+}
+
+// Run calls Schedule every `interval` until the context is cancelled.
+func (s *scheduler) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		report, err := s.Schedule(ctx)
+		if err != nil {
+			s.Logger.Debug(fmt.Sprintf("Scheduling pending simulations failed: %s", err))
+			continue
+		}
+
+		s.Logger.Debug(fmt.Sprintf("Scheduled pending simulations: %d launched, %d waiting, %d errors",
+			len(report.Launched), len(report.Waiting), len(report.Errors)))
+	}
+}
+
+// AvailableMachines returns the number of machines that can still be created in a platform.
+// It returns UnlimitedMachines if the platform does not have a machine limit.
+func AvailableMachines(p platform.Platform) (int, error) {
+	limit := p.Store().Machines().Limit()
+	if limit < 0 {
+		return UnlimitedMachines, nil
+	}
+
+	running := p.Machines().Count(machines.CountMachinesInput{
+		Filters: map[string][]string{
+			"instance-state-name": {
+				"pending",
+				"running",
+			},
+		},
+	})
+	if running < 0 {
+		return 0, ErrCountMachinesFailed
+	}
+
+	if running >= limit {
+		return 0, nil
+	}
+
+	return limit - running, nil
+}
@@ -0,0 +1,321 @@
+package scheduler
+
+import (
+	"context"
+	cloudfake "github.com/gazebo-web/cloudsim/v4/pkg/cloud/fake"
+	"github.com/gazebo-web/cloudsim/v4/pkg/machines"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform/manager"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations/fake"
+	simfake "github.com/gazebo-web/cloudsim/v4/pkg/simulator/fake"
+	fakeStore "github.com/gazebo-web/cloudsim/v4/pkg/store/implementations/fake"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type schedulerTest struct {
+	scheduler *scheduler
+	services  *fake.Service
+	simulator *simfake.Simulator
+	machines  *cloudfake.Machines
+	// count is the expected call that returns the number of machines running in the test platform.
+	count *mock.Call
+	// sims contains the simulations added to the queue, indexed by group id.
+	sims map[simulations.GroupID]simulations.Simulation
+	// starts contains the expected Simulator.Start calls of the simulations in sims.
+	starts map[simulations.GroupID]*mock.Call
+	now    time.Time
+}
+
+func newSchedulerTest(t *testing.T, limit int, running int) *schedulerTest {
+	storeMachines := fakeStore.NewFakeMachines()
+	storeMachines.On("Limit").Return(limit)
+
+	test := &schedulerTest{
+		services:  fake.NewService(),
+		simulator: simfake.NewSimulator(),
+		machines:  cloudfake.NewMachines(),
+		sims:      make(map[simulations.GroupID]simulations.Simulation),
+		starts:    make(map[simulations.GroupID]*mock.Call),
+		now:       time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	test.setRunning(running)
+	test.services.On("MarkLaunched", mock.AnythingOfType("simulations.GroupID"), test.now).Return(nil)
+
+	p, err := platform.NewPlatform("test", platform.Components{
+		Machines: test.machines,
+		Store:    fakeStore.NewFakeStore(storeMachines, nil, nil),
+	})
+	require.NoError(t, err)
+
+	s, err := NewScheduler(Config{
+		Queue:     NewMemoryQueue(),
+		Services:  test.services,
+		Platforms: manager.Map{"test": p},
+		Simulator: test.simulator,
+		Logger:    gz.NewLoggerNoRollbar("TestScheduler", gz.VerbosityWarning),
+	})
+	require.NoError(t, err)
+
+	test.scheduler = s.(*scheduler)
+	test.scheduler.now = func() time.Time {
+		return test.now
+	}
+
+	return test
+}
+
+// setRunning sets the number of machines running in the test platform.
+func (test *schedulerTest) setRunning(running int) {
+	if test.count != nil {
+		test.count.Unset()
+	}
+	test.count = test.machines.On("Count", mock.Anything).Return(running)
+}
+
+// enqueue creates a pending simulation and adds it to the queue.
+// Starting the simulation sets its status to running.
+func (test *schedulerTest) enqueue(t *testing.T, groupID simulations.GroupID, machines int, priority int) {
+	selector := "test"
+	sim := fake.WithPlatform(fake.NewSimulation(groupID, simulations.StatusPending, simulations.SimSingle, nil, "",
+		time.Hour, nil, nil), &selector)
+	test.sims[groupID] = sim
+
+	test.services.On("Get", groupID).Return(sim, nil)
+	test.services.On("UpdateStatus", groupID, mock.AnythingOfType("simulations.Status")).
+		Run(func(args mock.Arguments) {
+			sim.SetStatus(args.Get(1).(simulations.Status))
+		}).
+		Return(nil)
+	test.setStartError(groupID, nil)
+
+	require.NoError(t, test.scheduler.Enqueue(sim, machines, priority))
+}
+
+// setStartError sets the error returned when starting a simulation added to the queue with enqueue.
+func (test *schedulerTest) setStartError(groupID simulations.GroupID, err error) {
+	if call, ok := test.starts[groupID]; ok {
+		call.Unset()
+	}
+
+	sim := test.sims[groupID]
+	test.starts[groupID] = test.simulator.On("Start", mock.Anything, mock.Anything, groupID).
+		Run(func(args mock.Arguments) {
+			// Simulations leave the pending status as soon as they start launching
+			sim.SetStatus(simulations.StatusLaunchingInstances)
+			if err == nil {
+				sim.SetStatus(simulations.StatusRunning)
+			}
+		}).
+		Return(err)
+}
+
+func TestNewScheduler_InvalidConfig(t *testing.T) {
+	_, err := NewScheduler(Config{})
+	assert.Error(t, err)
+}
+
+func TestScheduler_Enqueue(t *testing.T) {
+	test := newSchedulerTest(t, -1, 0)
+	test.enqueue(t, "sim", 1, 0)
+
+	assert.Equal(t, ErrAlreadyQueued, test.scheduler.Enqueue(test.sims["sim"], 1, 0))
+
+	running := fake.NewSimulation("running", simulations.StatusRunning, simulations.SimSingle, nil, "", time.Hour,
+		nil, nil)
+	assert.Equal(t, simulations.ErrIncorrectStatus, test.scheduler.Enqueue(running, 1, 0))
+
+	pending := fake.NewSimulation("pending", simulations.StatusPending, simulations.SimSingle, nil, "", time.Hour,
+		nil, nil)
+	assert.Equal(t, ErrInvalidMachines, test.scheduler.Enqueue(pending, 0, 0))
+
+	require.NoError(t, test.scheduler.Dequeue("sim"))
+	assert.Equal(t, ErrItemNotFound, test.scheduler.Dequeue("sim"))
+}
+
+func TestScheduler_ScheduleOrder(t *testing.T) {
+	// 5 machines available
+	test := newSchedulerTest(t, 8, 3)
+
+	test.enqueue(t, "first", 2, 0)
+	test.enqueue(t, "large", 4, 0)
+	test.enqueue(t, "small", 1, 0)
+	test.enqueue(t, "urgent", 2, 10)
+
+	report, err := test.scheduler.Schedule(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+
+	// Simulations are launched by priority and FIFO order. Simulations queued after a simulation waiting for capacity
+	// are not launched.
+	assert.ElementsMatch(t, []simulations.GroupID{"urgent", "first"}, report.Launched)
+	assert.Equal(t, []simulations.GroupID{"large", "small"}, report.Waiting)
+
+	// Launch times are only recorded for simulations that started
+	test.services.AssertCalled(t, "MarkLaunched", simulations.GroupID("urgent"), test.now)
+	test.services.AssertCalled(t, "MarkLaunched", simulations.GroupID("first"), test.now)
+	test.services.AssertNumberOfCalls(t, "MarkLaunched", 2)
+
+	items, err := test.scheduler.Queue.List()
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, simulations.GroupID("large"), items[0].GroupID)
+	assert.Equal(t, simulations.GroupID("small"), items[1].GroupID)
+
+	// Waiting simulations are launched once capacity frees up
+	test.setRunning(4)
+	report, err = test.scheduler.Schedule(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []simulations.GroupID{"large"}, report.Launched)
+	assert.Equal(t, []simulations.GroupID{"small"}, report.Waiting)
+}
+
+func TestScheduler_ScheduleUnlimited(t *testing.T) {
+	test := newSchedulerTest(t, -1, 100)
+	test.enqueue(t, "a", 50, 0)
+	test.enqueue(t, "b", 50, 0)
+
+	report, err := test.scheduler.Schedule(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []simulations.GroupID{"a", "b"}, report.Launched)
+	assert.Empty(t, report.Waiting)
+}
+
+func TestScheduler_ScheduleLaunchErrors(t *testing.T) {
+	test := newSchedulerTest(t, -1, 0)
+	test.enqueue(t, "no-capacity", 1, 1)
+	test.enqueue(t, "failed", 1, 0)
+	test.enqueue(t, "stopped", 1, 0)
+
+	test.setStartError("no-capacity", machines.ErrInsufficientMachines)
+	test.setStartError("failed", assert.AnError)
+	test.sims["stopped"].SetStatus(simulations.StatusTerminated)
+
+	report, err := test.scheduler.Schedule(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, report.Launched)
+	test.services.AssertNotCalled(t, "MarkLaunched", mock.Anything, mock.Anything)
+	test.simulator.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, simulations.GroupID("stopped"))
+
+	// Simulations that fail because capacity was taken are queued again
+	assert.Equal(t, []simulations.GroupID{"no-capacity"}, report.Waiting)
+	assert.Equal(t, simulations.StatusPending, test.sims["no-capacity"].GetStatus())
+
+	// Simulations that fail to launch are marked as failed
+	assert.Equal(t, map[simulations.GroupID]error{"failed": assert.AnError}, report.Errors)
+	assert.Equal(t, simulations.StatusFailed, test.sims["failed"].GetStatus())
+
+	items, err := test.scheduler.Queue.List()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, simulations.GroupID("no-capacity"), items[0].GroupID)
+}
+
+func TestScheduler_ScheduleRetryableNoCapacity(t *testing.T) {
+	test := newSchedulerTest(t, -1, 0)
+	test.enqueue(t, "no-capacity", 1, 0)
+
+	// Cloud providers wrap capacity errors as retryable errors
+	test.setStartError("no-capacity", machines.WrapRetryableError(machines.ErrInsufficientMachines))
+
+	report, err := test.scheduler.Schedule(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []simulations.GroupID{"no-capacity"}, report.Waiting)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, simulations.StatusPending, test.sims["no-capacity"].GetStatus())
+
+	items, err := test.scheduler.Queue.List()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, simulations.GroupID("no-capacity"), items[0].GroupID)
+}
+
+func TestScheduler_ScheduleConcurrentStart(t *testing.T) {
+	test := newSchedulerTest(t, -1, 0)
+	test.enqueue(t, "slow", 1, 0)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	test.starts["slow"].Run(func(args mock.Arguments) {
+		close(started)
+		<-release
+	})
+
+	done := make(chan *Report)
+	go func() {
+		report, err := test.scheduler.Schedule(context.Background())
+		assert.NoError(t, err)
+		done <- report
+	}()
+	<-started
+
+	// Simulations are removed from the queue before they start, and other runs are not blocked while they start
+	test.enqueue(t, "next", 1, 0)
+	report, err := test.scheduler.Schedule(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []simulations.GroupID{"next"}, report.Launched)
+
+	close(release)
+	report = <-done
+	assert.Equal(t, []simulations.GroupID{"slow"}, report.Launched)
+	test.simulator.AssertNumberOfCalls(t, "Start", 2)
+}
+
+func TestScheduler_Run(t *testing.T) {
+	test := newSchedulerTest(t, -1, 0)
+
+	assert.Equal(t, ErrInvalidInterval, test.scheduler.Run(context.Background(), 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, test.scheduler.Run(ctx, time.Millisecond))
+}
+
+func TestMemoryQueue(t *testing.T) {
+	q := NewMemoryQueue()
+
+	require.NoError(t, q.Push(&Item{GroupID: "a", Priority: 0}))
+	require.NoError(t, q.Push(&Item{GroupID: "b", Priority: 1}))
+	require.NoError(t, q.Push(&Item{GroupID: "c", Priority: 0}))
+	assert.Equal(t, ErrAlreadyQueued, q.Push(&Item{GroupID: "a"}))
+
+	items, err := q.List()
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, simulations.GroupID("b"), items[0].GroupID)
+	assert.Equal(t, simulations.GroupID("a"), items[1].GroupID)
+	assert.Equal(t, simulations.GroupID("c"), items[2].GroupID)
+
+	// Items keep their position when they are pushed again
+	require.NoError(t, q.Remove("a"))
+	require.NoError(t, q.Push(items[1]))
+	items, err = q.List()
+	require.NoError(t, err)
+	assert.Equal(t, simulations.GroupID("a"), items[1].GroupID)
+}
+
+func TestAvailableMachines(t *testing.T) {
+	test := newSchedulerTest(t, 5, 3)
+	p, err := test.scheduler.Platforms.Platform("test")
+	require.NoError(t, err)
+
+	available, err := AvailableMachines(p)
+	require.NoError(t, err)
+	assert.Equal(t, 2, available)
+
+	test.setRunning(7)
+	available, err = AvailableMachines(p)
+	require.NoError(t, err)
+	assert.Equal(t, 0, available)
+
+	test.setRunning(-1)
+	_, err = AvailableMachines(p)
+	assert.Equal(t, ErrCountMachinesFailed, err)
+}
@@ -0,0 +1,226 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform/manager"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulator"
+	"github.com/gazebo-web/gz-go/v7"
+	"gopkg.in/go-playground/validator.v9"
+	"sync"
+	"time"
+)
+
+const (
+	// ReasonExpired is the reason recorded for simulations stopped because their validity elapsed.
+	ReasonExpired = "simulation expired"
+)
+
+var (
+	// ErrInvalidInterval is returned when a watchdog is run with an interval lower than 1.
+	ErrInvalidInterval = errors.New("watchdog interval must be positive")
+)
+
+// Warning is emitted when a running simulation is about to expire.
+type Warning struct {
+	// GroupID identifies the simulation.
+	GroupID simulations.GroupID
+	// Owner is the owner of the simulation.
+	Owner *string
+	// Creator is the creator of the simulation.
+	Creator string
+	// ExpiresAt is the time the simulation expires at.
+	ExpiresAt time.Time
+}
+
+// Notifier notifies owners that their simulations are about to expire.
+type Notifier interface {
+	// Notify notifies the owner of a simulation that it is about to expire.
+	Notify(warning Warning) error
+}
+
+// Config contains the configuration of a Watchdog.
+type Config struct {
+	// Services is used to find running simulations and record why they were stopped.
+	Services simulations.Service `validate:"required"`
+	// Platforms is used to find the platform each simulation runs in.
+	Platforms manager.Manager `validate:"required"`
+	// Simulator is used to stop expired simulations.
+	Simulator simulator.Simulator `validate:"required"`
+	// WarnBefore is the time before a simulation expires at which a warning is emitted.
+	// If zero, warnings are not emitted.
+	WarnBefore time.Duration `validate:"min=0"`
+	// Notifier is used to notify warnings. If nil, warnings are only included in reports.
+	Notifier Notifier
+	// Logger is used to log watchdog results.
+	Logger gz.Logger `validate:"required"`
+}
+
+// Report contains the result of a Watchdog check.
+type Report struct {
+	// Stopped contains the simulations that expired and were stopped.
+	Stopped []simulations.GroupID
+	// Warnings contains the warnings emitted for simulations that are about to expire.
+	// A single warning is emitted for each simulation.
+	Warnings []Warning
+	// Errors contains the errors returned while stopping simulations or notifying warnings, indexed by group id.
+	// Simulations that fail to be stopped are retried in the next check.
+	Errors map[simulations.GroupID]error
+}
+
+// Watchdog stops running simulations once their validity elapses.
+// A simulation expires once GetValidFor has passed since GetLaunchedAt. Simulations that have not been launched or
+// have no validity are never stopped.
+type Watchdog interface {
+	// Check stops running simulations that expired, and emits warnings for simulations that are about to expire.
+	Check(ctx context.Context) (*Report, error)
+	// Run calls Check every `interval` until the context is cancelled.
+	// Simulations that fail to be stopped are retried on the next check, so `interval` bounds how long a simulation
+	// can outlive its validity. Run blocks until the context is cancelled.
+	Run(ctx context.Context, interval time.Duration) error
+}
+
+// watchdog is a Watchdog implementation.
+type watchdog struct {
+	Config
+	// warned contains the simulations a warning has already been emitted for.
+	warned map[simulations.GroupID]bool
+	// lock prevents concurrent checks.
+	lock sync.Mutex
+	// now returns the current time.
+	now func() time.Time
+}
+
+// NewWatchdog initializes a new Watchdog.
+func NewWatchdog(config Config) (Watchdog, error) {
+	if err := validator.New().Struct(config); err != nil {
+		return nil, err
+	}
+
+	return &watchdog{
+		Config: config,
+		warned: make(map[simulations.GroupID]bool),
+		now:    time.Now,
+	}, nil
+}
+
+// expiresAt returns the time a simulation expires at. It returns false if the simulation does not expire.
+func expiresAt(sim simulations.Simulation) (time.Time, bool) {
+	if sim.GetLaunchedAt() == nil || sim.GetValidFor() <= 0 {
+		return time.Time{}, false
+	}
+
+	return sim.GetLaunchedAt().Add(sim.GetValidFor()), true
+}
+
+// Check stops running simulations that expired, and emits warnings for simulations that are about to expire.
+func (w *watchdog) Check(ctx context.Context) (*Report, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	running, err := w.Services.ListRunning()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Errors: make(map[simulations.GroupID]error),
+	}
+
+	now := w.now()
+	warned := make(map[simulations.GroupID]bool)
+	for _, sim := range running {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+
+		groupID := sim.GetGroupID()
+		expiration, ok := expiresAt(sim)
+		if !ok {
+			continue
+		}
+
+		if !now.Before(expiration) {
+			if err := w.stop(ctx, sim); err != nil {
+				report.Errors[groupID] = err
+				continue
+			}
+			report.Stopped = append(report.Stopped, groupID)
+			continue
+		}
+
+		if w.WarnBefore == 0 || now.Before(expiration.Add(-w.WarnBefore)) {
+			continue
+		}
+
+		// Emit a single warning for each simulation
+		warned[groupID] = true
+		if w.warned[groupID] {
+			continue
+		}
+		warning := Warning{
+			GroupID:   groupID,
+			Owner:     sim.GetOwner(),
+			Creator:   sim.GetCreator(),
+			ExpiresAt: expiration,
+		}
+		if w.Notifier != nil {
+			if err := w.Notifier.Notify(warning); err != nil {
+				report.Errors[groupID] = err
+				delete(warned, groupID)
+				continue
+			}
+		}
+		report.Warnings = append(report.Warnings, warning)
+	}
+
+	// Only keep track of simulations that are still running
+	w.warned = warned
+
+	return report, nil
+}
+
+// stop records the reason an expired simulation is being stopped, and stops it.
+// The reason is recorded first so that it is available to the stop action, and so that it is not lost if the
+// simulation is stopped but the watchdog fails before recording it.
+func (w *watchdog) stop(ctx context.Context, sim simulations.Simulation) error {
+	p, err := manager.GetSimulationPlatform(w.Platforms, sim)
+	if err != nil {
+		return err
+	}
+
+	if err := w.Services.RecordStopReason(sim.GetGroupID(), ReasonExpired); err != nil {
+		return err
+	}
+
+	return w.Simulator.Stop(ctx, p, sim.GetGroupID())
+}
+
+// Run calls Check every `interval` until the context is cancelled.
+func (w *watchdog) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		report, err := w.Check(ctx)
+		if err != nil {
+			w.Logger.Debug(fmt.Sprintf("Checking expired simulations failed: %s", err))
+			continue
+		}
+
+		w.Logger.Debug(fmt.Sprintf("Checked expired simulations: %d stopped, %d warnings, %d errors",
+			len(report.Stopped), len(report.Warnings), len(report.Errors)))
+	}
+}
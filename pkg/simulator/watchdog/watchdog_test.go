@@ -0,0 +1,177 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform"
+	"github.com/gazebo-web/cloudsim/v4/pkg/platform/manager"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations"
+	"github.com/gazebo-web/cloudsim/v4/pkg/simulations/fake"
+	simfake "github.com/gazebo-web/cloudsim/v4/pkg/simulator/fake"
+	"github.com/gazebo-web/gz-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// newTestSimulation returns a running simulation launched at `launchedAt` that runs in the given platform.
+func newTestSimulation(groupID simulations.GroupID, launchedAt *time.Time, validFor time.Duration,
+	platform *string) simulations.Simulation {
+
+	owner := "owner"
+	sim := fake.NewSimulation(groupID, simulations.StatusRunning, simulations.SimSingle, nil, "", validFor, &owner,
+		launchedAt)
+	return fake.WithPlatform(sim, platform)
+}
+
+// testNotifier is a Notifier implementation that records warnings.
+type testNotifier struct {
+	warnings []Warning
+	err      error
+}
+
+func (n *testNotifier) Notify(warning Warning) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.warnings = append(n.warnings, warning)
+	return nil
+}
+
+type watchdogTest struct {
+	watchdog  *watchdog
+	services  *fake.Service
+	simulator *simfake.Simulator
+	notifier  *testNotifier
+	platform  platform.Platform
+	now       time.Time
+}
+
+func newWatchdogTest(t *testing.T, running ...simulations.Simulation) *watchdogTest {
+	p, err := platform.NewPlatform("test", platform.Components{})
+	require.NoError(t, err)
+
+	test := &watchdogTest{
+		services:  fake.NewService(),
+		simulator: simfake.NewSimulator(),
+		notifier:  &testNotifier{},
+		platform:  p,
+		now:       time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	test.services.On("ListRunning").Return(running, nil)
+
+	w, err := NewWatchdog(Config{
+		Services:   test.services,
+		Platforms:  manager.Map{"test": p},
+		Simulator:  test.simulator,
+		WarnBefore: 10 * time.Minute,
+		Notifier:   test.notifier,
+		Logger:     gz.NewLoggerNoRollbar("TestWatchdog", gz.VerbosityWarning),
+	})
+	require.NoError(t, err)
+
+	test.watchdog = w.(*watchdog)
+	test.watchdog.now = func() time.Time {
+		return test.now
+	}
+
+	return test
+}
+
+func TestNewWatchdog_InvalidConfig(t *testing.T) {
+	_, err := NewWatchdog(Config{})
+	assert.Error(t, err)
+}
+
+func TestWatchdog_Check(t *testing.T) {
+	selector := "test"
+	missing := "missing"
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	launchedAt := now.Add(-time.Hour)
+
+	test := newWatchdogTest(t,
+		newTestSimulation("expired", &launchedAt, time.Hour, &selector),
+		newTestSimulation("expiring", &launchedAt, time.Hour+5*time.Minute, &selector),
+		newTestSimulation("valid", &launchedAt, 2*time.Hour, &selector),
+		newTestSimulation("not-launched", nil, time.Hour, &selector),
+		newTestSimulation("no-validity", &launchedAt, 0, &selector),
+		newTestSimulation("unknown-platform", &launchedAt, time.Minute, &missing),
+	)
+
+	test.services.On("RecordStopReason", mock.Anything, mock.Anything).Return(nil)
+	test.simulator.On("Stop", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	report, err := test.watchdog.Check(context.Background())
+	require.NoError(t, err)
+
+	// Expired simulations are stopped in their platform and the reason is recorded
+	assert.Equal(t, []simulations.GroupID{"expired"}, report.Stopped)
+	test.simulator.AssertCalled(t, "Stop", mock.Anything, test.platform, simulations.GroupID("expired"))
+	test.simulator.AssertNumberOfCalls(t, "Stop", 1)
+	test.services.AssertCalled(t, "RecordStopReason", simulations.GroupID("expired"), ReasonExpired)
+	test.services.AssertNumberOfCalls(t, "RecordStopReason", 1)
+
+	// Simulations running in unknown platforms are reported
+	require.Len(t, report.Errors, 1)
+	assert.True(t, errors.Is(report.Errors["unknown-platform"], manager.ErrPlatformNotFound))
+
+	// Simulations about to expire emit a warning
+	require.Len(t, report.Warnings, 1)
+	warning := report.Warnings[0]
+	assert.Equal(t, simulations.GroupID("expiring"), warning.GroupID)
+	assert.Equal(t, "owner", *warning.Owner)
+	assert.Equal(t, now.Add(5*time.Minute), warning.ExpiresAt)
+	assert.Equal(t, report.Warnings, test.notifier.warnings)
+
+	// Warnings are only emitted once
+	report, err = test.watchdog.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Warnings)
+	assert.Len(t, test.notifier.warnings, 1)
+}
+
+func TestWatchdog_CheckRecordsReasonBeforeStopping(t *testing.T) {
+	selector := "test"
+	launchedAt := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	test := newWatchdogTest(t, newTestSimulation("expired", &launchedAt, time.Hour, &selector))
+	test.services.On("RecordStopReason", simulations.GroupID("expired"), ReasonExpired).Return(assert.AnError)
+
+	// Simulations are not stopped if the reason cannot be recorded
+	report, err := test.watchdog.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Stopped)
+	assert.Equal(t, assert.AnError, report.Errors["expired"])
+	test.simulator.AssertNotCalled(t, "Stop", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWatchdog_CheckNotifyError(t *testing.T) {
+	selector := "test"
+	launchedAt := time.Date(2021, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	test := newWatchdogTest(t, newTestSimulation("expiring", &launchedAt, time.Hour+5*time.Minute, &selector))
+	test.notifier.err = assert.AnError
+
+	report, err := test.watchdog.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Warnings)
+	assert.Equal(t, assert.AnError, report.Errors["expiring"])
+
+	// Warnings that fail to be notified are retried
+	test.notifier.err = nil
+	report, err = test.watchdog.Check(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, report.Warnings, 1)
+}
+
+func TestWatchdog_Run(t *testing.T) {
+	test := newWatchdogTest(t)
+
+	assert.Equal(t, ErrInvalidInterval, test.watchdog.Run(context.Background(), 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, test.watchdog.Run(ctx, time.Millisecond))
+}
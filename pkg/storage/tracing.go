@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// tracerName is the name of the tracer used to create storage spans.
+const tracerName = "github.com/gazebo-web/cloudsim/v4/pkg/storage"
+
+// WithTracing initializes a new Storage that creates a span for every call to the base Storage implementation.
+// Storage methods do not receive a context, so spans are created as root spans and are not part of the trace of the
+// caller (e.g. the deployment trace of an action). They must be correlated with the caller's spans by time, using
+// the bucket and key of each operation, which are added as span attributes.
+func WithTracing(base Storage, tracerProvider trace.TracerProvider) Storage {
+	return &tracing{
+		Storage: base,
+		tracer:  tracerProvider.Tracer(tracerName),
+	}
+}
+
+// tracing is a Storage implementation that traces calls to another Storage implementation.
+type tracing struct {
+	Storage
+	tracer trace.Tracer
+}
+
+// start starts a new span for a Storage operation.
+func (t *tracing) start(operation, bucket, key string) trace.Span {
+	_, span := t.tracer.Start(context.Background(), "storage."+operation, trace.WithAttributes(
+		attribute.String("cloudsim.storage.bucket", bucket),
+		attribute.String("cloudsim.storage.key", key),
+	))
+	return span
+}
+
+// end ends a span, recording `err` if it is not nil.
+func (t *tracing) end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Upload uploads a file to a cloud storage.
+func (t *tracing) Upload(input UploadInput) (err error) {
+	span := t.start("Upload", input.Bucket, input.Key)
+	span.SetAttributes(attribute.Int64("cloudsim.storage.content_length", input.ContentLength))
+	defer func() { t.end(span, err) }()
+
+	return t.Storage.Upload(input)
+}
+
+// GetURL returns the URL of the given bucket and key from a cloud storage.
+func (t *tracing) GetURL(bucket string, key string, expiresIn time.Duration) (url string, err error) {
+	span := t.start("GetURL", bucket, key)
+	defer func() { t.end(span, err) }()
+
+	return t.Storage.GetURL(bucket, key, expiresIn)
+}
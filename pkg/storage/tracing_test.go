@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+	"time"
+)
+
+// tracingTestStorage is a Storage implementation used to test tracing.
+type tracingTestStorage struct {
+	Storage
+	err error
+}
+
+func (s *tracingTestStorage) Upload(input UploadInput) error {
+	return s.err
+}
+
+func (s *tracingTestStorage) GetURL(bucket string, key string, expiresIn time.Duration) (string, error) {
+	return "https://" + bucket + "/" + key, nil
+}
+
+func TestWithTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	s := WithTracing(&tracingTestStorage{err: errors.New("test")}, tracerProvider)
+
+	assert.Error(t, s.Upload(UploadInput{Bucket: "bucket", Key: "logs.tar.gz", ContentLength: 10}))
+
+	url, err := s.GetURL("bucket", "logs.tar.gz", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "https://bucket/logs.tar.gz", url)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	// Storage spans are root spans
+	assert.Equal(t, "storage.Upload", spans[0].Name)
+	assert.False(t, spans[0].Parent.IsValid())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Contains(t, spans[0].Attributes, attribute.String("cloudsim.storage.bucket", "bucket"))
+	assert.Contains(t, spans[0].Attributes, attribute.String("cloudsim.storage.key", "logs.tar.gz"))
+	assert.Contains(t, spans[0].Attributes, attribute.Int64("cloudsim.storage.content_length", 10))
+
+	assert.Equal(t, "storage.GetURL", spans[1].Name)
+	assert.False(t, spans[1].Parent.IsValid())
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+}
@@ -1,6 +1,7 @@
 package waiter
 
 import (
+	"context"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"time"
 )
@@ -14,6 +15,8 @@ var (
 type Waiter interface {
 	// Wait runs a new thread for at least `timeout` duration, repeated by every `frequency` cycles.
 	Wait(timeout time.Duration, frequency time.Duration) error
+	// WaitContext works like Wait, but stops waiting when the context is done and returns the context error.
+	WaitContext(ctx context.Context, timeout time.Duration, frequency time.Duration) error
 }
 
 // request is a Waiter implementation that will be used to wait for a job to succeed.
@@ -24,7 +27,20 @@ type request struct {
 // Wait executes a job in regular time intervals given by a certain frequency.
 // If will return an error when the job fails or the request times out.
 func (r request) Wait(timeout time.Duration, frequency time.Duration) error {
-	return wait.PollImmediate(frequency, timeout, r.job)
+	return r.WaitContext(context.Background(), timeout, frequency)
+}
+
+// WaitContext executes a job in regular time intervals given by a certain frequency until the context is done.
+// If will return an error when the job fails, the request times out or the context is done.
+func (r request) WaitContext(ctx context.Context, timeout time.Duration, frequency time.Duration) error {
+	err := wait.PollImmediateWithContext(ctx, frequency, timeout, func(context.Context) (bool, error) {
+		return r.job()
+	})
+	// The poller reports a done context as a timeout
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
 }
 
 // NewWaitRequest creates a new Waiter implementation.
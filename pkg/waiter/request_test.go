@@ -1,6 +1,7 @@
 package waiter
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
@@ -55,3 +56,21 @@ func TestRequest_WaitTimeouts(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, ErrRequestTimeout, err)
 }
+
+func TestRequest_WaitContextStopsWhenContextIsDone(t *testing.T) {
+	job := func() (bool, error) {
+		return false, nil
+	}
+	wr := NewWaitRequest(job)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	err := wr.WaitContext(ctx, time.Minute, time.Millisecond)
+	assert.Equal(t, context.Canceled, err)
+	assert.Less(t, time.Since(start), time.Minute)
+}